@@ -0,0 +1,60 @@
+// Package analysis extracts semantic information, such as the relations and
+// columns a statement references, from parse trees returned by pg_query.Parse.
+//
+// The analysis works purely on the raw parse tree without access to a database
+// catalog, so references that can only be resolved with knowledge of the schema,
+// such as an unqualified column in a query joining several tables, are reported
+// with their candidate tables instead of a single resolved table.
+package analysis
+
+// Context describes how a statement uses a relation or column.
+type Context int
+
+const (
+	// ContextSelect is a read of a relation or column.
+	ContextSelect Context = iota + 1
+	// ContextInsert is the target of an INSERT or COPY FROM.
+	ContextInsert
+	// ContextUpdate is the target of an UPDATE or ON CONFLICT DO UPDATE.
+	ContextUpdate
+	// ContextDelete is the target of a DELETE.
+	ContextDelete
+	// ContextDDL is a reference from a utility statement, such as CREATE TABLE or ALTER TABLE.
+	ContextDDL
+)
+
+// String returns the SQL command name of the context.
+func (c Context) String() string {
+	switch c {
+	case ContextSelect:
+		return "SELECT"
+	case ContextInsert:
+		return "INSERT"
+	case ContextUpdate:
+		return "UPDATE"
+	case ContextDelete:
+		return "DELETE"
+	case ContextDDL:
+		return "DDL"
+	}
+	return "UNKNOWN"
+}
+
+// RelationName is a possibly schema-qualified relation name.
+type RelationName struct {
+	Schema string
+	Name   string
+}
+
+// String returns the name in schema.name form, omitting the schema if empty.
+func (n RelationName) String() string {
+	if n.Schema == "" {
+		return n.Name
+	}
+	return n.Schema + "." + n.Name
+}
+
+// MarshalText implements encoding.TextMarshaler using the String form.
+func (n RelationName) MarshalText() ([]byte, error) {
+	return []byte(n.String()), nil
+}
@@ -0,0 +1,1019 @@
+package analysis
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+	"strconv"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/wasilibs/go-pgquery/internal/naming"
+	"github.com/wasilibs/go-pgquery/internal/walk"
+)
+
+// TableRef is a reference to a relation by a statement.
+type TableRef struct {
+	// Relation is the name of the referenced relation.
+	Relation RelationName
+	// Alias is the alias the relation was given in the FROM clause, if any.
+	Alias string
+	// Context is how the statement uses the relation.
+	Context Context
+	// Stmt is the index of the statement in ParseResult.Stmts.
+	Stmt int
+	// Location is the byte offset of the reference in the parsed SQL, or -1 if
+	// the parse tree does not record it.
+	Location int32
+}
+
+// ColumnRef is a reference to a column by a statement.
+type ColumnRef struct {
+	// Name is the name of the column, or "*" for a star reference.
+	Name string
+	// Qualifier holds the qualifying names as written, such as ["t"] for t.col.
+	Qualifier []string
+	// Table is the relation the column was resolved to, or nil if it could not
+	// be resolved to a single relation or belongs to a derived source.
+	Table *RelationName
+	// Column is the name of the column in Table when Name is an alias given to
+	// it by a column alias list, as a in FROM t AS x(a). It is only set if the
+	// columns of Table are known from a Schema.
+	Column string
+	// Source is the name of the CTE or subquery the column was resolved to when
+	// it does not belong to a relation.
+	Source string
+	// Candidates lists the relations an unqualified column may belong to when it
+	// could not be attributed to a single one.
+	Candidates []RelationName
+	// CandidateSources lists the CTEs and subqueries an unqualified column may
+	// belong to when it could not be attributed to a single source.
+	CandidateSources []string
+	// Ambiguous is set when more than one source in scope is known to have the
+	// column, which requires a Schema for relations.
+	Ambiguous bool
+	// Undefined is set for a qualified column when the columns of the source it
+	// is qualified with are known and do not include it, such as a column of a
+	// relation renamed by a column alias list.
+	Undefined bool
+	// WholeRow is set when an unqualified name that is not a column refers to
+	// the whole row of the source of that name, as t in SELECT t FROM t.
+	WholeRow bool
+	// Context is how the statement uses the column.
+	Context Context
+	// Stmt is the index of the statement in ParseResult.Stmts.
+	Stmt int
+	// Location is the byte offset of the reference in the parsed SQL, or -1 if
+	// the parse tree does not record it.
+	Location int32
+}
+
+// ExtractReferences returns the relations and columns referenced by the
+// statements in tree, ordered by statement and location. Names of CTEs are
+// resolved and not reported as relations, and table aliases are resolved to the
+// relations they refer to.
+func ExtractReferences(tree *pganalyze.ParseResult) ([]TableRef, []ColumnRef) {
+	return ExtractReferencesWithSchema(tree, nil)
+}
+
+// Schema provides the columns of relations to reference extraction.
+type Schema interface {
+	// Columns returns the column names of the relation, or false if the
+	// relation is not known.
+	Columns(name RelationName) ([]string, bool)
+}
+
+// ExtractReferencesWithSchema is like ExtractReferences, but uses schema to
+// look up the columns of relations. This allows unqualified columns to be
+// resolved the way Postgres does, attributing them to the only relation in
+// scope that has the column, including relations of outer queries.
+func ExtractReferencesWithSchema(tree *pganalyze.ParseResult, schema Schema) ([]TableRef, []ColumnRef) {
+	e := &extractor{schema: schema}
+	for i, raw := range tree.GetStmts() {
+		e.stmt = i
+		e.statement(raw.GetStmt(), nil)
+	}
+
+	sort.SliceStable(e.tables, func(i, j int) bool {
+		if e.tables[i].Stmt != e.tables[j].Stmt {
+			return e.tables[i].Stmt < e.tables[j].Stmt
+		}
+		return e.tables[i].Location < e.tables[j].Location
+	})
+	sort.SliceStable(e.columns, func(i, j int) bool {
+		if e.columns[i].Stmt != e.columns[j].Stmt {
+			return e.columns[i].Stmt < e.columns[j].Stmt
+		}
+		return e.columns[i].Location < e.columns[j].Location
+	})
+
+	return e.tables, e.columns
+}
+
+type extractor struct {
+	schema  Schema
+	tables  []TableRef
+	columns []ColumnRef
+	stmt    int
+}
+
+// scope holds the names visible at one level of query nesting.
+type scope struct {
+	parent  *scope
+	ctes    map[string]*source
+	sources []*source
+	// outputs are the output column aliases, which ORDER BY may refer to.
+	outputs map[string]bool
+}
+
+// source is an entry of a FROM clause, or the target relation of a statement.
+type source struct {
+	// refname is the name columns are qualified with, the alias if present.
+	refname string
+	// table is the referenced relation, or nil for CTEs, subqueries and functions.
+	table *RelationName
+	// columns are the known column names of the source, nil if unknown.
+	columns []string
+	// partial is set when columns are only the leading columns of the source,
+	// given by a column alias list for a source whose columns are unknown.
+	partial bool
+	// attnames are the names of columns in table, before renaming by a column
+	// alias list, nil if not renamed.
+	attnames []string
+	// qualifiedOnly is set for join aliases, whose columns are attributed to
+	// the joined sources when referenced without qualification.
+	qualifiedOnly bool
+	// using are the columns merged by a join with USING or NATURAL, which
+	// replace the columns of the same name of the joined sources when
+	// referenced without qualification.
+	using []string
+	// joined are the sources of a join with merged columns.
+	joined []*source
+}
+
+func newScope(parent *scope) *scope {
+	return &scope{parent: parent}
+}
+
+func (s *scope) cte(name string) *source {
+	for ; s != nil; s = s.parent {
+		if c, ok := s.ctes[name]; ok {
+			return c
+		}
+	}
+	return nil
+}
+
+func (e *extractor) statement(n *pganalyze.Node, parent *scope) {
+	switch s := walk.Unwrap(n).(type) {
+	case *pganalyze.SelectStmt:
+		e.selectStmt(s, parent)
+	case *pganalyze.InsertStmt:
+		e.insertStmt(s, parent)
+	case *pganalyze.UpdateStmt:
+		e.updateStmt(s, parent)
+	case *pganalyze.DeleteStmt:
+		e.deleteStmt(s, parent)
+	case *pganalyze.MergeStmt:
+		e.mergeStmt(s, parent)
+	case *pganalyze.ExplainStmt:
+		e.statement(s.GetQuery(), parent)
+	case *pganalyze.PrepareStmt:
+		e.statement(s.GetQuery(), parent)
+	case *pganalyze.DeclareCursorStmt:
+		e.statement(s.GetQuery(), parent)
+	case *pganalyze.CopyStmt:
+		e.copyStmt(s)
+	case nil:
+	default:
+		e.utility(s)
+	}
+}
+
+func (e *extractor) withClause(w *pganalyze.WithClause, sc *scope) {
+	if w == nil {
+		return
+	}
+	sc.ctes = map[string]*source{}
+	for _, n := range w.GetCtes() {
+		cte := n.GetCommonTableExpr()
+		if cte == nil {
+			continue
+		}
+		src := &source{refname: cte.GetCtename(), columns: stringList(cte.GetAliascolnames())}
+		if w.GetRecursive() {
+			sc.ctes[src.refname] = src
+		}
+		cols := e.subquery(cte.GetCtequery(), sc)
+		if src.columns == nil {
+			src.columns = cols
+		}
+		sc.ctes[src.refname] = src
+	}
+}
+
+// subquery analyzes a nested statement and returns its output column names.
+func (e *extractor) subquery(n *pganalyze.Node, parent *scope) []string {
+	if s := n.GetSelectStmt(); s != nil {
+		return e.selectStmt(s, parent)
+	}
+	e.statement(n, parent)
+	return nil
+}
+
+// selectStmt analyzes s and returns its output column names, or nil if they
+// cannot be determined without a catalog.
+func (e *extractor) selectStmt(s *pganalyze.SelectStmt, parent *scope) []string {
+	sc := newScope(parent)
+	e.withClause(s.GetWithClause(), sc)
+
+	if s.GetOp() != pganalyze.SetOperation_SETOP_NONE {
+		cols := e.selectStmt(s.GetLarg(), sc)
+		e.selectStmt(s.GetRarg(), sc)
+		sc.outputs = map[string]bool{}
+		for _, c := range cols {
+			sc.outputs[c] = true
+		}
+		e.sortAndLimit(s, sc)
+		return cols
+	}
+
+	for _, n := range s.GetFromClause() {
+		e.fromItem(n, sc, ContextSelect)
+	}
+	if into := s.GetIntoClause(); into != nil {
+		e.addTable(into.GetRel(), ContextDDL)
+	}
+
+	for _, row := range s.GetValuesLists() {
+		e.expr(row, sc, ContextSelect)
+	}
+
+	var cols []string
+	known := true
+	aliases := map[string]bool{}
+	for _, n := range s.GetTargetList() {
+		rt := n.GetResTarget()
+		e.expr(rt.GetVal(), sc, ContextSelect)
+		name := naming.OutputName(rt)
+		if name == "*" {
+			expanded, ok := expandStar(rt.GetVal().GetColumnRef(), sc)
+			known = known && ok
+			cols = append(cols, expanded...)
+			continue
+		}
+		cols = append(cols, name)
+		if rt.GetName() != "" {
+			aliases[name] = true
+		}
+	}
+	if !known {
+		cols = nil
+	}
+
+	e.exprs(s.GetDistinctClause(), sc, ContextSelect)
+	e.expr(s.GetWhereClause(), sc, ContextSelect)
+	e.groupBy(s.GetGroupClause(), aliases, sc)
+	e.expr(s.GetHavingClause(), sc, ContextSelect)
+	e.exprs(s.GetWindowClause(), sc, ContextSelect)
+	// ORDER BY may refer to output columns by their alias.
+	sc.outputs = aliases
+	e.sortAndLimit(s, sc)
+
+	if len(s.GetValuesLists()) > 0 {
+		if row := s.GetValuesLists()[0].GetList(); row != nil {
+			cols = make([]string, len(row.GetItems()))
+			for i := range cols {
+				cols[i] = "column" + strconv.Itoa(i+1)
+			}
+		}
+	}
+
+	return cols
+}
+
+// groupBy records the column references of a GROUP BY clause. Like Postgres,
+// a bare name refers to an output column if no source in scope has a column
+// of that name.
+func (e *extractor) groupBy(items []*pganalyze.Node, aliases map[string]bool, sc *scope) {
+	for _, n := range items {
+		if fields := n.GetColumnRef().GetFields(); len(fields) == 1 {
+			name := fields[0].GetString_().GetSval()
+			if aliases[name] && !slices.ContainsFunc(resolveUnqualified(name, sc), func(src *source) bool { return src.has(name) }) {
+				continue
+			}
+		}
+		e.expr(n, sc, ContextSelect)
+	}
+}
+
+func (e *extractor) sortAndLimit(s *pganalyze.SelectStmt, sc *scope) {
+	e.exprs(s.GetSortClause(), sc, ContextSelect)
+	e.expr(s.GetLimitOffset(), sc, ContextSelect)
+	e.expr(s.GetLimitCount(), sc, ContextSelect)
+}
+
+func (e *extractor) insertStmt(s *pganalyze.InsertStmt, parent *scope) {
+	sc := newScope(parent)
+	e.withClause(s.GetWithClause(), sc)
+
+	target := e.addTable(s.GetRelation(), ContextInsert)
+	for _, n := range s.GetCols() {
+		rt := n.GetResTarget()
+		e.addColumn(ColumnRef{
+			Name:     rt.GetName(),
+			Table:    target.table,
+			Context:  ContextInsert,
+			Location: rt.GetLocation(),
+		})
+	}
+
+	if sel := s.GetSelectStmt(); sel != nil {
+		e.subquery(sel, sc)
+	}
+
+	// ON CONFLICT and RETURNING see the target relation, the SELECT does not.
+	sc.sources = append(sc.sources, target)
+	if oc := s.GetOnConflictClause(); oc != nil {
+		if infer := oc.GetInfer(); infer != nil {
+			for _, n := range infer.GetIndexElems() {
+				if name := n.GetIndexElem().GetName(); name != "" {
+					e.addColumn(ColumnRef{Name: name, Table: target.table, Context: ContextSelect, Location: -1})
+				}
+			}
+			e.exprs(infer.GetIndexElems(), sc, ContextSelect)
+			e.expr(infer.GetWhereClause(), sc, ContextSelect)
+		}
+		excluded := &source{refname: "excluded", table: target.table, columns: target.columns}
+		sc.sources = append(sc.sources, excluded)
+		e.setTargets(oc.GetTargetList(), target, sc, ContextUpdate)
+		e.expr(oc.GetWhereClause(), sc, ContextSelect)
+		sc.sources = sc.sources[:len(sc.sources)-1]
+	}
+	e.exprs(s.GetReturningList(), sc, ContextSelect)
+}
+
+func (e *extractor) updateStmt(s *pganalyze.UpdateStmt, parent *scope) {
+	sc := newScope(parent)
+	e.withClause(s.GetWithClause(), sc)
+
+	target := e.addTable(s.GetRelation(), ContextUpdate)
+	sc.sources = append(sc.sources, target)
+	for _, n := range s.GetFromClause() {
+		e.fromItem(n, sc, ContextSelect)
+	}
+	e.setTargets(s.GetTargetList(), target, sc, ContextUpdate)
+	e.expr(s.GetWhereClause(), sc, ContextSelect)
+	e.exprs(s.GetReturningList(), sc, ContextSelect)
+}
+
+func (e *extractor) deleteStmt(s *pganalyze.DeleteStmt, parent *scope) {
+	sc := newScope(parent)
+	e.withClause(s.GetWithClause(), sc)
+
+	target := e.addTable(s.GetRelation(), ContextDelete)
+	sc.sources = append(sc.sources, target)
+	for _, n := range s.GetUsingClause() {
+		e.fromItem(n, sc, ContextSelect)
+	}
+	e.expr(s.GetWhereClause(), sc, ContextSelect)
+	e.exprs(s.GetReturningList(), sc, ContextSelect)
+}
+
+func (e *extractor) mergeStmt(s *pganalyze.MergeStmt, parent *scope) {
+	sc := newScope(parent)
+	e.withClause(s.GetWithClause(), sc)
+
+	ctxs := map[Context]bool{}
+	for _, n := range s.GetMergeWhenClauses() {
+		switch n.GetMergeWhenClause().GetCommandType() {
+		case pganalyze.CmdType_CMD_INSERT:
+			ctxs[ContextInsert] = true
+		case pganalyze.CmdType_CMD_UPDATE:
+			ctxs[ContextUpdate] = true
+		case pganalyze.CmdType_CMD_DELETE:
+			ctxs[ContextDelete] = true
+		default:
+		}
+	}
+	var target *source
+	for _, ctx := range []Context{ContextInsert, ContextUpdate, ContextDelete} {
+		if ctxs[ctx] || (ctx == ContextUpdate && target == nil) {
+			target = e.addTable(s.GetRelation(), ctx)
+		}
+	}
+	sc.sources = append(sc.sources, target)
+	e.fromItem(s.GetSourceRelation(), sc, ContextSelect)
+	e.expr(s.GetJoinCondition(), sc, ContextSelect)
+
+	for _, n := range s.GetMergeWhenClauses() {
+		mw := n.GetMergeWhenClause()
+		e.expr(mw.GetCondition(), sc, ContextSelect)
+		switch mw.GetCommandType() {
+		case pganalyze.CmdType_CMD_INSERT:
+			for _, t := range mw.GetTargetList() {
+				rt := t.GetResTarget()
+				e.addColumn(ColumnRef{Name: rt.GetName(), Table: target.table, Context: ContextInsert, Location: rt.GetLocation()})
+			}
+			e.exprs(mw.GetValues(), sc, ContextSelect)
+		case pganalyze.CmdType_CMD_UPDATE:
+			e.setTargets(mw.GetTargetList(), target, sc, ContextUpdate)
+		default:
+		}
+	}
+	e.exprs(s.GetReturningList(), sc, ContextSelect)
+}
+
+func (e *extractor) copyStmt(s *pganalyze.CopyStmt) {
+	if q := s.GetQuery(); q != nil {
+		e.statement(q, nil)
+		return
+	}
+	ctx := ContextSelect
+	if s.GetIsFrom() {
+		ctx = ContextInsert
+	}
+	target := e.addTable(s.GetRelation(), ctx)
+	for _, name := range s.GetAttlist() {
+		e.addColumn(ColumnRef{Name: name.GetString_().GetSval(), Table: target.table, Context: ctx, Location: -1})
+	}
+	if w := s.GetWhereClause(); w != nil {
+		sc := newScope(nil)
+		sc.sources = append(sc.sources, target)
+		e.expr(w, sc, ContextSelect)
+	}
+}
+
+// utility records references made by statements other than queries. Every
+// relation they mention is reported with ContextDDL, as are columns that are
+// defined or altered. Queries embedded in the statement, such as the
+// definition of a view, are analyzed as queries.
+func (e *extractor) utility(stmt proto.Message) {
+	var primary *source
+	sc := newScope(nil)
+	switch s := stmt.(type) {
+	case *pganalyze.CreateStmt:
+		primary = e.addTable(s.GetRelation(), ContextDDL)
+	case *pganalyze.AlterTableStmt:
+		primary = e.addTable(s.GetRelation(), ContextDDL)
+	case *pganalyze.IndexStmt:
+		primary = e.addTable(s.GetRelation(), ContextDDL)
+	case *pganalyze.RenameStmt:
+		if s.GetRelation() != nil {
+			primary = e.addTable(s.GetRelation(), ContextDDL)
+			if s.GetRenameType() == pganalyze.ObjectType_OBJECT_COLUMN {
+				e.addColumn(ColumnRef{Name: s.GetSubname(), Table: primary.table, Context: ContextDDL, Location: s.GetRelation().GetLocation()})
+			}
+		}
+	case *pganalyze.DropStmt:
+		switch s.GetRemoveType() {
+		case pganalyze.ObjectType_OBJECT_TABLE, pganalyze.ObjectType_OBJECT_VIEW,
+			pganalyze.ObjectType_OBJECT_MATVIEW, pganalyze.ObjectType_OBJECT_FOREIGN_TABLE:
+			for _, obj := range s.GetObjects() {
+				names := stringList(obj.GetList().GetItems())
+				if len(names) == 0 {
+					continue
+				}
+				ref := TableRef{Context: ContextDDL, Stmt: e.stmt, Location: -1}
+				ref.Relation.Name = names[len(names)-1]
+				if len(names) > 1 {
+					ref.Relation.Schema = names[len(names)-2]
+				}
+				e.tables = append(e.tables, ref)
+			}
+		default:
+		}
+		return
+	}
+	primaryRV := primaryRangeVar(stmt)
+	if primary != nil {
+		sc.sources = append(sc.sources, primary)
+	}
+
+	walk.Walk(stmt, func(msg proto.Message) bool {
+		switch m := msg.(type) {
+		case *pganalyze.RangeVar:
+			if m != primaryRV {
+				e.addTable(m, ContextDDL)
+			}
+			return false
+		case *pganalyze.SelectStmt:
+			e.selectStmt(m, nil)
+			return false
+		case *pganalyze.InsertStmt:
+			e.insertStmt(m, nil)
+			return false
+		case *pganalyze.UpdateStmt:
+			e.updateStmt(m, nil)
+			return false
+		case *pganalyze.DeleteStmt:
+			e.deleteStmt(m, nil)
+			return false
+		case *pganalyze.ColumnDef:
+			if m.GetColname() != "" && primary != nil {
+				e.addColumn(ColumnRef{Name: m.GetColname(), Table: primary.table, Context: ContextDDL, Location: m.GetLocation()})
+			}
+		case *pganalyze.AlterTableCmd:
+			if m.GetName() != "" && primary != nil && alterTableColumnCmds[m.GetSubtype()] {
+				e.addColumn(ColumnRef{Name: m.GetName(), Table: primary.table, Context: ContextDDL, Location: -1})
+			}
+		case *pganalyze.IndexElem:
+			if m.GetName() != "" && primary != nil {
+				e.addColumn(ColumnRef{Name: m.GetName(), Table: primary.table, Context: ContextDDL, Location: -1})
+			}
+		case *pganalyze.Constraint:
+			if primary != nil {
+				for _, name := range stringList(m.GetKeys()) {
+					e.addColumn(ColumnRef{Name: name, Table: primary.table, Context: ContextDDL, Location: m.GetLocation()})
+				}
+				for _, name := range stringList(m.GetFkAttrs()) {
+					e.addColumn(ColumnRef{Name: name, Table: primary.table, Context: ContextDDL, Location: m.GetLocation()})
+				}
+			}
+			if pk := m.GetPktable(); pk != nil {
+				ref := e.addTable(pk, ContextDDL)
+				for _, name := range stringList(m.GetPkAttrs()) {
+					e.addColumn(ColumnRef{Name: name, Table: ref.table, Context: ContextDDL, Location: pk.GetLocation()})
+				}
+			}
+			e.expr(m.GetRawExpr(), sc, ContextDDL)
+			e.expr(m.GetWhereClause(), sc, ContextDDL)
+			return false
+		case *pganalyze.ColumnRef:
+			e.columnRef(m, sc, ContextDDL)
+			return false
+		}
+		return true
+	})
+}
+
+var alterTableColumnCmds = map[pganalyze.AlterTableType]bool{
+	pganalyze.AlterTableType_AT_ColumnDefault:             true,
+	pganalyze.AlterTableType_AT_DropNotNull:               true,
+	pganalyze.AlterTableType_AT_SetNotNull:                true,
+	pganalyze.AlterTableType_AT_SetExpression:             true,
+	pganalyze.AlterTableType_AT_DropExpression:            true,
+	pganalyze.AlterTableType_AT_SetStatistics:             true,
+	pganalyze.AlterTableType_AT_SetOptions:                true,
+	pganalyze.AlterTableType_AT_ResetOptions:              true,
+	pganalyze.AlterTableType_AT_SetStorage:                true,
+	pganalyze.AlterTableType_AT_SetCompression:            true,
+	pganalyze.AlterTableType_AT_DropColumn:                true,
+	pganalyze.AlterTableType_AT_AlterColumnType:           true,
+	pganalyze.AlterTableType_AT_AlterColumnGenericOptions: true,
+	pganalyze.AlterTableType_AT_AddIdentity:               true,
+	pganalyze.AlterTableType_AT_SetIdentity:               true,
+	pganalyze.AlterTableType_AT_DropIdentity:              true,
+}
+
+func primaryRangeVar(stmt proto.Message) *pganalyze.RangeVar {
+	switch s := stmt.(type) {
+	case *pganalyze.CreateStmt:
+		return s.GetRelation()
+	case *pganalyze.AlterTableStmt:
+		return s.GetRelation()
+	case *pganalyze.IndexStmt:
+		return s.GetRelation()
+	case *pganalyze.RenameStmt:
+		return s.GetRelation()
+	}
+	return nil
+}
+
+// fromItem adds the sources of a FROM clause entry to sc.
+func (e *extractor) fromItem(n *pganalyze.Node, sc *scope, ctx Context) {
+	switch f := walk.Unwrap(n).(type) {
+	case *pganalyze.RangeVar:
+		sc.sources = append(sc.sources, e.rangeVar(f, sc, ctx))
+	case *pganalyze.JoinExpr:
+		first := len(sc.sources)
+		e.fromItem(f.GetLarg(), sc, ctx)
+		mid := len(sc.sources)
+		e.fromItem(f.GetRarg(), sc, ctx)
+		if using := mergedColumns(f, sc.sources[first:mid], sc.sources[mid:]); len(using) > 0 {
+			src := &source{columns: using, using: using, joined: slices.Clone(sc.sources[first:])}
+			sc.sources = append(sc.sources, src)
+		}
+		e.expr(f.GetQuals(), sc, ContextSelect)
+		if a := f.GetAlias(); a != nil {
+			src := &source{refname: a.GetAliasname(), qualifiedOnly: true}
+			src.rename(stringList(a.GetColnames()))
+			sc.sources = append(sc.sources, src)
+		}
+	case *pganalyze.RangeSubselect:
+		parent := sc.parent
+		if f.GetLateral() {
+			parent = sc
+		}
+		// The subquery must see CTEs of this level but not its FROM entries.
+		cteScope := &scope{parent: parent, ctes: sc.ctes}
+		cols := e.subquery(f.GetSubquery(), cteScope)
+		src := &source{refname: f.GetAlias().GetAliasname(), columns: cols}
+		src.rename(stringList(f.GetAlias().GetColnames()))
+		sc.sources = append(sc.sources, src)
+	case *pganalyze.RangeFunction:
+		e.exprs(f.GetFunctions(), sc, ContextSelect)
+		src := &source{refname: f.GetAlias().GetAliasname(), columns: stringList(f.GetAlias().GetColnames())}
+		if src.refname == "" {
+			src.refname = naming.FunctionName(f)
+		}
+		sc.sources = append(sc.sources, src)
+	case *pganalyze.RangeTableSample:
+		e.fromItem(f.GetRelation(), sc, ctx)
+		e.exprs(f.GetArgs(), sc, ContextSelect)
+		e.expr(f.GetRepeatable(), sc, ContextSelect)
+	case *pganalyze.RangeTableFunc:
+		e.expr(n, sc, ContextSelect)
+		src := &source{refname: cmp.Or(f.GetAlias().GetAliasname(), "xmltable")}
+		for _, c := range f.GetColumns() {
+			src.columns = append(src.columns, c.GetRangeTableFuncCol().GetColname())
+		}
+		src.rename(stringList(f.GetAlias().GetColnames()))
+		sc.sources = append(sc.sources, src)
+	case *pganalyze.JsonTable:
+		e.expr(n, sc, ContextSelect)
+		src := &source{refname: cmp.Or(f.GetAlias().GetAliasname(), "json_table"), columns: jsonTableColumns(f.GetColumns())}
+		src.rename(stringList(f.GetAlias().GetColnames()))
+		sc.sources = append(sc.sources, src)
+	default:
+		// Other FROM entries only contribute column references from their
+		// expressions.
+		if f != nil {
+			e.expr(n, sc, ContextSelect)
+		}
+	}
+}
+
+// jsonTableColumns returns the names of the columns of JSON_TABLE, including
+// those of NESTED paths.
+func jsonTableColumns(nodes []*pganalyze.Node) []string {
+	cols := []string{}
+	for _, n := range nodes {
+		c := n.GetJsonTableColumn()
+		if c.GetColtype() == pganalyze.JsonTableColumnType_JTC_NESTED {
+			cols = append(cols, jsonTableColumns(c.GetColumns())...)
+			continue
+		}
+		cols = append(cols, c.GetName())
+	}
+	return cols
+}
+
+// mergedColumns returns the columns merged by a join with USING or NATURAL.
+// The common columns of a NATURAL join are only those known to be columns of
+// both sides.
+func mergedColumns(j *pganalyze.JoinExpr, left, right []*source) []string {
+	if !j.GetIsNatural() {
+		return stringList(j.GetUsingClause())
+	}
+	rightCols := knownColumns(right)
+	var cols []string
+	for _, name := range knownColumns(left) {
+		if slices.Contains(rightCols, name) && !slices.Contains(cols, name) {
+			cols = append(cols, name)
+		}
+	}
+	return cols
+}
+
+// knownColumns returns the known columns of the sources of one side of a join.
+func knownColumns(sources []*source) []string {
+	var cols []string
+	for _, src := range sources {
+		if !src.qualifiedOnly && src.using == nil {
+			cols = append(cols, src.columns...)
+		}
+	}
+	return cols
+}
+
+// rangeVar resolves a relation reference in a FROM clause to a CTE or table.
+func (e *extractor) rangeVar(rv *pganalyze.RangeVar, sc *scope, ctx Context) *source {
+	if rv.GetSchemaname() == "" {
+		if cte := sc.cte(rv.GetRelname()); cte != nil {
+			src := &source{refname: cte.refname, columns: cte.columns, partial: cte.partial}
+			if a := rv.GetAlias(); a != nil {
+				src.refname = a.GetAliasname()
+				src.rename(stringList(a.GetColnames()))
+			}
+			return src
+		}
+	}
+	return e.addTable(rv, ctx)
+}
+
+func (e *extractor) addTable(rv *pganalyze.RangeVar, ctx Context) *source {
+	if rv == nil {
+		return &source{}
+	}
+	ref := TableRef{
+		Relation: RelationName{Schema: rv.GetSchemaname(), Name: rv.GetRelname()},
+		Alias:    rv.GetAlias().GetAliasname(),
+		Context:  ctx,
+		Stmt:     e.stmt,
+		Location: rv.GetLocation(),
+	}
+	e.tables = append(e.tables, ref)
+
+	src := &source{refname: ref.Relation.Name, table: &ref.Relation}
+	if e.schema != nil {
+		if cols, ok := e.schema.Columns(ref.Relation); ok {
+			src.columns = append([]string{}, cols...)
+		}
+	}
+	if ref.Alias != "" {
+		src.refname = ref.Alias
+		if names := stringList(rv.GetAlias().GetColnames()); names != nil {
+			src.attnames = src.columns
+			src.rename(names)
+		}
+	}
+	return src
+}
+
+// rename applies a column alias list, which renames the leading columns of
+// the source. If the columns of the source are unknown, the aliases are its
+// known leading columns.
+func (s *source) rename(names []string) {
+	if len(names) == 0 {
+		return
+	}
+	if s.columns == nil {
+		s.partial = true
+	}
+	cols := slices.Clone(s.columns)
+	if s.partial && len(names) > len(cols) {
+		cols = append(cols, names[len(cols):]...)
+	}
+	copy(cols, names)
+	s.columns = cols
+}
+
+// has returns whether the source is known to have the named column.
+func (s *source) has(name string) bool {
+	return slices.Contains(s.columns, name)
+}
+
+func (e *extractor) addColumn(c ColumnRef) {
+	c.Stmt = e.stmt
+	e.columns = append(e.columns, c)
+}
+
+// setTargets records the columns assigned by SET clauses.
+func (e *extractor) setTargets(targets []*pganalyze.Node, target *source, sc *scope, ctx Context) {
+	for _, n := range targets {
+		rt := n.GetResTarget()
+		e.addColumn(ColumnRef{Name: rt.GetName(), Table: target.table, Context: ctx, Location: rt.GetLocation()})
+		e.exprs(rt.GetIndirection(), sc, ContextSelect)
+		e.expr(rt.GetVal(), sc, ContextSelect)
+	}
+}
+
+func (e *extractor) exprs(nodes []*pganalyze.Node, sc *scope, ctx Context) {
+	for _, n := range nodes {
+		e.expr(n, sc, ctx)
+	}
+}
+
+// expr records the column references of an expression, analyzing any
+// subqueries it contains with sc as their outer scope.
+func (e *extractor) expr(n *pganalyze.Node, sc *scope, ctx Context) {
+	if n == nil {
+		return
+	}
+	walk.Walk(n, func(msg proto.Message) bool {
+		switch m := msg.(type) {
+		case *pganalyze.ColumnRef:
+			e.columnRef(m, sc, ctx)
+			return false
+		case *pganalyze.SubLink:
+			e.expr(m.GetTestexpr(), sc, ctx)
+			e.subquery(m.GetSubselect(), sc)
+			return false
+		case *pganalyze.SelectStmt:
+			e.selectStmt(m, sc)
+			return false
+		}
+		return true
+	})
+}
+
+func (e *extractor) columnRef(cr *pganalyze.ColumnRef, sc *scope, ctx Context) {
+	fields := cr.GetFields()
+	if len(fields) == 0 {
+		return
+	}
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		if f.GetAStar() != nil {
+			parts[i] = "*"
+		} else {
+			parts[i] = f.GetString_().GetSval()
+		}
+	}
+
+	ref := ColumnRef{
+		Name:     parts[len(parts)-1],
+		Context:  ctx,
+		Location: cr.GetLocation(),
+	}
+	if len(parts) > 1 {
+		ref.Qualifier = parts[:len(parts)-1]
+		if src := resolveQualified(ref.Qualifier, sc); src != nil {
+			ref.resolve(src)
+			ref.Undefined = ref.Name != "*" && src.columns != nil && !src.partial && !src.has(ref.Name)
+		}
+		e.addColumn(ref)
+		return
+	}
+
+	if sc != nil && sc.outputs[ref.Name] {
+		// Reference to an output column in ORDER BY, not to a relation.
+		return
+	}
+
+	possible := resolveUnqualified(ref.Name, sc)
+	if len(possible) == 0 {
+		// Like Postgres, a name that is not a column of any source refers to
+		// the whole row of the source of that name.
+		if src := resolveQualified([]string{ref.Name}, sc); src != nil {
+			ref.resolve(src)
+			ref.WholeRow = true
+			e.addColumn(ref)
+			return
+		}
+	}
+	var known []*source
+	for _, src := range possible {
+		if src.has(ref.Name) {
+			known = append(known, src)
+		}
+	}
+	// Other sources cannot have a column one of them is known to have, as the
+	// reference would be ambiguous.
+	if len(known) == 1 {
+		possible = known
+	}
+	if len(possible) == 1 && possible[0].using == nil {
+		ref.resolve(possible[0])
+		e.addColumn(ref)
+		return
+	}
+
+	for _, src := range possible {
+		for _, c := range src.candidates(ref.Name) {
+			if c.table != nil {
+				ref.Candidates = append(ref.Candidates, *c.table)
+			} else {
+				ref.CandidateSources = append(ref.CandidateSources, c.refname)
+			}
+		}
+	}
+	ref.Ambiguous = len(known) > 1
+	e.addColumn(ref)
+}
+
+// resolve attributes the column to src.
+func (c *ColumnRef) resolve(src *source) {
+	c.Table = src.table
+	if src.table == nil {
+		c.Source = src.refname
+		return
+	}
+	if i := slices.Index(src.columns, c.Name); i >= 0 && i < len(src.attnames) && src.attnames[i] != c.Name {
+		c.Column = src.attnames[i]
+	}
+}
+
+// candidates returns the sources a column of s may belong to. A merged column
+// of a join is computed from the joined sources that may have the column.
+func (s *source) candidates(name string) []*source {
+	if s.using == nil {
+		return []*source{s}
+	}
+	var res []*source
+	for _, src := range s.joined {
+		if src.qualifiedOnly || src.using != nil || src.columns != nil && !src.partial && !src.has(name) {
+			continue
+		}
+		res = append(res, src)
+	}
+	return res
+}
+
+// resolveQualified finds the source a qualified column belongs to, searching
+// from the innermost scope outwards.
+func resolveQualified(qualifier []string, sc *scope) *source {
+	name := qualifier[len(qualifier)-1]
+	schema := ""
+	if len(qualifier) > 1 {
+		schema = qualifier[len(qualifier)-2]
+	}
+	for ; sc != nil; sc = sc.parent {
+		for i := len(sc.sources) - 1; i >= 0; i-- {
+			src := sc.sources[i]
+			if schema != "" {
+				if src.table != nil && src.table.Schema == schema && src.table.Name == name {
+					return src
+				}
+				continue
+			}
+			if src.refname == name {
+				return src
+			}
+		}
+	}
+	return nil
+}
+
+// resolveUnqualified returns the sources an unqualified column may belong to,
+// searching from the innermost scope outwards. A scope is only skipped if all
+// of its sources have known columns and none of them has the column. A
+// column merged by a join replaces the joined sources.
+func resolveUnqualified(name string, sc *scope) []*source {
+	for ; sc != nil; sc = sc.parent {
+		var possible []*source
+		for _, src := range sc.sources {
+			if src.qualifiedOnly {
+				continue
+			}
+			if src.using != nil {
+				if src.has(name) {
+					possible = slices.DeleteFunc(possible, func(p *source) bool { return slices.Contains(src.joined, p) })
+					possible = append(possible, src)
+				}
+				continue
+			}
+			if src.columns != nil && !src.partial && name != "*" && !src.has(name) {
+				continue
+			}
+			possible = append(possible, src)
+		}
+		if len(possible) > 0 {
+			return possible
+		}
+	}
+	return nil
+}
+
+// expandStar returns the columns a star reference in a target list expands
+// to, and false if the columns of any of the sources are not known.
+func expandStar(cr *pganalyze.ColumnRef, sc *scope) ([]string, bool) {
+	fields := cr.GetFields()
+	if len(fields) > 1 {
+		qualifier := stringList(fields[:len(fields)-1])
+		src := resolveQualified(qualifier, sc)
+		if src == nil || src.columns == nil || src.partial {
+			return nil, false
+		}
+		return src.columns, true
+	}
+
+	var cols []string
+	// starts holds the index in cols of the first column of each source.
+	starts := make([]int, 0, len(sc.sources))
+	for _, src := range sc.sources {
+		starts = append(starts, len(cols))
+		if src.using != nil {
+			// The merged columns come first and replace the columns of the
+			// same name of the joined sources, which precede the join.
+			start := starts[len(starts)-1-len(src.joined)]
+			rest := slices.DeleteFunc(slices.Clone(cols[start:]), func(c string) bool { return slices.Contains(src.using, c) })
+			cols = append(append(cols[:start], src.using...), rest...)
+			continue
+		}
+		if src.qualifiedOnly {
+			continue
+		}
+		if src.columns == nil || src.partial {
+			return nil, false
+		}
+		cols = append(cols, src.columns...)
+	}
+	return cols, true
+}
+
+// stringList returns the values of a list of String nodes, or nil if empty.
+func stringList(nodes []*pganalyze.Node) []string {
+	if len(nodes) == 0 {
+		return nil
+	}
+	res := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		if s := n.GetString_(); s != nil {
+			res = append(res, s.GetSval())
+		}
+	}
+	return res
+}
+
+// OutputColumns returns the names of the columns produced by query, such as the
+// definition of a view, following the rules Postgres uses to name unaliased
+// columns. Star references are expanded using schema, which may be nil. If the
+// columns cannot be determined, false is returned.
+func OutputColumns(query *pganalyze.Node, schema Schema) ([]string, bool) {
+	e := &extractor{schema: schema}
+	cols := e.subquery(query, nil)
+	return cols, cols != nil
+}
@@ -0,0 +1,193 @@
+package analysis_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+	"github.com/wasilibs/go-pgquery/analysis"
+)
+
+func rel(name string) *analysis.RelationName {
+	return &analysis.RelationName{Name: name}
+}
+
+var referenceTests = []struct {
+	name    string
+	input   string
+	tables  []analysis.TableRef
+	columns []analysis.ColumnRef
+}{
+	{
+		name:  "alias",
+		input: "SELECT u.name, email FROM users u WHERE u.id = 1",
+		tables: []analysis.TableRef{
+			{Relation: analysis.RelationName{Name: "users"}, Alias: "u", Context: analysis.ContextSelect, Location: 26},
+		},
+		columns: []analysis.ColumnRef{
+			{Name: "name", Qualifier: []string{"u"}, Table: rel("users"), Context: analysis.ContextSelect, Location: 7},
+			{Name: "email", Table: rel("users"), Context: analysis.ContextSelect, Location: 15},
+			{Name: "id", Qualifier: []string{"u"}, Table: rel("users"), Context: analysis.ContextSelect, Location: 40},
+		},
+	},
+	{
+		name:  "ambiguous join",
+		input: "SELECT a.x, y FROM s.a JOIN b ON a.id = b.id",
+		tables: []analysis.TableRef{
+			{Relation: analysis.RelationName{Schema: "s", Name: "a"}, Context: analysis.ContextSelect, Location: 19},
+			{Relation: analysis.RelationName{Name: "b"}, Context: analysis.ContextSelect, Location: 28},
+		},
+		columns: []analysis.ColumnRef{
+			{Name: "x", Qualifier: []string{"a"}, Table: &analysis.RelationName{Schema: "s", Name: "a"}, Context: analysis.ContextSelect, Location: 7},
+			{
+				Name: "y", Context: analysis.ContextSelect, Location: 12,
+				Candidates: []analysis.RelationName{{Schema: "s", Name: "a"}, {Name: "b"}},
+			},
+			{Name: "id", Qualifier: []string{"a"}, Table: &analysis.RelationName{Schema: "s", Name: "a"}, Context: analysis.ContextSelect, Location: 33},
+			{Name: "id", Qualifier: []string{"b"}, Table: rel("b"), Context: analysis.ContextSelect, Location: 40},
+		},
+	},
+	{
+		name:  "column alias list",
+		input: "SELECT a, c FROM t AS x(a, b) JOIN u ON true",
+		tables: []analysis.TableRef{
+			{Relation: analysis.RelationName{Name: "t"}, Alias: "x", Context: analysis.ContextSelect, Location: 17},
+			{Relation: analysis.RelationName{Name: "u"}, Context: analysis.ContextSelect, Location: 35},
+		},
+		columns: []analysis.ColumnRef{
+			{Name: "a", Table: rel("t"), Context: analysis.ContextSelect, Location: 7},
+			{Name: "c", Candidates: []analysis.RelationName{{Name: "t"}, {Name: "u"}}, Context: analysis.ContextSelect, Location: 10},
+		},
+	},
+	{
+		name:  "cte and subquery",
+		input: "WITH r AS (SELECT id FROM orders) SELECT r.id, n FROM r, (SELECT 1 AS n) s WHERE id IN (SELECT order_id FROM items)",
+		tables: []analysis.TableRef{
+			{Relation: analysis.RelationName{Name: "orders"}, Context: analysis.ContextSelect, Location: 26},
+			{Relation: analysis.RelationName{Name: "items"}, Context: analysis.ContextSelect, Location: 109},
+		},
+		columns: []analysis.ColumnRef{
+			{Name: "id", Table: rel("orders"), Context: analysis.ContextSelect, Location: 18},
+			{Name: "id", Qualifier: []string{"r"}, Source: "r", Context: analysis.ContextSelect, Location: 41},
+			{Name: "n", Source: "s", Context: analysis.ContextSelect, Location: 47},
+			{Name: "id", Source: "r", Context: analysis.ContextSelect, Location: 81},
+			{Name: "order_id", Table: rel("items"), Context: analysis.ContextSelect, Location: 95},
+		},
+	},
+	{
+		name:  "lateral sources",
+		input: "SELECT *, k, b FROM t, LATERAL (SELECT t.a AS k) s, xmltable('/r' PASSING d COLUMNS a int) AS x, JSON_TABLE(j, '$' COLUMNS (b int)) AS y",
+		tables: []analysis.TableRef{
+			{Relation: analysis.RelationName{Name: "t"}, Context: analysis.ContextSelect, Location: 20},
+		},
+		columns: []analysis.ColumnRef{
+			{Name: "*", Candidates: []analysis.RelationName{{Name: "t"}}, CandidateSources: []string{"s", "x", "y"}, Context: analysis.ContextSelect, Location: 7},
+			{Name: "k", Source: "s", Context: analysis.ContextSelect, Location: 10},
+			{Name: "b", Source: "y", Context: analysis.ContextSelect, Location: 13},
+			{Name: "a", Qualifier: []string{"t"}, Table: rel("t"), Context: analysis.ContextSelect, Location: 39},
+			{Name: "d", Table: rel("t"), Context: analysis.ContextSelect, Location: 74},
+			{Name: "j", Table: rel("t"), Context: analysis.ContextSelect, Location: 108},
+		},
+	},
+	{
+		name:  "correlated subquery",
+		input: "SELECT 1 FROM users u WHERE EXISTS (SELECT 1 FROM bans WHERE bans.user_id = u.id)",
+		tables: []analysis.TableRef{
+			{Relation: analysis.RelationName{Name: "users"}, Alias: "u", Context: analysis.ContextSelect, Location: 14},
+			{Relation: analysis.RelationName{Name: "bans"}, Context: analysis.ContextSelect, Location: 50},
+		},
+		columns: []analysis.ColumnRef{
+			{Name: "user_id", Qualifier: []string{"bans"}, Table: rel("bans"), Context: analysis.ContextSelect, Location: 61},
+			{Name: "id", Qualifier: []string{"u"}, Table: rel("users"), Context: analysis.ContextSelect, Location: 76},
+		},
+	},
+	{
+		name:  "order by alias",
+		input: "SELECT count(*) AS c FROM t ORDER BY c",
+		tables: []analysis.TableRef{
+			{Relation: analysis.RelationName{Name: "t"}, Context: analysis.ContextSelect, Location: 26},
+		},
+	},
+	{
+		name:  "insert select",
+		input: "INSERT INTO logs (msg) SELECT m FROM queue RETURNING id",
+		tables: []analysis.TableRef{
+			{Relation: analysis.RelationName{Name: "logs"}, Context: analysis.ContextInsert, Location: 12},
+			{Relation: analysis.RelationName{Name: "queue"}, Context: analysis.ContextSelect, Location: 37},
+		},
+		columns: []analysis.ColumnRef{
+			{Name: "msg", Table: rel("logs"), Context: analysis.ContextInsert, Location: 18},
+			{Name: "m", Table: rel("queue"), Context: analysis.ContextSelect, Location: 30},
+			{Name: "id", Table: rel("logs"), Context: analysis.ContextSelect, Location: 53},
+		},
+	},
+	{
+		name:  "update",
+		input: "UPDATE accounts SET balance = 0 WHERE id = 1",
+		tables: []analysis.TableRef{
+			{Relation: analysis.RelationName{Name: "accounts"}, Context: analysis.ContextUpdate, Location: 7},
+		},
+		columns: []analysis.ColumnRef{
+			{Name: "balance", Table: rel("accounts"), Context: analysis.ContextUpdate, Location: 20},
+			{Name: "id", Table: rel("accounts"), Context: analysis.ContextSelect, Location: 38},
+		},
+	},
+	{
+		name:  "delete using",
+		input: "DELETE FROM a USING b WHERE a.id = b.id",
+		tables: []analysis.TableRef{
+			{Relation: analysis.RelationName{Name: "a"}, Context: analysis.ContextDelete, Location: 12},
+			{Relation: analysis.RelationName{Name: "b"}, Context: analysis.ContextSelect, Location: 20},
+		},
+		columns: []analysis.ColumnRef{
+			{Name: "id", Qualifier: []string{"a"}, Table: rel("a"), Context: analysis.ContextSelect, Location: 28},
+			{Name: "id", Qualifier: []string{"b"}, Table: rel("b"), Context: analysis.ContextSelect, Location: 35},
+		},
+	},
+	{
+		name:  "ddl",
+		input: "CREATE TABLE t (id int, ref int REFERENCES other (id)); DROP TABLE s.x",
+		tables: []analysis.TableRef{
+			{Relation: analysis.RelationName{Name: "t"}, Context: analysis.ContextDDL, Location: 13},
+			{Relation: analysis.RelationName{Name: "other"}, Context: analysis.ContextDDL, Location: 43},
+			{Relation: analysis.RelationName{Schema: "s", Name: "x"}, Context: analysis.ContextDDL, Stmt: 1, Location: -1},
+		},
+		columns: []analysis.ColumnRef{
+			{Name: "id", Table: rel("t"), Context: analysis.ContextDDL, Location: 16},
+			{Name: "ref", Table: rel("t"), Context: analysis.ContextDDL, Location: 24},
+			{Name: "id", Table: rel("other"), Context: analysis.ContextDDL, Location: 43},
+		},
+	},
+	{
+		name:  "view",
+		input: "CREATE VIEW v AS SELECT id FROM t",
+		tables: []analysis.TableRef{
+			{Relation: analysis.RelationName{Name: "v"}, Context: analysis.ContextDDL, Location: 12},
+			{Relation: analysis.RelationName{Name: "t"}, Context: analysis.ContextSelect, Location: 32},
+		},
+		columns: []analysis.ColumnRef{
+			{Name: "id", Table: rel("t"), Context: analysis.ContextSelect, Location: 24},
+		},
+	},
+}
+
+func TestExtractReferences(t *testing.T) {
+	for _, tc := range referenceTests {
+		t.Run(tc.name, func(t *testing.T) {
+			tree, err := pg_query.Parse(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tables, columns := analysis.ExtractReferences(tree)
+			if diff := cmp.Diff(tc.tables, tables, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("tables mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.columns, columns, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("columns mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,531 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+	"github.com/wasilibs/go-pgquery/internal/walk"
+)
+
+// Span is a byte range of the parsed SQL.
+type Span struct {
+	// Start is the byte offset of the first byte.
+	Start int
+	// End is the byte offset after the last byte.
+	End int
+}
+
+// Spans returns the byte ranges of the nodes of tree in sql, which tree must
+// have been parsed from. Both the *pganalyze.Node wrappers and the messages
+// they hold, as well as messages referenced directly such as the *RangeVar of
+// an INSERT, are keys of the map. Nodes without any location in the parse
+// tree, such as the names in a ColumnRef, have no span.
+//
+// Parse trees only record where nodes start, so their ends are derived from
+// the tokens of sql: a node spans at least the nodes it contains, and is
+// extended to balance parentheses, brackets and CASE ... END and to include
+// trailing parts that have no location of their own, such as the remaining
+// names of qualified names, aliases, IS NULL and sort directions. Statements
+// span their text without surrounding whitespace, comments and semicolons.
+func Spans(sql string, tree *pganalyze.ParseResult) (map[proto.Message]Span, error) {
+	spans := map[proto.Message]Span{}
+	err := WalkSpans(sql, tree, func(msg proto.Message, span Span) bool {
+		spans[msg] = span
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return spans, nil
+}
+
+// WalkSpans calls fn for the nodes of tree with a span, as computed by Spans,
+// in depth-first order. Like walk order, a *pganalyze.Node wrapper is visited
+// before the message it holds. If fn returns false, the children of the
+// visited message are skipped.
+func WalkSpans(sql string, tree *pganalyze.ParseResult, fn func(msg proto.Message, span Span) bool) error {
+	scan, err := pg_query.Scan(sql)
+	if err != nil {
+		return fmt.Errorf("analysis: scanning input: %w", err)
+	}
+	s := &spanner{sql: sql, spans: map[proto.Message]tokenRange{}}
+	for _, t := range scan.GetTokens() {
+		if t.GetToken() != pganalyze.Token_SQL_COMMENT && t.GetToken() != pganalyze.Token_C_COMMENT {
+			s.tokens = append(s.tokens, t)
+		}
+	}
+	for _, raw := range tree.GetStmts() {
+		s.statement(sql, raw)
+	}
+
+	walk.Walk(tree, func(msg proto.Message) bool {
+		r, ok := s.spans[msg]
+		if !ok {
+			return true
+		}
+		return fn(msg, Span{Start: int(s.tokens[r.first].GetStart()), End: int(s.tokens[r.last].GetEnd())})
+	})
+	return nil
+}
+
+// tokenRange is a range of tokens, with the indexes of the first and last
+// token.
+type tokenRange struct {
+	first, last int
+}
+
+type spanner struct {
+	// tokens are the tokens of the input without comments.
+	tokens []*pganalyze.ScanToken
+	sql    string
+	spans  map[proto.Message]tokenRange
+}
+
+// statement computes the spans of the nodes of raw, with the statement
+// spanning the tokens of raw.
+func (s *spanner) statement(sql string, raw *pganalyze.RawStmt) {
+	start := int(raw.GetStmtLocation())
+	end := len(sql)
+	if raw.GetStmtLen() > 0 {
+		end = start + int(raw.GetStmtLen())
+	}
+	first := s.tokenAt(start)
+	last := first - 1
+	for last+1 < len(s.tokens) && int(s.tokens[last+1].GetStart()) < end {
+		last++
+	}
+	// The last statement extends to the end of the input, including the
+	// semicolon terminating it.
+	for last >= first && s.is(last, pganalyze.Token_ASCII_59) {
+		last--
+	}
+
+	stmt := raw.GetStmt()
+	if stmt == nil {
+		return
+	}
+	msg := walk.Unwrap(stmt)
+	if msg == nil {
+		return
+	}
+	s.node(msg.ProtoReflect(), true)
+	if last >= first {
+		r := tokenRange{first: first, last: last}
+		s.spans[raw], s.spans[stmt], s.spans[msg] = r, r, r
+	}
+}
+
+// node computes the spans of m and the messages it contains, returning the
+// span of m. top is set for statements, whose span is given by their RawStmt.
+func (s *spanner) node(m protoreflect.Message, top bool) (tokenRange, bool) {
+	r, ok := tokenRange{}, false
+	add := func(c tokenRange) {
+		if !ok {
+			r, ok = c, true
+			return
+		}
+		r.first, r.last = min(r.first, c.first), max(r.last, c.last)
+	}
+
+	fields := m.Descriptor().Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		if !m.Has(fd) {
+			continue
+		}
+		switch {
+		case fd.Kind() == protoreflect.MessageKind && fd.IsList():
+			l := m.Get(fd).List()
+			for j := range l.Len() {
+				if c, ok := s.child(l.Get(j).Message()); ok {
+					add(c)
+				}
+			}
+		case fd.Kind() == protoreflect.MessageKind:
+			if c, ok := s.child(m.Get(fd).Message()); ok {
+				add(c)
+			}
+		case fd.Name() == "location" && fd.Kind() == protoreflect.Int32Kind:
+			if loc := int(m.Get(fd).Int()); loc >= 0 {
+				i := s.tokenAt(loc)
+				if i < len(s.tokens) {
+					add(tokenRange{first: i, last: i})
+				}
+			}
+		}
+	}
+	if !ok || top {
+		return r, ok
+	}
+
+	r = s.balance(s.extend(m.Interface(), s.balance(r)))
+	s.spans[m.Interface()] = r
+	return r, true
+}
+
+// child computes the span of a message referenced by a field, which may be a
+// *pganalyze.Node wrapper.
+func (s *spanner) child(m protoreflect.Message) (tokenRange, bool) {
+	n, ok := m.Interface().(*pganalyze.Node)
+	if !ok {
+		return s.node(m, false)
+	}
+	msg := walk.Unwrap(n)
+	if msg == nil {
+		return tokenRange{}, false
+	}
+	r, ok := s.node(msg.ProtoReflect(), false)
+	if ok {
+		s.spans[n] = r
+	}
+	return r, ok
+}
+
+// tokenAt returns the index of the token starting at offset loc, or of the
+// first token after it.
+func (s *spanner) tokenAt(loc int) int {
+	return sort.Search(len(s.tokens), func(i int) bool { return int(s.tokens[i].GetStart()) >= loc })
+}
+
+// is returns whether the token at index i is one of toks.
+func (s *spanner) is(i int, toks ...pganalyze.Token) bool {
+	if i < 0 || i >= len(s.tokens) {
+		return false
+	}
+	for _, t := range toks {
+		if s.tokens[i].GetToken() == t {
+			return true
+		}
+	}
+	return false
+}
+
+// balance extends r to include the closing tokens of parentheses, brackets
+// and CASE expressions opened in it, and the opening tokens of those closed
+// in it.
+func (s *spanner) balance(r tokenRange) tokenRange {
+	depth, minDepth := 0, 0
+	for i := r.first; i <= r.last; i++ {
+		depth += s.nesting(i)
+		minDepth = min(minDepth, depth)
+	}
+	for d := minDepth; d < 0 && r.first > 0; {
+		r.first--
+		d += s.nesting(r.first)
+		if d > 0 {
+			// Not an opening token of the range, as for a closing token
+			// before an unbalanced range.
+			r.first++
+			break
+		}
+	}
+	for depth > 0 && r.last+1 < len(s.tokens) {
+		r.last++
+		depth += s.nesting(r.last)
+	}
+	return r
+}
+
+// nesting returns 1 for tokens opening a nested part, -1 for tokens closing
+// it and 0 for others.
+func (s *spanner) nesting(i int) int {
+	switch s.tokens[i].GetToken() { //nolint:exhaustive // Other tokens do not nest.
+	case pganalyze.Token_ASCII_40, pganalyze.Token_ASCII_91, pganalyze.Token_CASE:
+		return 1
+	case pganalyze.Token_ASCII_41, pganalyze.Token_ASCII_93, pganalyze.Token_END_P:
+		return -1
+	}
+	return 0
+}
+
+// extend extends r to include the trailing parts of msg without location.
+func (s *spanner) extend(msg proto.Message, r tokenRange) tokenRange {
+	switch msg := msg.(type) {
+	case *pganalyze.A_Const:
+		if s.tokens[r.first].GetToken() == pganalyze.Token_ASCII_45 && r.first == r.last && r.last+1 < len(s.tokens) {
+			// Negative numbers start with the minus sign.
+			r.last++
+		}
+		if s.is(r.last, pganalyze.Token_USCONST) && s.is(r.last+1, pganalyze.Token_UESCAPE) {
+			r.last += 2
+		}
+	case *pganalyze.ColumnRef:
+		r.last = s.qualified(r.last)
+	case *pganalyze.A_Indirection:
+		// Field selection requires parentheses around expressions other than
+		// column references.
+		if s.is(r.first-1, pganalyze.Token_ASCII_40) && s.is(r.last+1, pganalyze.Token_ASCII_41) &&
+			s.is(r.last+2, pganalyze.Token_ASCII_46, pganalyze.Token_ASCII_91) {
+			r.first--
+			r.last++
+		}
+		for {
+			if s.is(r.last+1, pganalyze.Token_ASCII_91) {
+				r.last = s.balance(tokenRange{first: r.last + 1, last: r.last + 1}).last
+				continue
+			}
+			if i := s.qualified(r.last); i != r.last {
+				r.last = i
+				continue
+			}
+			break
+		}
+	case *pganalyze.CollateClause:
+		if s.is(r.last, pganalyze.Token_COLLATE) {
+			r.last = s.qualified(r.last + 1)
+		}
+	case *pganalyze.InferClause:
+		if msg.GetConname() != "" && s.is(r.last+1, pganalyze.Token_CONSTRAINT) {
+			r.last += 2
+		}
+	case *pganalyze.OnConflictClause:
+		if msg.GetAction() == pganalyze.OnConflictAction_ONCONFLICT_NOTHING && s.is(r.last+1, pganalyze.Token_DO) {
+			r.last += 2
+		}
+	case *pganalyze.DefElem:
+		r.last = s.defElem(msg, r)
+	case *pganalyze.Constraint:
+		r.last = s.constraint(msg, r.last)
+	case *pganalyze.RangeVar:
+		r.last = s.qualified(r.last)
+		r.last = s.alias(r.last, msg.GetAlias())
+	case *pganalyze.FuncCall:
+		r.last = s.qualified(r.last)
+		if s.is(r.last+1, pganalyze.Token_ASCII_40) {
+			r = s.balance(tokenRange{first: r.first, last: r.last + 1})
+		}
+	case *pganalyze.TypeName:
+		r.last = s.typeName(r.last)
+	case *pganalyze.ResTarget:
+		// Only target lists of SELECT have names after the value.
+		if msg.GetName() != "" && msg.GetVal() != nil && s.spans[walk.Unwrap(msg.GetVal())].first == r.first {
+			if s.is(r.last+1, pganalyze.Token_AS) {
+				r.last++
+			}
+			r.last++
+		}
+	case *pganalyze.NullTest, *pganalyze.BooleanTest:
+		for s.is(r.last+1, pganalyze.Token_IS, pganalyze.Token_NOT, pganalyze.Token_NULL_P, pganalyze.Token_TRUE_P,
+			pganalyze.Token_FALSE_P, pganalyze.Token_UNKNOWN, pganalyze.Token_ISNULL, pganalyze.Token_NOTNULL) {
+			r.last++
+		}
+	case *pganalyze.SortBy:
+		for s.is(r.last+1, pganalyze.Token_ASC, pganalyze.Token_DESC, pganalyze.Token_NULLS_P, pganalyze.Token_FIRST_P, pganalyze.Token_LAST_P) {
+			r.last++
+		}
+	case *pganalyze.RangeSubselect:
+		if s.is(r.first-1, pganalyze.Token_ASCII_40) && s.is(r.last+1, pganalyze.Token_ASCII_41) {
+			r.first--
+			r.last++
+		}
+		r.first = s.lateral(r.first)
+		r.last = s.alias(r.last, msg.GetAlias())
+	case *pganalyze.RangeFunction:
+		if msg.GetIsRowsfrom() {
+			if s.is(r.first-1, pganalyze.Token_ASCII_40) {
+				r.first--
+			}
+			if s.is(r.first-1, pganalyze.Token_FROM) && s.is(r.first-2, pganalyze.Token_ROWS) {
+				r.first -= 2
+			}
+			r = s.balance(r)
+		}
+		if msg.GetOrdinality() && s.is(r.last+1, pganalyze.Token_WITH) && s.is(r.last+2, pganalyze.Token_ORDINALITY) {
+			r.last += 2
+		}
+		// With a column definition list, the alias precedes the column
+		// definitions the span already includes.
+		if len(msg.GetColdeflist()) == 0 {
+			r.last = s.alias(r.last, msg.GetAlias())
+		}
+		r.first = s.lateral(r.first)
+	case *pganalyze.RangeTableFunc:
+		r.last = s.alias(r.last, msg.GetAlias())
+		r.first = s.lateral(r.first)
+	case *pganalyze.JsonTable:
+		r.last = s.alias(r.last, msg.GetAlias())
+		r.first = s.lateral(r.first)
+	case *pganalyze.JoinExpr:
+		if len(msg.GetUsingClause()) > 0 && s.is(r.last+1, pganalyze.Token_USING) {
+			r = s.balance(tokenRange{first: r.first, last: r.last + 2})
+			r.last = s.alias(r.last, msg.GetJoinUsingAlias())
+		}
+		r.last = s.alias(r.last, msg.GetAlias())
+	case *pganalyze.WithClause:
+		if s.is(r.first-1, pganalyze.Token_RECURSIVE) {
+			r.first--
+		}
+		if s.is(r.first-1, pganalyze.Token_WITH) {
+			r.first--
+		}
+	case *pganalyze.SelectStmt:
+		r.first = s.statementStart(r.first)
+		if len(msg.GetLockingClause()) > 0 {
+			for s.is(r.last+1, pganalyze.Token_FOR, pganalyze.Token_UPDATE, pganalyze.Token_SHARE, pganalyze.Token_NO,
+				pganalyze.Token_KEY, pganalyze.Token_NOWAIT, pganalyze.Token_SKIP, pganalyze.Token_LOCKED) {
+				r.last++
+			}
+		}
+	case *pganalyze.InsertStmt, *pganalyze.UpdateStmt, *pganalyze.DeleteStmt, *pganalyze.MergeStmt:
+		r.first = s.statementStart(r.first)
+	}
+	return r
+}
+
+// defElem returns the index of the last token of option d spanning r, whose
+// value has no location if it is a name or number, as in LANGUAGE sql or
+// WITH (fillfactor = 70).
+func (s *spanner) defElem(d *pganalyze.DefElem, r tokenRange) int {
+	var values []string
+	for _, n := range append([]*pganalyze.Node{d.GetArg()}, d.GetArg().GetList().GetItems()...) {
+		switch n := walk.Unwrap(n).(type) {
+		case *pganalyze.String:
+			values = append(values, n.GetSval())
+		case *pganalyze.Integer:
+			values = append(values, strconv.Itoa(int(n.GetIval())))
+		case *pganalyze.Float:
+			values = append(values, n.GetFval())
+		}
+	}
+	i := r.last
+	for j, v := range values {
+		k := i + 1
+		switch {
+		case j == 0 && s.is(k, pganalyze.Token_ASCII_61):
+			k++
+		case j > 0 && s.is(k, pganalyze.Token_ASCII_44):
+			k++
+		}
+		if !s.matches(k, v) {
+			break
+		}
+		i = k
+	}
+	return i
+}
+
+// matches returns whether the token at index i is value, ignoring quotes and
+// case, or a string constant.
+func (s *spanner) matches(i int, value string) bool {
+	if i >= len(s.tokens) {
+		return false
+	}
+	if s.is(i, pganalyze.Token_SCONST) {
+		// Strings may be escaped or dollar-quoted.
+		return true
+	}
+	text := s.sql[s.tokens[i].GetStart():s.tokens[i].GetEnd()]
+	if len(text) >= 2 && text[0] == '"' && text[len(text)-1] == text[0] {
+		text = text[1 : len(text)-1]
+	}
+	return strings.EqualFold(text, value)
+}
+
+// constraint returns the index of the last token of constraint c whose
+// keyword or expression ends at index i.
+func (s *spanner) constraint(c *pganalyze.Constraint, i int) int {
+	group := func() {
+		if s.is(i+1, pganalyze.Token_ASCII_40) {
+			i = s.balance(tokenRange{first: i + 1, last: i + 1}).last
+		}
+	}
+	switch c.GetContype() { //nolint:exhaustive // Other constraints end with their expression.
+	case pganalyze.ConstrType_CONSTR_NOTNULL, pganalyze.ConstrType_CONSTR_NULL:
+		for s.is(i+1, pganalyze.Token_NOT, pganalyze.Token_NULL_P) {
+			i++
+		}
+	case pganalyze.ConstrType_CONSTR_PRIMARY, pganalyze.ConstrType_CONSTR_UNIQUE:
+		for s.is(i+1, pganalyze.Token_KEY, pganalyze.Token_NULLS_P, pganalyze.Token_NOT, pganalyze.Token_DISTINCT) {
+			i++
+		}
+		group()
+	case pganalyze.ConstrType_CONSTR_FOREIGN:
+		group()
+		for s.is(i+1, pganalyze.Token_MATCH, pganalyze.Token_FULL, pganalyze.Token_SIMPLE, pganalyze.Token_PARTIAL,
+			pganalyze.Token_ON, pganalyze.Token_DELETE_P, pganalyze.Token_UPDATE, pganalyze.Token_CASCADE,
+			pganalyze.Token_RESTRICT, pganalyze.Token_SET, pganalyze.Token_NULL_P, pganalyze.Token_DEFAULT,
+			pganalyze.Token_NO, pganalyze.Token_ACTION) {
+			i++
+			group()
+		}
+	}
+	return min(i, len(s.tokens)-1)
+}
+
+// qualified returns the index of the last token of a qualified name whose
+// first name ends at index i.
+func (s *spanner) qualified(i int) int {
+	for s.is(i+1, pganalyze.Token_ASCII_46) && i+2 < len(s.tokens) {
+		i += 2
+	}
+	return i
+}
+
+// alias returns the index of the last token of alias following index i.
+func (s *spanner) alias(i int, alias *pganalyze.Alias) int {
+	if alias == nil {
+		return i
+	}
+	if s.is(i+1, pganalyze.Token_AS) {
+		i++
+	}
+	i++
+	if len(alias.GetColnames()) > 0 && s.is(i+1, pganalyze.Token_ASCII_40) {
+		i = s.balance(tokenRange{first: i + 1, last: i + 1}).last
+	}
+	return min(i, len(s.tokens)-1)
+}
+
+// lateral returns the index of the LATERAL keyword preceding the FROM item
+// starting at index i, or i if there is none.
+func (s *spanner) lateral(i int) int {
+	if s.is(i-1, pganalyze.Token_LATERAL_P) {
+		return i - 1
+	}
+	return i
+}
+
+// typeName returns the index of the last token of a type name whose first
+// name or type modifiers end at index i.
+func (s *spanner) typeName(i int) int {
+	i = s.qualified(i)
+	for {
+		switch {
+		case s.is(i+1, pganalyze.Token_PRECISION, pganalyze.Token_VARYING, pganalyze.Token_WITH, pganalyze.Token_WITHOUT,
+			pganalyze.Token_TIME, pganalyze.Token_ZONE, pganalyze.Token_ARRAY):
+			i++
+		case s.is(i+1, pganalyze.Token_ASCII_91):
+			i = s.balance(tokenRange{first: i + 1, last: i + 1}).last
+		default:
+			return i
+		}
+	}
+}
+
+// statementStart returns the index of the first token of a nested statement
+// whose first node starts at index i, such as the SELECT keyword.
+func (s *spanner) statementStart(i int) int {
+	start := i
+	for j := i - 1; j >= 0; j-- {
+		t := s.tokens[j]
+		switch {
+		case s.is(j, pganalyze.Token_SELECT, pganalyze.Token_VALUES, pganalyze.Token_WITH, pganalyze.Token_INSERT,
+			pganalyze.Token_UPDATE, pganalyze.Token_DELETE_P, pganalyze.Token_MERGE, pganalyze.Token_TABLE):
+			start = j
+		case s.is(j, pganalyze.Token_ASCII_40) && s.is(j-1, pganalyze.Token_VALUES):
+		case t.GetKeywordKind() == pganalyze.KeywordKind_NO_KEYWORD:
+			return start
+		case s.is(j, pganalyze.Token_UNION, pganalyze.Token_INTERSECT, pganalyze.Token_EXCEPT):
+			// Set operations separate the statements they combine.
+			return start
+		}
+	}
+	return start
+}
@@ -0,0 +1,272 @@
+package analysis_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+	"google.golang.org/protobuf/proto"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+	"github.com/wasilibs/go-pgquery/analysis"
+)
+
+var spanTests = []struct {
+	name  string
+	input string
+	// spans are the texts of the spans in walk order, prefixed by the type of
+	// the node, without *pganalyze.Node wrappers.
+	spans []string
+}{
+	{
+		name:  "expressions",
+		input: "SELECT a.b AS x, (a) + -1, count(*) FILTER (WHERE c IS NULL) FROM t",
+		spans: []string{
+			"RawStmt: SELECT a.b AS x, (a) + -1, count(*) FILTER (WHERE c IS NULL) FROM t",
+			"SelectStmt: SELECT a.b AS x, (a) + -1, count(*) FILTER (WHERE c IS NULL) FROM t",
+			"ResTarget: a.b AS x",
+			"ColumnRef: a.b",
+			"ResTarget: (a) + -1",
+			"A_Expr: (a) + -1",
+			"ColumnRef: a",
+			"A_Const: -1",
+			"ResTarget: count(*) FILTER (WHERE c IS NULL)",
+			"FuncCall: count(*) FILTER (WHERE c IS NULL)",
+			"NullTest: c IS NULL",
+			"ColumnRef: c",
+			"RangeVar: t",
+		},
+	},
+	{
+		name:  "case and indirection",
+		input: "SELECT CASE WHEN x[1] THEN 'y' END, (f(x)).y, z COLLATE \"C\"",
+		spans: []string{
+			"RawStmt: SELECT CASE WHEN x[1] THEN 'y' END, (f(x)).y, z COLLATE \"C\"",
+			"SelectStmt: SELECT CASE WHEN x[1] THEN 'y' END, (f(x)).y, z COLLATE \"C\"",
+			"ResTarget: CASE WHEN x[1] THEN 'y' END",
+			"CaseExpr: CASE WHEN x[1] THEN 'y' END",
+			"CaseWhen: WHEN x[1] THEN 'y'",
+			"A_Indirection: x[1]",
+			"ColumnRef: x",
+			"A_Indices: 1",
+			"A_Const: 1",
+			"A_Const: 'y'",
+			"ResTarget: (f(x)).y",
+			"A_Indirection: (f(x)).y",
+			"FuncCall: f(x)",
+			"ColumnRef: x",
+			"ResTarget: z COLLATE \"C\"",
+			"CollateClause: z COLLATE \"C\"",
+			"ColumnRef: z",
+		},
+	},
+	{
+		name:  "from clause",
+		input: "SELECT 1 FROM s.t AS u(p) JOIN v USING (id), (SELECT 2) AS w, LATERAL f() g ORDER BY 1 DESC NULLS LAST",
+		spans: []string{
+			"RawStmt: SELECT 1 FROM s.t AS u(p) JOIN v USING (id), (SELECT 2) AS w, LATERAL f() g ORDER BY 1 DESC NULLS LAST",
+			"SelectStmt: SELECT 1 FROM s.t AS u(p) JOIN v USING (id), (SELECT 2) AS w, LATERAL f() g ORDER BY 1 DESC NULLS LAST",
+			"ResTarget: 1",
+			"A_Const: 1",
+			"JoinExpr: s.t AS u(p) JOIN v USING (id)",
+			"RangeVar: s.t AS u(p)",
+			"RangeVar: v",
+			"RangeSubselect: (SELECT 2) AS w",
+			"SelectStmt: SELECT 2",
+			"ResTarget: 2",
+			"A_Const: 2",
+			"RangeFunction: LATERAL f() g",
+			"List: f()",
+			"FuncCall: f()",
+			"SortBy: 1 DESC NULLS LAST",
+			"A_Const: 1",
+		},
+	},
+	{
+		name:  "table functions",
+		input: "SELECT 1 FROM t, f(t.a) WITH ORDINALITY AS q, LATERAL f(x) WITH ORDINALITY, ROWS FROM (f(x), g() AS (b int)) AS r, f() AS s(c int), LATERAL ROWS FROM (f()) WITH ORDINALITY, f() AS (d int)",
+		spans: []string{
+			"RawStmt: SELECT 1 FROM t, f(t.a) WITH ORDINALITY AS q, LATERAL f(x) WITH ORDINALITY, ROWS FROM (f(x), g() AS (b int)) AS r, f() AS s(c int), LATERAL ROWS FROM (f()) WITH ORDINALITY, f() AS (d int)",
+			"SelectStmt: SELECT 1 FROM t, f(t.a) WITH ORDINALITY AS q, LATERAL f(x) WITH ORDINALITY, ROWS FROM (f(x), g() AS (b int)) AS r, f() AS s(c int), LATERAL ROWS FROM (f()) WITH ORDINALITY, f() AS (d int)",
+			"ResTarget: 1",
+			"A_Const: 1",
+			"RangeVar: t",
+			"RangeFunction: f(t.a) WITH ORDINALITY AS q",
+			"List: f(t.a)",
+			"FuncCall: f(t.a)",
+			"ColumnRef: t.a",
+			"RangeFunction: LATERAL f(x) WITH ORDINALITY",
+			"List: f(x)",
+			"FuncCall: f(x)",
+			"ColumnRef: x",
+			"RangeFunction: ROWS FROM (f(x), g() AS (b int)) AS r",
+			"List: f(x)",
+			"FuncCall: f(x)",
+			"ColumnRef: x",
+			"List: g() AS (b int)",
+			"FuncCall: g()",
+			"List: b int",
+			"ColumnDef: b int",
+			"TypeName: int",
+			"RangeFunction: f() AS s(c int)",
+			"List: f()",
+			"FuncCall: f()",
+			"ColumnDef: c int",
+			"TypeName: int",
+			"RangeFunction: LATERAL ROWS FROM (f()) WITH ORDINALITY",
+			"List: f()",
+			"FuncCall: f()",
+			"RangeFunction: f() AS (d int)",
+			"List: f()",
+			"FuncCall: f()",
+			"ColumnDef: d int",
+			"TypeName: int",
+		},
+	},
+	{
+		name:  "xmltable and json_table",
+		input: "SELECT 1 FROM xmltable('/r' PASSING d COLUMNS a int) AS z, LATERAL JSON_TABLE(j, '$[*]' COLUMNS (b int PATH '$.b')) AS y (b)",
+		spans: []string{
+			"RawStmt: SELECT 1 FROM xmltable('/r' PASSING d COLUMNS a int) AS z, LATERAL JSON_TABLE(j, '$[*]' COLUMNS (b int PATH '$.b')) AS y (b)",
+			"SelectStmt: SELECT 1 FROM xmltable('/r' PASSING d COLUMNS a int) AS z, LATERAL JSON_TABLE(j, '$[*]' COLUMNS (b int PATH '$.b')) AS y (b)",
+			"ResTarget: 1",
+			"A_Const: 1",
+			"RangeTableFunc: xmltable('/r' PASSING d COLUMNS a int) AS z",
+			"ColumnRef: d",
+			"A_Const: '/r'",
+			"RangeTableFuncCol: a int",
+			"TypeName: int",
+			"JsonTable: LATERAL JSON_TABLE(j, '$[*]' COLUMNS (b int PATH '$.b')) AS y (b)",
+			"JsonValueExpr: j",
+			"ColumnRef: j",
+			"JsonTablePathSpec: '$[*]'",
+			"A_Const: '$[*]'",
+			"JsonTableColumn: b int PATH '$.b'",
+			"TypeName: int",
+			"JsonTablePathSpec: '$.b'",
+			"A_Const: '$.b'",
+		},
+	},
+	{
+		name:  "nested statements",
+		input: "WITH q AS (SELECT 1) SELECT * FROM q WHERE x IN (SELECT y FROM r) UNION SELECT 2 FOR UPDATE",
+		spans: []string{
+			"RawStmt: WITH q AS (SELECT 1) SELECT * FROM q WHERE x IN (SELECT y FROM r) UNION SELECT 2 FOR UPDATE",
+			"SelectStmt: WITH q AS (SELECT 1) SELECT * FROM q WHERE x IN (SELECT y FROM r) UNION SELECT 2 FOR UPDATE",
+			"WithClause: WITH q AS (SELECT 1)",
+			"CommonTableExpr: q AS (SELECT 1)",
+			"SelectStmt: SELECT 1",
+			"ResTarget: 1",
+			"A_Const: 1",
+			"SelectStmt: SELECT * FROM q WHERE x IN (SELECT y FROM r)",
+			"ResTarget: *",
+			"ColumnRef: *",
+			"RangeVar: q",
+			"SubLink: x IN (SELECT y FROM r)",
+			"ColumnRef: x",
+			"SelectStmt: SELECT y FROM r",
+			"ResTarget: y",
+			"ColumnRef: y",
+			"RangeVar: r",
+			"SelectStmt: SELECT 2",
+			"ResTarget: 2",
+			"A_Const: 2",
+		},
+	},
+	{
+		name:  "statements",
+		input: "/* c */ INSERT INTO t VALUES (1, -- c\n 2) ON CONFLICT ON CONSTRAINT k DO NOTHING; UPDATE t SET a = U&'x' UESCAPE '!';",
+		spans: []string{
+			"RawStmt: INSERT INTO t VALUES (1, -- c\n 2) ON CONFLICT ON CONSTRAINT k DO NOTHING",
+			"InsertStmt: INSERT INTO t VALUES (1, -- c\n 2) ON CONFLICT ON CONSTRAINT k DO NOTHING",
+			"RangeVar: t",
+			"SelectStmt: VALUES (1, -- c\n 2)",
+			"List: 1, -- c\n 2",
+			"A_Const: 1",
+			"A_Const: 2",
+			"OnConflictClause: ON CONFLICT ON CONSTRAINT k DO NOTHING",
+			"InferClause: ON CONSTRAINT k",
+			"RawStmt: UPDATE t SET a = U&'x' UESCAPE '!'",
+			"UpdateStmt: UPDATE t SET a = U&'x' UESCAPE '!'",
+			"RangeVar: t",
+			"ResTarget: a = U&'x' UESCAPE '!'",
+			"A_Const: U&'x' UESCAPE '!'",
+		},
+	},
+	{
+		name:  "ddl",
+		input: "CREATE TABLE t (a int NOT NULL, b double precision[] REFERENCES u (c) ON DELETE CASCADE, UNIQUE (a, b)) WITH (fillfactor = 70)",
+		spans: []string{
+			"RawStmt: CREATE TABLE t (a int NOT NULL, b double precision[] REFERENCES u (c) ON DELETE CASCADE, UNIQUE (a, b)) WITH (fillfactor = 70)",
+			"CreateStmt: CREATE TABLE t (a int NOT NULL, b double precision[] REFERENCES u (c) ON DELETE CASCADE, UNIQUE (a, b)) WITH (fillfactor = 70)",
+			"RangeVar: t",
+			"ColumnDef: a int NOT NULL",
+			"TypeName: int",
+			"Constraint: NOT NULL",
+			"ColumnDef: b double precision[] REFERENCES u (c) ON DELETE CASCADE",
+			"TypeName: double precision[]",
+			"Constraint: REFERENCES u (c) ON DELETE CASCADE",
+			"RangeVar: u",
+			"Constraint: UNIQUE (a, b)",
+			"DefElem: fillfactor = 70",
+		},
+	},
+}
+
+func TestSpans(t *testing.T) {
+	for _, tc := range spanTests {
+		t.Run(tc.name, func(t *testing.T) {
+			tree, err := pg_query.Parse(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var spans []string
+			err = analysis.WalkSpans(tc.input, tree, func(msg proto.Message, span analysis.Span) bool {
+				if _, ok := msg.(*pganalyze.Node); !ok {
+					spans = append(spans, fmt.Sprintf("%s: %s", msg.ProtoReflect().Descriptor().Name(), tc.input[span.Start:span.End]))
+				}
+				return true
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.spans, spans); diff != "" {
+				t.Errorf("spans mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSpansWrappers(t *testing.T) {
+	input := "SELECT a + 1 FROM t"
+	tree, err := pg_query.Parse(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spans, err := analysis.Spans(input, tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := tree.GetStmts()[0].GetStmt().GetSelectStmt().GetTargetList()[0].GetResTarget().GetVal()
+	want := analysis.Span{Start: 7, End: 12}
+	if got := spans[target]; got != want {
+		t.Errorf("wrapper span = %v, want %v", got, want)
+	}
+	if got := spans[target.GetAExpr()]; got != want {
+		t.Errorf("node span = %v, want %v", got, want)
+	}
+}
+
+func TestSpansScanError(t *testing.T) {
+	tree, err := pg_query.Parse("SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := analysis.Spans("SELECT 'unterminated", tree); err == nil || !strings.HasPrefix(err.Error(), "analysis: scanning input: ") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
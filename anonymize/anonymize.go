@@ -0,0 +1,307 @@
+// Package anonymize replaces the identifiers of SQL statements with stable
+// pseudonyms, to share query workloads without revealing the schema.
+//
+// Pseudonyms are derived from identifiers with HMAC-SHA256 and a secret key,
+// so the same identifier always gets the same pseudonym for a key but cannot
+// be recovered without it. Constants are normalized to parameter references.
+// Queries have the same fingerprint after anonymization exactly if they had
+// the same fingerprint before, so workloads can still be grouped:
+//
+//	a := anonymize.New([]byte("secret"))
+//	sql, _ := a.Anonymize("SELECT name FROM users u WHERE u.id = 42")
+//	// SELECT x_zdmvmxaxiw FROM x_uidpiuyxrh x_jexabbnhvk WHERE x_jexabbnhvk.x_srcscbwurq = $1
+//
+// The mapping of pseudonyms back to identifiers can be saved to restore
+// anonymized statements internally.
+package anonymize
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"strconv"
+	"strings"
+	"sync"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+	"google.golang.org/protobuf/proto"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+	"github.com/wasilibs/go-pgquery/internal/walk"
+)
+
+// Anonymizer replaces identifiers with pseudonyms derived from a key. It is
+// safe for concurrent use.
+type Anonymizer struct {
+	key  []byte
+	keep map[string]bool
+
+	mu      sync.Mutex
+	mapping Mapping
+}
+
+// New returns an Anonymizer deriving pseudonyms with key. Identifiers in keep
+// are not replaced, in addition to built-in functions, system schemas and
+// columns, and the public schema.
+func New(key []byte, keep ...string) *Anonymizer {
+	a := &Anonymizer{key: key, keep: map[string]bool{}, mapping: Mapping{}}
+	for _, name := range keep {
+		a.keep[name] = true
+	}
+	return a
+}
+
+// Anonymize parses sql, replaces the names of relations, columns, schemas,
+// aliases and functions other than built-in ones with pseudonyms, deparses
+// the result and replaces its constants with parameter references.
+func (a *Anonymizer) Anonymize(sql string) (string, error) {
+	tree, err := pg_query.Parse(sql)
+	if err != nil {
+		return "", fmt.Errorf("anonymize: parsing input: %w", err)
+	}
+	out, err := pg_query.Deparse(a.AnonymizeTree(tree))
+	if err != nil {
+		return "", fmt.Errorf("anonymize: deparsing tree: %w", err)
+	}
+	out, err = pg_query.Normalize(out)
+	if err != nil {
+		return "", fmt.Errorf("anonymize: normalizing constants: %w", err)
+	}
+	return out, nil
+}
+
+// AnonymizeTree returns a copy of tree with identifiers replaced like
+// Anonymize, keeping constants.
+func (a *Anonymizer) AnonymizeTree(tree *pganalyze.ParseResult) *pganalyze.ParseResult {
+	tree = proto.CloneOf(tree)
+	rename(tree, a.pseudonym, a.keepFunction)
+	return tree
+}
+
+// Mapping returns a copy of the pseudonyms assigned so far.
+func (a *Anonymizer) Mapping() Mapping {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return maps.Clone(a.mapping)
+}
+
+// pseudonym returns the pseudonym of name, recording it in the mapping.
+func (a *Anonymizer) pseudonym(name string) string {
+	if name == "" || a.keep[name] || keepNames[name] || strings.HasPrefix(name, "pg_") {
+		return name
+	}
+	// Numbers in table names, such as those of partitions, are ignored by
+	// fingerprints. The pseudonym is derived from the name without numbers,
+	// with digits derived from the full name appended if it had numbers, so
+	// that it is still unique but has the same fingerprint.
+	p := "x_" + letters(a.mac("n", stripNumbers(name)), 10)
+	if stripNumbers(name) != name {
+		p += strconv.FormatUint(binary.BigEndian.Uint64(a.mac("d", name))%1e8+1e8, 10)[1:]
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.mapping[p] = name
+	return p
+}
+
+func (a *Anonymizer) mac(domain, name string) []byte {
+	h := hmac.New(sha256.New, a.key)
+	h.Write([]byte(domain))
+	h.Write([]byte{0})
+	h.Write([]byte(name))
+	return h.Sum(nil)
+}
+
+// keepFunction returns whether the function with the given possibly qualified
+// name is built-in.
+func (a *Anonymizer) keepFunction(names []string) bool {
+	if len(names) > 1 {
+		return systemSchemas[names[0]]
+	}
+	return builtinFunctions[names[0]] || a.keep[names[0]] || strings.HasPrefix(names[0], "pg_")
+}
+
+// letters returns n lowercase letters encoding b.
+func letters(b []byte, n int) string {
+	var s strings.Builder
+	for i := range n {
+		s.WriteByte('a' + b[i]%26)
+	}
+	return s.String()
+}
+
+// stripNumbers removes digits adjacent to other digits from s, as
+// fingerprints do for table names.
+func stripNumbers(s string) string {
+	isDigit := func(i int) bool { return i >= 0 && i < len(s) && s[i] >= '0' && s[i] <= '9' }
+	var b strings.Builder
+	for i := range len(s) {
+		if isDigit(i) && (isDigit(i+1) || isDigit(i-1)) {
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// Mapping maps pseudonyms to the identifiers they replace.
+type Mapping map[string]string
+
+// ReadMapping reads a mapping written by Mapping.Write.
+func ReadMapping(r io.Reader) (Mapping, error) {
+	var m Mapping
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, fmt.Errorf("anonymize: reading mapping: %w", err)
+	}
+	return m, nil
+}
+
+// Write writes the mapping to w as a JSON object.
+func (m Mapping) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(m); err != nil {
+		return fmt.Errorf("anonymize: writing mapping: %w", err)
+	}
+	return nil
+}
+
+// Restore parses sql anonymized with the mapping and replaces pseudonyms with
+// the original identifiers. Constants normalized by Anonymize are not
+// restored.
+func (m Mapping) Restore(sql string) (string, error) {
+	tree, err := pg_query.Parse(sql)
+	if err != nil {
+		return "", fmt.Errorf("anonymize: parsing input: %w", err)
+	}
+	rename(tree, func(name string) string {
+		if orig, ok := m[name]; ok {
+			return orig
+		}
+		return name
+	}, func([]string) bool { return false })
+	out, err := pg_query.Deparse(tree)
+	if err != nil {
+		return "", fmt.Errorf("anonymize: deparsing tree: %w", err)
+	}
+	return out, nil
+}
+
+// rename replaces the identifiers of tree using fn, keeping functions for
+// which keepFunction returns true.
+func rename(tree *pganalyze.ParseResult, fn func(string) string, keepFunction func([]string) bool) {
+	strs := func(nodes []*pganalyze.Node) {
+		for _, n := range nodes {
+			if s := n.GetString_(); s != nil {
+				s.Sval = fn(s.GetSval())
+			}
+		}
+	}
+	walk.Walk(tree, func(msg proto.Message) bool {
+		switch msg := msg.(type) {
+		case *pganalyze.RangeVar:
+			if systemSchemas[msg.GetSchemaname()] {
+				return true
+			}
+			msg.Catalogname = fn(msg.GetCatalogname())
+			msg.Schemaname = fn(msg.GetSchemaname())
+			msg.Relname = fn(msg.GetRelname())
+		case *pganalyze.Alias:
+			msg.Aliasname = fn(msg.GetAliasname())
+			strs(msg.GetColnames())
+		case *pganalyze.ColumnRef:
+			fields := msg.GetFields()
+			if len(fields) > 1 && systemSchemas[fields[0].GetString_().GetSval()] {
+				return true
+			}
+			for i, f := range fields {
+				s := f.GetString_()
+				if s == nil {
+					continue
+				}
+				// Pseudo-relations of rules, triggers and ON CONFLICT, and
+				// system columns.
+				if i == 0 && len(fields) > 1 && pseudoRelations[s.GetSval()] || i == len(fields)-1 && systemColumns[s.GetSval()] {
+					continue
+				}
+				s.Sval = fn(s.GetSval())
+			}
+		case *pganalyze.ResTarget:
+			msg.Name = fn(msg.GetName())
+			strs(msg.GetIndirection())
+		case *pganalyze.A_Indirection:
+			strs(msg.GetIndirection())
+		case *pganalyze.FuncCall:
+			if !keepFunction(stringList(msg.GetFuncname())) {
+				strs(msg.GetFuncname())
+			}
+		case *pganalyze.CommonTableExpr:
+			msg.Ctename = fn(msg.GetCtename())
+			strs(msg.GetAliascolnames())
+		case *pganalyze.WindowDef:
+			msg.Name = fn(msg.GetName())
+			msg.Refname = fn(msg.GetRefname())
+		case *pganalyze.ColumnDef:
+			msg.Colname = fn(msg.GetColname())
+		case *pganalyze.Constraint:
+			msg.Conname = fn(msg.GetConname())
+			msg.Indexname = fn(msg.GetIndexname())
+			strs(msg.GetKeys())
+			strs(msg.GetIncluding())
+			strs(msg.GetFkAttrs())
+			strs(msg.GetPkAttrs())
+		case *pganalyze.IndexStmt:
+			msg.Idxname = fn(msg.GetIdxname())
+		case *pganalyze.IndexElem:
+			msg.Name = fn(msg.GetName())
+		case *pganalyze.InferClause:
+			msg.Conname = fn(msg.GetConname())
+		case *pganalyze.JoinExpr:
+			strs(msg.GetUsingClause())
+		case *pganalyze.IntoClause:
+			strs(msg.GetColNames())
+		case *pganalyze.ViewStmt:
+			strs(msg.GetAliases())
+		case *pganalyze.CopyStmt:
+			strs(msg.GetAttlist())
+		case *pganalyze.AlterTableCmd:
+			msg.Name = fn(msg.GetName())
+		case *pganalyze.RenameStmt:
+			msg.Subname = fn(msg.GetSubname())
+			msg.Newname = fn(msg.GetNewname())
+		case *pganalyze.DropStmt:
+			switch {
+			case msg.GetRemoveType() == pganalyze.ObjectType_OBJECT_SCHEMA:
+				strs(msg.GetObjects())
+			case renamedObjects[msg.GetRemoveType()]:
+				for _, o := range msg.GetObjects() {
+					strs(o.GetList().GetItems())
+				}
+			}
+		case *pganalyze.CreateSchemaStmt:
+			msg.Schemaname = fn(msg.GetSchemaname())
+		case *pganalyze.ObjectWithArgs:
+			if !keepFunction(stringList(msg.GetObjname())) {
+				strs(msg.GetObjname())
+			}
+		case *pganalyze.CreateFunctionStmt:
+			strs(msg.GetFuncname())
+		case *pganalyze.FunctionParameter:
+			msg.Name = fn(msg.GetName())
+		}
+		return true
+	})
+}
+
+func stringList(nodes []*pganalyze.Node) []string {
+	names := make([]string, len(nodes))
+	for i, n := range nodes {
+		names[i] = n.GetString_().GetSval()
+	}
+	return names
+}
@@ -0,0 +1,147 @@
+package anonymize_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+	"github.com/wasilibs/go-pgquery/anonymize"
+)
+
+func TestAnonymize(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{
+			name: "select",
+			sql:  "SELECT name FROM users u WHERE u.id = 42",
+			want: "SELECT x_zdmvmxaxiw FROM x_uidpiuyxrh x_jexabbnhvk WHERE x_jexabbnhvk.x_srcscbwurq = $1",
+		},
+		{
+			name: "kept names",
+			sql:  "SELECT count(*), lower(email), my_func(x), my_ext_func(y) FROM public.events_2023 e JOIN s.t USING (id) WHERE e.ctid = '(0,1)' GROUP BY 2 ORDER BY 1",
+			want: "SELECT count(*), lower(x_xhuggqrptu), x_vjwtznaikg(x_prfwzhwfnn), my_ext_func(x_mofbfdqaaj) FROM public.x_xjudptkaez95221187 x_klqcsskqty JOIN x_rvfomksnks.x_kzfqanahch USING (x_srcscbwurq) WHERE x_klqcsskqty.ctid = $1 GROUP BY 2 ORDER BY 1",
+		},
+		{
+			name: "system relations",
+			sql:  "SELECT * FROM pg_catalog.pg_class c JOIN pg_stat_activity a ON true",
+			want: "SELECT * FROM pg_catalog.pg_class x_culxrngunx JOIN pg_stat_activity x_dhytlnufvh ON $1",
+		},
+		{
+			name: "insert",
+			sql:  "INSERT INTO t (a, b) VALUES (1, 'x') ON CONFLICT (a) DO UPDATE SET b = excluded.b RETURNING *",
+			want: "INSERT INTO x_kzfqanahch (x_dhytlnufvh, x_oixwxlftdl) VALUES ($1, $2) ON CONFLICT (x_dhytlnufvh) DO UPDATE SET x_oixwxlftdl = excluded.x_oixwxlftdl RETURNING *",
+		},
+		{
+			name: "cte and window",
+			sql:  "WITH c AS (SELECT 1 AS v) SELECT c.v, row_number() OVER w FROM c WINDOW w AS (ORDER BY v)",
+			want: "WITH x_culxrngunx AS (SELECT $1 AS x_wbmbhdylnz) SELECT x_culxrngunx.x_wbmbhdylnz, row_number() OVER x_qytmwejvpy FROM x_culxrngunx WINDOW x_qytmwejvpy AS (ORDER BY x_wbmbhdylnz)",
+		},
+		{
+			name: "ddl",
+			sql:  "CREATE TABLE s.t (id int PRIMARY KEY, email text, CONSTRAINT c UNIQUE (email)); CREATE INDEX i ON s.t (lower(email)); DROP TABLE s.t",
+			want: "CREATE TABLE x_rvfomksnks.x_kzfqanahch (x_srcscbwurq int PRIMARY KEY, x_xhuggqrptu text, CONSTRAINT x_culxrngunx UNIQUE (x_xhuggqrptu)); CREATE INDEX x_unidzyldof ON x_rvfomksnks.x_kzfqanahch USING btree (lower(x_xhuggqrptu)); DROP TABLE x_rvfomksnks.x_kzfqanahch",
+		},
+	}
+
+	a := anonymize.New([]byte("secret"), "my_ext_func")
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := a.Anonymize(tc.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("expected %s\nactual %s", tc.want, got)
+			}
+		})
+	}
+
+	other, err := anonymize.New([]byte("other")).Anonymize(tests[0].sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == tests[0].want {
+		t.Errorf("expected different pseudonyms for a different key, actual %s", other)
+	}
+}
+
+func TestFingerprintPreserved(t *testing.T) {
+	queries := []string{
+		"SELECT a FROM t WHERE b = 1",
+		"SELECT a AS x FROM t AS y WHERE b = 2",
+		"SELECT a FROM t WHERE b IN (1, 2, 3)",
+		"SELECT a FROM t WHERE b = 1 AND c = 2",
+		"SELECT b FROM t WHERE b = 1",
+		"SELECT a FROM u WHERE b = 1",
+		"SELECT * FROM events_2023",
+		"SELECT * FROM events_2024",
+		"SELECT * FROM events",
+		"SELECT * FROM events1",
+		"SELECT * FROM events2",
+		"SELECT events_2023.id FROM events_2023",
+		"SELECT events_2024.id FROM events_2024",
+		"SELECT lower(a) FROM t",
+		"SELECT upper(a) FROM t",
+		"SELECT f(a) FROM t",
+		"SELECT g(a) FROM t",
+	}
+
+	a := anonymize.New([]byte("secret"))
+	before := make([]string, len(queries))
+	after := make([]string, len(queries))
+	for i, q := range queries {
+		var err error
+		if before[i], err = pg_query.Fingerprint(q); err != nil {
+			t.Fatal(err)
+		}
+		anonymized, err := a.Anonymize(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if after[i], err = pg_query.Fingerprint(anonymized); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range queries {
+		for j := range i {
+			if (before[i] == before[j]) != (after[i] == after[j]) {
+				t.Errorf("%q and %q: same fingerprint before %v, after %v", queries[i], queries[j], before[i] == before[j], after[i] == after[j])
+			}
+		}
+	}
+}
+
+func TestMapping(t *testing.T) {
+	a := anonymize.New([]byte("secret"))
+	sql := "SELECT u.name, count(*) FROM app.users u JOIN orders o ON o.user_id = u.id WHERE o.total > 100 GROUP BY u.name"
+	anonymized, err := a.Anonymize(sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := a.Mapping().Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	m, err := anonymize.ReadMapping(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(a.Mapping(), m); diff != "" {
+		t.Error(diff)
+	}
+
+	restored, err := m.Restore(anonymized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "SELECT u.name, count(*) FROM app.users u JOIN orders o ON o.user_id = u.id WHERE o.total > $1 GROUP BY u.name"
+	if restored != want {
+		t.Errorf("expected %s\nactual %s", want, restored)
+	}
+}
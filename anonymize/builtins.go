@@ -0,0 +1,323 @@
+package anonymize
+
+import pganalyze "github.com/pganalyze/pg_query_go/v6"
+
+// keepNames are identifiers that are never replaced because they do not
+// reveal anything about a schema.
+var keepNames = map[string]bool{
+	"public":             true,
+	"pg_catalog":         true,
+	"information_schema": true,
+	"pg_temp":            true,
+	"pg_toast":           true,
+}
+
+// systemSchemas are the schemas of built-in objects, whose names are kept.
+var systemSchemas = map[string]bool{
+	"pg_catalog":         true,
+	"information_schema": true,
+}
+
+// pseudoRelations are names referring to rows in rules, triggers, RETURNING
+// and ON CONFLICT DO UPDATE rather than to relations.
+var pseudoRelations = map[string]bool{
+	"excluded": true,
+	"new":      true,
+	"old":      true,
+}
+
+// renamedObjects are the kinds of objects whose names are replaced in DROP
+// statements.
+var renamedObjects = map[pganalyze.ObjectType]bool{
+	pganalyze.ObjectType_OBJECT_TABLE:         true,
+	pganalyze.ObjectType_OBJECT_VIEW:          true,
+	pganalyze.ObjectType_OBJECT_MATVIEW:       true,
+	pganalyze.ObjectType_OBJECT_INDEX:         true,
+	pganalyze.ObjectType_OBJECT_SEQUENCE:      true,
+	pganalyze.ObjectType_OBJECT_FOREIGN_TABLE: true,
+}
+
+// systemColumns are the system columns every table has.
+var systemColumns = map[string]bool{
+	"ctid":     true,
+	"xmin":     true,
+	"xmax":     true,
+	"cmin":     true,
+	"cmax":     true,
+	"tableoid": true,
+	"oid":      true,
+}
+
+// builtinFunctions are commonly used built-in functions, which are kept when
+// called without schema. Functions of the pg_catalog schema and functions
+// whose name starts with pg_ are kept as well.
+var builtinFunctions = map[string]bool{
+	// Aggregates.
+	"array_agg":           true,
+	"avg":                 true,
+	"bit_and":             true,
+	"bit_or":              true,
+	"bit_xor":             true,
+	"bool_and":            true,
+	"bool_or":             true,
+	"corr":                true,
+	"count":               true,
+	"covar_pop":           true,
+	"covar_samp":          true,
+	"every":               true,
+	"json_agg":            true,
+	"json_object_agg":     true,
+	"jsonb_agg":           true,
+	"jsonb_object_agg":    true,
+	"max":                 true,
+	"min":                 true,
+	"mode":                true,
+	"percentile_cont":     true,
+	"percentile_disc":     true,
+	"range_agg":           true,
+	"regr_avgx":           true,
+	"regr_avgy":           true,
+	"regr_count":          true,
+	"regr_intercept":      true,
+	"regr_r2":             true,
+	"regr_slope":          true,
+	"stddev":              true,
+	"stddev_pop":          true,
+	"stddev_samp":         true,
+	"string_agg":          true,
+	"sum":                 true,
+	"var_pop":             true,
+	"var_samp":            true,
+	"variance":            true,
+	"xmlagg":              true,
+	"any_value":           true,
+	"cume_dist":           true,
+	"dense_rank":          true,
+	"first_value":         true,
+	"lag":                 true,
+	"last_value":          true,
+	"lead":                true,
+	"nth_value":           true,
+	"ntile":               true,
+	"percent_rank":        true,
+	"rank":                true,
+	"row_number":          true,
+	"array_append":        true,
+	"array_cat":           true,
+	"array_dims":          true,
+	"array_fill":          true,
+	"array_length":        true,
+	"array_lower":         true,
+	"array_ndims":         true,
+	"array_position":      true,
+	"array_positions":     true,
+	"array_prepend":       true,
+	"array_remove":        true,
+	"array_replace":       true,
+	"array_to_string":     true,
+	"array_upper":         true,
+	"cardinality":         true,
+	"generate_series":     true,
+	"generate_subscripts": true,
+	"string_to_array":     true,
+	"unnest":              true,
+	// Strings.
+	"ascii":                 true,
+	"bit_length":            true,
+	"btrim":                 true,
+	"char_length":           true,
+	"character_length":      true,
+	"chr":                   true,
+	"concat":                true,
+	"concat_ws":             true,
+	"convert_from":          true,
+	"convert_to":            true,
+	"decode":                true,
+	"encode":                true,
+	"format":                true,
+	"initcap":               true,
+	"left":                  true,
+	"length":                true,
+	"lower":                 true,
+	"lpad":                  true,
+	"ltrim":                 true,
+	"md5":                   true,
+	"octet_length":          true,
+	"overlay":               true,
+	"position":              true,
+	"quote_ident":           true,
+	"quote_literal":         true,
+	"quote_nullable":        true,
+	"regexp_count":          true,
+	"regexp_instr":          true,
+	"regexp_like":           true,
+	"regexp_match":          true,
+	"regexp_matches":        true,
+	"regexp_replace":        true,
+	"regexp_split_to_array": true,
+	"regexp_split_to_table": true,
+	"regexp_substr":         true,
+	"repeat":                true,
+	"replace":               true,
+	"reverse":               true,
+	"right":                 true,
+	"rpad":                  true,
+	"rtrim":                 true,
+	"sha224":                true,
+	"sha256":                true,
+	"sha384":                true,
+	"sha512":                true,
+	"split_part":            true,
+	"starts_with":           true,
+	"strpos":                true,
+	"substr":                true,
+	"substring":             true,
+	"to_ascii":              true,
+	"to_hex":                true,
+	"translate":             true,
+	"trim":                  true,
+	"unistr":                true,
+	"upper":                 true,
+	"to_char":               true,
+	"to_date":               true,
+	"to_number":             true,
+	"to_timestamp":          true,
+	// Numbers.
+	"abs":          true,
+	"cbrt":         true,
+	"ceil":         true,
+	"ceiling":      true,
+	"degrees":      true,
+	"div":          true,
+	"exp":          true,
+	"factorial":    true,
+	"floor":        true,
+	"gcd":          true,
+	"lcm":          true,
+	"ln":           true,
+	"log":          true,
+	"log10":        true,
+	"mod":          true,
+	"pi":           true,
+	"power":        true,
+	"radians":      true,
+	"random":       true,
+	"round":        true,
+	"scale":        true,
+	"setseed":      true,
+	"sign":         true,
+	"sqrt":         true,
+	"trunc":        true,
+	"width_bucket": true,
+	"acos":         true,
+	"asin":         true,
+	"atan":         true,
+	"atan2":        true,
+	"cos":          true,
+	"cot":          true,
+	"sin":          true,
+	"tan":          true,
+	// Dates and times.
+	"age":                   true,
+	"clock_timestamp":       true,
+	"date_add":              true,
+	"date_bin":              true,
+	"date_part":             true,
+	"date_subtract":         true,
+	"date_trunc":            true,
+	"extract":               true,
+	"isfinite":              true,
+	"justify_days":          true,
+	"justify_hours":         true,
+	"justify_interval":      true,
+	"make_date":             true,
+	"make_interval":         true,
+	"make_time":             true,
+	"make_timestamp":        true,
+	"make_timestamptz":      true,
+	"now":                   true,
+	"statement_timestamp":   true,
+	"timeofday":             true,
+	"timezone":              true,
+	"transaction_timestamp": true,
+	// JSON.
+	"array_to_json":             true,
+	"json_array_elements":       true,
+	"json_array_elements_text":  true,
+	"json_array_length":         true,
+	"json_build_array":          true,
+	"json_build_object":         true,
+	"json_each":                 true,
+	"json_each_text":            true,
+	"json_extract_path":         true,
+	"json_extract_path_text":    true,
+	"json_object":               true,
+	"json_object_keys":          true,
+	"json_populate_record":      true,
+	"json_populate_recordset":   true,
+	"json_strip_nulls":          true,
+	"json_to_record":            true,
+	"json_to_recordset":         true,
+	"json_typeof":               true,
+	"jsonb_array_elements":      true,
+	"jsonb_array_elements_text": true,
+	"jsonb_array_length":        true,
+	"jsonb_build_array":         true,
+	"jsonb_build_object":        true,
+	"jsonb_each":                true,
+	"jsonb_each_text":           true,
+	"jsonb_extract_path":        true,
+	"jsonb_extract_path_text":   true,
+	"jsonb_insert":              true,
+	"jsonb_object":              true,
+	"jsonb_object_keys":         true,
+	"jsonb_path_exists":         true,
+	"jsonb_path_match":          true,
+	"jsonb_path_query":          true,
+	"jsonb_path_query_array":    true,
+	"jsonb_path_query_first":    true,
+	"jsonb_populate_record":     true,
+	"jsonb_populate_recordset":  true,
+	"jsonb_pretty":              true,
+	"jsonb_set":                 true,
+	"jsonb_set_lax":             true,
+	"jsonb_strip_nulls":         true,
+	"jsonb_to_record":           true,
+	"jsonb_to_recordset":        true,
+	"jsonb_typeof":              true,
+	"row_to_json":               true,
+	"to_json":                   true,
+	"to_jsonb":                  true,
+	// Text search.
+	"phraseto_tsquery":     true,
+	"plainto_tsquery":      true,
+	"setweight":            true,
+	"to_tsquery":           true,
+	"to_tsvector":          true,
+	"ts_headline":          true,
+	"ts_rank":              true,
+	"ts_rank_cd":           true,
+	"websearch_to_tsquery": true,
+	// Other.
+	"coalesce":            true,
+	"currval":             true,
+	"current_database":    true,
+	"current_schema":      true,
+	"current_schemas":     true,
+	"current_setting":     true,
+	"current_user":        true,
+	"gen_random_uuid":     true,
+	"greatest":            true,
+	"has_table_privilege": true,
+	"inet_client_addr":    true,
+	"lastval":             true,
+	"least":               true,
+	"nextval":             true,
+	"nullif":              true,
+	"num_nonnulls":        true,
+	"num_nulls":           true,
+	"set_config":          true,
+	"setval":              true,
+	"txid_current":        true,
+	"version":             true,
+}
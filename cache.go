@@ -0,0 +1,142 @@
+package pg_query //nolint:revive // Keep package name aligned with existing public API.
+
+import (
+	"hash/maphash"
+	"sync/atomic"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/wasilibs/go-pgquery/internal/lru"
+)
+
+// Cache memoizes the results of Parse, Scan, Normalize, Fingerprint and
+// FingerprintToUInt64 by input, for workloads processing the same SQL
+// repeatedly. Errors are cached as well. A Cache is safe for concurrent use.
+type Cache struct {
+	seed    maphash.Seed
+	entries *lru.Cache[cacheKey, cacheEntry]
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+type cacheOp uint8
+
+const (
+	cacheParse cacheOp = iota
+	cacheScan
+	cacheNormalize
+	cacheFingerprint
+	cacheFingerprintUInt64
+)
+
+// cacheKey identifies a cached result by operation and input hash. Entries
+// also hold the input to detect hash collisions.
+type cacheKey struct {
+	op   cacheOp
+	hash uint64
+}
+
+type cacheEntry struct {
+	input string
+	tree  *pganalyze.ParseResult
+	scan  *pganalyze.ScanResult
+	str   string
+	u64   uint64
+	err   error
+}
+
+// CacheStats are the lookup counts of a Cache.
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// HitRatio returns the fraction of lookups that were hits, or 0 if there were
+// none.
+func (s CacheStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// NewCache returns a cache holding at most size results, evicting the least
+// recently used ones. Results of each operation are cached separately.
+func NewCache(size int) *Cache {
+	return &Cache{seed: maphash.MakeSeed(), entries: lru.New[cacheKey, cacheEntry](size)}
+}
+
+// Parse is like the package-level Parse. The returned tree is a copy of the
+// cached one, so callers may modify it.
+func (c *Cache) Parse(input string) (*pganalyze.ParseResult, error) {
+	e := c.get(cacheParse, input, func(e *cacheEntry) {
+		e.tree, e.err = Parse(input)
+	})
+	if e.err != nil {
+		return nil, e.err
+	}
+	return proto.CloneOf(e.tree), nil
+}
+
+// Scan is like the package-level Scan. The returned result is a copy of the
+// cached one, so callers may modify it.
+func (c *Cache) Scan(input string) (*pganalyze.ScanResult, error) {
+	e := c.get(cacheScan, input, func(e *cacheEntry) {
+		e.scan, e.err = Scan(input)
+	})
+	if e.err != nil {
+		return nil, e.err
+	}
+	return proto.CloneOf(e.scan), nil
+}
+
+// Normalize is like the package-level Normalize.
+func (c *Cache) Normalize(input string) (string, error) {
+	e := c.get(cacheNormalize, input, func(e *cacheEntry) {
+		e.str, e.err = Normalize(input)
+	})
+	return e.str, e.err
+}
+
+// Fingerprint is like the package-level Fingerprint.
+func (c *Cache) Fingerprint(input string) (string, error) {
+	e := c.get(cacheFingerprint, input, func(e *cacheEntry) {
+		e.str, e.err = Fingerprint(input)
+	})
+	return e.str, e.err
+}
+
+// FingerprintToUInt64 is like the package-level FingerprintToUInt64.
+func (c *Cache) FingerprintToUInt64(input string) (uint64, error) {
+	e := c.get(cacheFingerprintUInt64, input, func(e *cacheEntry) {
+		e.u64, e.err = FingerprintToUInt64(input)
+	})
+	return e.u64, e.err
+}
+
+// Stats returns the number of lookups that were hits and misses so far.
+func (c *Cache) Stats() CacheStats {
+	return CacheStats{Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
+
+// Len returns the number of cached results.
+func (c *Cache) Len() int {
+	return c.entries.Len()
+}
+
+// get returns the cached entry for op and input, computing it with compute on
+// a miss.
+func (c *Cache) get(op cacheOp, input string, compute func(e *cacheEntry)) cacheEntry {
+	key := cacheKey{op: op, hash: maphash.String(c.seed, input)}
+	if e, ok := c.entries.Get(key); ok && e.input == input {
+		c.hits.Add(1)
+		return e
+	}
+	c.misses.Add(1)
+	e := cacheEntry{input: input}
+	compute(&e)
+	c.entries.Add(key, e)
+	return e
+}
@@ -0,0 +1,143 @@
+package pg_query_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+	"github.com/wasilibs/go-pgquery/parser"
+)
+
+func TestCache(t *testing.T) {
+	c := pg_query.NewCache(10)
+	const input = "SELECT a FROM t WHERE b = 1"
+
+	for range 3 {
+		fp, err := c.Fingerprint(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := pg_query.Fingerprint(input)
+		if fp != want {
+			t.Errorf("Fingerprint(%s)\nexpected %s\nactual %s", input, want, fp)
+		}
+		normalized, err := c.Normalize(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if normalized != "SELECT a FROM t WHERE b = $1" {
+			t.Errorf("Normalize(%s)\nactual %s", input, normalized)
+		}
+		u64, err := c.FingerprintToUInt64(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wantU64, _ := pg_query.FingerprintToUInt64(input); u64 != wantU64 {
+			t.Errorf("FingerprintToUInt64(%s)\nexpected %d\nactual %d", input, wantU64, u64)
+		}
+	}
+
+	if want := (pg_query.CacheStats{Hits: 6, Misses: 3}); c.Stats() != want {
+		t.Errorf("expected stats %+v, actual %+v", want, c.Stats())
+	}
+	if ratio := c.Stats().HitRatio(); ratio < 0.66 || ratio > 0.67 {
+		t.Errorf("unexpected hit ratio %f", ratio)
+	}
+	if c.Len() != 3 {
+		t.Errorf("expected 3 cached results, actual %d", c.Len())
+	}
+}
+
+func TestCacheParseCopies(t *testing.T) {
+	c := pg_query.NewCache(10)
+	const input = "SELECT a FROM t"
+
+	tree, err := c.Parse(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree.Stmts[0].Stmt = nil
+	tree.Version = 0
+
+	again, err := c.Parse(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := pg_query.Parse(input)
+	if !proto.Equal(want, again) {
+		t.Errorf("cached tree was modified by caller:\nexpected %v\nactual %v", want, again)
+	}
+
+	scan, err := c.Scan(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scan.Tokens = nil
+	if scan, _ = c.Scan(input); len(scan.GetTokens()) != 4 {
+		t.Errorf("cached scan was modified by caller: %v", scan)
+	}
+}
+
+func TestCacheErrors(t *testing.T) {
+	c := pg_query.NewCache(10)
+	for range 2 {
+		_, err := c.Parse("SELECT $")
+		var perr *parser.Error
+		if !errors.As(err, &perr) || perr.Message != "syntax error at or near \"$\"" {
+			t.Errorf("unexpected error %v", err)
+		}
+	}
+	if want := (pg_query.CacheStats{Hits: 1, Misses: 1}); c.Stats() != want {
+		t.Errorf("expected stats %+v, actual %+v", want, c.Stats())
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	c := pg_query.NewCache(2)
+	for _, input := range []string{"SELECT 1", "SELECT 2", "SELECT 3", "SELECT 1"} {
+		if _, err := c.Fingerprint(input); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := (pg_query.CacheStats{Misses: 4}); c.Stats() != want {
+		t.Errorf("expected stats %+v, actual %+v", want, c.Stats())
+	}
+	if c.Len() != 2 {
+		t.Errorf("expected 2 cached results, actual %d", c.Len())
+	}
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	c := pg_query.NewCache(100)
+	inputs := []string{"SELECT 1", "SELECT a FROM t", "UPDATE t SET a = 1"}
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			for _, input := range inputs {
+				want, _ := pg_query.Normalize(input)
+				got, err := c.Normalize(input)
+				if err != nil || got != want {
+					t.Errorf("Normalize(%s)\nexpected %s\nactual %s (%v)", input, want, got, err)
+				}
+			}
+		})
+	}
+	wg.Wait()
+	if stats := c.Stats(); stats.Hits+stats.Misses != 24 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func BenchmarkCacheFingerprint(b *testing.B) {
+	c := pg_query.NewCache(1000)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := c.Fingerprint("SELECT a, b FROM t WHERE c = $1 AND d IN (1, 2, 3)"); err != nil {
+				b.Error(err)
+			}
+		}
+	})
+}
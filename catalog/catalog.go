@@ -0,0 +1,352 @@
+// Package catalog models the objects of a Postgres database schema without a
+// running server. A Catalog is built by applying DDL statements, such as the
+// migrations of an application, and can then validate queries for references
+// to objects that do not exist.
+package catalog
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/wasilibs/go-pgquery/analysis"
+)
+
+var (
+	// ErrUndefinedObject is returned when a statement refers to an object that
+	// does not exist in the catalog.
+	ErrUndefinedObject = errors.New("object does not exist")
+	// ErrDuplicateObject is returned when a statement creates an object that
+	// already exists in the catalog.
+	ErrDuplicateObject = errors.New("object already exists")
+)
+
+// RelationKind is the kind of a relation.
+type RelationKind int
+
+const (
+	// RelationTable is a table, including partitioned and foreign tables.
+	RelationTable RelationKind = iota + 1
+	// RelationView is a view.
+	RelationView
+	// RelationMaterializedView is a materialized view.
+	RelationMaterializedView
+)
+
+// Relation is a table or view.
+type Relation struct {
+	Schema  string
+	Name    string
+	Kind    RelationKind
+	Columns []*Column
+}
+
+// Column returns the column of r with the given name, or nil if there is none.
+func (r *Relation) Column(name string) *Column {
+	for _, c := range r.Columns {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Column is a column of a relation or composite type.
+type Column struct {
+	Name string
+	// Type is the name of the column type as written, without a pg_catalog
+	// qualification, such as "int4" or "varchar[]".
+	Type       string
+	NotNull    bool
+	HasDefault bool
+}
+
+// TypeKind is the kind of a user-defined type.
+type TypeKind int
+
+const (
+	// TypeEnum is an enum type created with CREATE TYPE ... AS ENUM.
+	TypeEnum TypeKind = iota + 1
+	// TypeComposite is a composite type created with CREATE TYPE ... AS.
+	TypeComposite
+	// TypeDomain is a domain created with CREATE DOMAIN.
+	TypeDomain
+	// TypeRange is a range type created with CREATE TYPE ... AS RANGE.
+	TypeRange
+)
+
+// Type is a user-defined type.
+type Type struct {
+	Schema string
+	Name   string
+	Kind   TypeKind
+	// Values are the labels of an enum type.
+	Values []string
+	// Columns are the attributes of a composite type.
+	Columns []*Column
+	// BaseType is the underlying type of a domain.
+	BaseType string
+}
+
+// Function is a user-defined function or procedure.
+type Function struct {
+	Schema     string
+	Name       string
+	Args       []Arg
+	ReturnType string
+	Procedure  bool
+}
+
+// Arg is an argument of a function.
+type Arg struct {
+	Name       string
+	Type       string
+	Variadic   bool
+	HasDefault bool
+	// Out is set for OUT and TABLE arguments, which are not passed in calls.
+	Out bool
+}
+
+// Accepts returns whether f can be called with n arguments.
+func (f *Function) Accepts(n int) bool {
+	minArgs, maxArgs := 0, 0
+	for _, a := range f.Args {
+		switch {
+		case a.Out:
+		case a.Variadic:
+			maxArgs = -1
+		default:
+			if !a.HasDefault {
+				minArgs++
+			}
+			if maxArgs >= 0 {
+				maxArgs++
+			}
+		}
+	}
+	return n >= minArgs && (maxArgs < 0 || n <= maxArgs)
+}
+
+// inputTypes returns the types of the arguments passed in calls, which
+// identify a function among its overloads.
+func (f *Function) inputTypes() []string {
+	var types []string
+	for _, a := range f.Args {
+		if !a.Out {
+			types = append(types, a.Type)
+		}
+	}
+	return types
+}
+
+// Schema is a namespace of objects.
+type Schema struct {
+	Name      string
+	Relations map[string]*Relation
+	Types     map[string]*Type
+	Functions map[string][]*Function
+}
+
+func newSchema(name string) *Schema {
+	return &Schema{
+		Name:      name,
+		Relations: map[string]*Relation{},
+		Types:     map[string]*Type{},
+		Functions: map[string][]*Function{},
+	}
+}
+
+// Catalog is a collection of schemas and the objects they contain. The zero
+// value is not usable, use New to create a Catalog.
+type Catalog struct {
+	schemas    map[string]*Schema
+	searchPath []string
+}
+
+// New returns a Catalog containing only the empty public schema, with a search
+// path of public.
+func New() *Catalog {
+	return &Catalog{
+		schemas:    map[string]*Schema{"public": newSchema("public")},
+		searchPath: []string{"public"},
+	}
+}
+
+// Schema returns the schema with the given name, or nil if it does not exist.
+func (c *Catalog) Schema(name string) *Schema {
+	return c.schemas[name]
+}
+
+// SearchPath returns the schemas unqualified names are resolved in.
+func (c *Catalog) SearchPath() []string {
+	return slices.Clone(c.searchPath)
+}
+
+// Relation returns the relation with the given name, resolving an unqualified
+// name using the search path, or nil if it does not exist.
+func (c *Catalog) Relation(name analysis.RelationName) *Relation {
+	for _, s := range c.lookupSchemas(name.Schema) {
+		if r, ok := s.Relations[name.Name]; ok {
+			return r
+		}
+	}
+	return nil
+}
+
+// Type returns the user-defined type with the given name, resolving an
+// unqualified name using the search path, or nil if it does not exist.
+func (c *Catalog) Type(schema, name string) *Type {
+	for _, s := range c.lookupSchemas(schema) {
+		if t, ok := s.Types[name]; ok {
+			return t
+		}
+	}
+	return nil
+}
+
+// Functions returns the overloads of the function with the given name,
+// resolving an unqualified name using the search path.
+func (c *Catalog) Functions(schema, name string) []*Function {
+	for _, s := range c.lookupSchemas(schema) {
+		if fs, ok := s.Functions[name]; ok && len(fs) > 0 {
+			return fs
+		}
+	}
+	return nil
+}
+
+// Columns implements analysis.Schema.
+func (c *Catalog) Columns(name analysis.RelationName) ([]string, bool) {
+	r := c.Relation(name)
+	if r == nil {
+		return nil, false
+	}
+	cols := make([]string, len(r.Columns))
+	for i, col := range r.Columns {
+		cols[i] = col.Name
+	}
+	return cols, true
+}
+
+// Clone returns a deep copy of c.
+func (c *Catalog) Clone() *Catalog {
+	res := &Catalog{
+		schemas:    make(map[string]*Schema, len(c.schemas)),
+		searchPath: slices.Clone(c.searchPath),
+	}
+	for name, s := range c.schemas {
+		cs := newSchema(s.Name)
+		for k, r := range s.Relations {
+			cr := *r
+			cr.Columns = cloneColumns(r.Columns)
+			cs.Relations[k] = &cr
+		}
+		for k, t := range s.Types {
+			ct := *t
+			ct.Values = slices.Clone(t.Values)
+			ct.Columns = cloneColumns(t.Columns)
+			cs.Types[k] = &ct
+		}
+		for k, fs := range s.Functions {
+			cfs := make([]*Function, len(fs))
+			for i, f := range fs {
+				cf := *f
+				cf.Args = slices.Clone(f.Args)
+				cfs[i] = &cf
+			}
+			cs.Functions[k] = cfs
+		}
+		res.schemas[name] = cs
+	}
+	return res
+}
+
+func cloneColumns(cols []*Column) []*Column {
+	if cols == nil {
+		return nil
+	}
+	res := make([]*Column, len(cols))
+	for i, c := range cols {
+		cc := *c
+		res[i] = &cc
+	}
+	return res
+}
+
+// lookupSchemas returns the schemas to search for an object, the named one if
+// schema is set and otherwise the existing schemas of the search path.
+func (c *Catalog) lookupSchemas(schema string) []*Schema {
+	if schema != "" {
+		if s, ok := c.schemas[schema]; ok {
+			return []*Schema{s}
+		}
+		return nil
+	}
+	var res []*Schema
+	for _, name := range c.searchPath {
+		if s, ok := c.schemas[name]; ok {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// creationSchema returns the schema a new object is created in, the named one
+// if schema is set and otherwise the first existing schema of the search path.
+func (c *Catalog) creationSchema(schema string) (*Schema, error) {
+	if schema != "" {
+		if s, ok := c.schemas[schema]; ok {
+			return s, nil
+		}
+		return nil, undefined("schema", schema)
+	}
+	for _, name := range c.searchPath {
+		if s, ok := c.schemas[name]; ok {
+			return s, nil
+		}
+	}
+	return nil, errNoSchemaSelected
+}
+
+var errNoSchemaSelected = errors.New("no schema has been selected to create in")
+
+func qualifiedName(schema, name string) string {
+	if schema == "" {
+		return name
+	}
+	return schema + "." + name
+}
+
+func undefined(kind, name string) error {
+	return &objectError{err: ErrUndefinedObject, kind: kind, name: name}
+}
+
+func duplicate(kind, name string) error {
+	return &objectError{err: ErrDuplicateObject, kind: kind, name: name}
+}
+
+// objectError reports an undefined or duplicate object using the wording of
+// the corresponding Postgres error.
+type objectError struct {
+	err  error
+	kind string
+	name string
+}
+
+func (e *objectError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(e.kind)
+	sb.WriteString(` "`)
+	sb.WriteString(e.name)
+	sb.WriteString(`" `)
+	if errors.Is(e.err, ErrUndefinedObject) {
+		sb.WriteString("does not exist")
+	} else {
+		sb.WriteString("already exists")
+	}
+	return sb.String()
+}
+
+func (e *objectError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,215 @@
+package catalog_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/wasilibs/go-pgquery/analysis"
+	"github.com/wasilibs/go-pgquery/catalog"
+)
+
+const migrations = `
+CREATE TABLE users (id serial PRIMARY KEY, name text NOT NULL, email text);
+CREATE TABLE orders (id bigint PRIMARY KEY, user_id int REFERENCES users (id), total numeric(10, 2));
+ALTER TABLE users ADD COLUMN created_at timestamptz DEFAULT now();
+ALTER TABLE users DROP COLUMN email;
+ALTER TABLE orders RENAME COLUMN total TO amount;
+CREATE TYPE status AS ENUM ('new', 'done');
+ALTER TYPE status ADD VALUE 'failed' BEFORE 'done';
+CREATE SCHEMA billing;
+CREATE TABLE billing.invoices (LIKE orders, paid boolean);
+CREATE VIEW user_totals (uid, total) AS SELECT u.id, sum(o.amount) FROM users u JOIN orders o ON o.user_id = u.id GROUP BY u.id;
+CREATE FUNCTION add_tax(amount numeric, rate numeric DEFAULT 0.2) RETURNS numeric LANGUAGE sql AS 'SELECT amount * (1 + rate)';
+CREATE TABLE tmp (x int);
+DROP TABLE tmp;
+DROP TABLE IF EXISTS missing;
+`
+
+func newCatalog(t *testing.T) *catalog.Catalog {
+	t.Helper()
+	c := catalog.New()
+	if err := c.Exec(migrations); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestExec(t *testing.T) {
+	c := newCatalog(t)
+
+	users := c.Relation(analysis.RelationName{Name: "users"})
+	if users == nil {
+		t.Fatal("users not found")
+	}
+	want := []*catalog.Column{
+		{Name: "id", Type: "serial", NotNull: true, HasDefault: true},
+		{Name: "name", Type: "text", NotNull: true},
+		{Name: "created_at", Type: "timestamptz", HasDefault: true},
+	}
+	if diff := cmp.Diff(want, users.Columns); diff != "" {
+		t.Errorf("users columns mismatch (-want +got):\n%s", diff)
+	}
+
+	tests := []struct {
+		name    analysis.RelationName
+		columns []string
+	}{
+		{analysis.RelationName{Name: "orders"}, []string{"id", "user_id", "amount"}},
+		{analysis.RelationName{Schema: "billing", Name: "invoices"}, []string{"id", "user_id", "amount", "paid"}},
+		{analysis.RelationName{Name: "user_totals"}, []string{"uid", "total"}},
+	}
+	for _, tc := range tests {
+		cols, ok := c.Columns(tc.name)
+		if !ok {
+			t.Errorf("%s not found", tc.name)
+			continue
+		}
+		if diff := cmp.Diff(tc.columns, cols); diff != "" {
+			t.Errorf("%s columns mismatch (-want +got):\n%s", tc.name, diff)
+		}
+	}
+
+	if c.Relation(analysis.RelationName{Name: "tmp"}) != nil {
+		t.Error("dropped table tmp still exists")
+	}
+	if st := c.Type("", "status"); st == nil || !cmp.Equal(st.Values, []string{"new", "failed", "done"}) {
+		t.Errorf("unexpected status type %+v", st)
+	}
+	if fs := c.Functions("", "add_tax"); len(fs) != 1 || !fs[0].Accepts(1) || !fs[0].Accepts(2) || fs[0].Accepts(3) {
+		t.Errorf("unexpected add_tax functions %+v", fs)
+	}
+}
+
+func TestExecErrors(t *testing.T) {
+	tests := []struct {
+		sql string
+		err error
+		msg string
+	}{
+		{"CREATE TABLE users (id int)", catalog.ErrDuplicateObject, `relation "users" already exists`},
+		{"ALTER TABLE nope ADD COLUMN x int", catalog.ErrUndefinedObject, `relation "nope" does not exist`},
+		{"ALTER TABLE users DROP COLUMN email", catalog.ErrUndefinedObject, `column "email" does not exist`},
+		{"CREATE TABLE nope.t (x int)", catalog.ErrUndefinedObject, `schema "nope" does not exist`},
+		{"DROP TYPE mood", catalog.ErrUndefinedObject, `type "mood" does not exist`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.sql, func(t *testing.T) {
+			c := newCatalog(t)
+			err := c.Exec(tc.sql)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+			if want := "catalog: statement 1: " + tc.msg; err.Error() != want {
+				t.Errorf("expected message %q, got %q", want, err.Error())
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		problems []catalog.Problem
+	}{
+		{
+			name: "valid",
+			sql:  "SELECT u.name, o.amount, ctid FROM users u JOIN orders o ON o.user_id = u.id WHERE add_tax(o.amount) > 10",
+		},
+		{
+			name: "unknown relation",
+			sql:  "SELECT * FROM accounts",
+			problems: []catalog.Problem{
+				{Kind: catalog.ProblemUnknownRelation, Message: `relation "accounts" does not exist`, Location: 14},
+			},
+		},
+		{
+			name: "unknown columns",
+			sql:  "SELECT email, u.phone, x.id FROM users u",
+			problems: []catalog.Problem{
+				{Kind: catalog.ProblemUnknownColumn, Message: `column "email" does not exist`, Location: 7},
+				{Kind: catalog.ProblemUnknownColumn, Message: `column "u.phone" does not exist`, Location: 14},
+				{Kind: catalog.ProblemUnknownColumn, Message: `missing FROM-clause entry for table "x"`, Location: 23},
+			},
+		},
+		{
+			name: "column alias list",
+			sql:  "SELECT a, x.a, name FROM users AS x(a)",
+		},
+		{
+			name: "renamed column",
+			sql:  "SELECT x.id, id FROM users AS x(a)",
+			problems: []catalog.Problem{
+				{Kind: catalog.ProblemUnknownColumn, Message: `column "x.id" does not exist`, Location: 7},
+				{Kind: catalog.ProblemUnknownColumn, Message: `column "id" does not exist`, Location: 13},
+			},
+		},
+		{
+			name: "group by output column",
+			sql:  "SELECT count(*) AS n FROM users GROUP BY n ORDER BY n",
+		},
+		{
+			name: "ambiguous column",
+			sql:  "SELECT id FROM users JOIN orders ON orders.user_id = users.id",
+			problems: []catalog.Problem{
+				{Kind: catalog.ProblemAmbiguousColumn, Message: `column reference "id" is ambiguous`, Location: 7},
+			},
+		},
+		{
+			name: "merged join columns",
+			sql:  "SELECT id, user_id FROM users JOIN orders USING (id); SELECT id FROM users NATURAL JOIN orders; SELECT id FROM users JOIN orders USING (id) JOIN billing.invoices USING (id)",
+		},
+		{
+			name: "whole row",
+			sql:  "SELECT users, u FROM users, users AS u; SELECT x FROM users",
+			problems: []catalog.Problem{
+				{Kind: catalog.ProblemUnknownColumn, Message: `column "x" does not exist`, Stmt: 1, Location: 47},
+			},
+		},
+		{
+			name: "lateral sources",
+			sql:  "CREATE VIEW v AS SELECT * FROM users, LATERAL (SELECT users.id AS k) s, xmltable('/r' PASSING name COLUMNS a int) AS x, JSON_TABLE(name::jsonb, '$' COLUMNS (b int, NESTED '$.n' COLUMNS (c int))) AS j; SELECT created_at, k, a, c FROM v",
+		},
+		{
+			name: "wrong arity",
+			sql:  "SELECT add_tax(1, 2, 3)",
+			problems: []catalog.Problem{
+				{Kind: catalog.ProblemWrongArity, Message: "function add_tax does not accept 3 arguments", Location: 7},
+			},
+		},
+		{
+			name: "ddl applied progressively",
+			sql:  "CREATE TABLE notes (body text); SELECT body FROM notes; CREATE TABLE notes (x int)",
+			problems: []catalog.Problem{
+				{Kind: catalog.ProblemInvalidDDL, Message: `relation "notes" already exists`, Stmt: 2, Location: 55},
+			},
+		},
+		{
+			name: "cte and insert",
+			sql:  "WITH recent AS (SELECT id FROM orders) INSERT INTO billing.invoices (id, paid, due) SELECT id, true, now() FROM recent",
+			problems: []catalog.Problem{
+				{Kind: catalog.ProblemUnknownColumn, Message: `column "due" does not exist`, Location: 79},
+			},
+		},
+	}
+
+	c := newCatalog(t)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			problems, err := c.ValidateSQL(tc.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.problems, problems, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("problems mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if c.Relation(analysis.RelationName{Name: "notes"}) != nil {
+		t.Error("Validate modified the catalog")
+	}
+}
@@ -0,0 +1,657 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+	"github.com/wasilibs/go-pgquery/analysis"
+	"github.com/wasilibs/go-pgquery/internal/walk"
+)
+
+// Exec parses sql and applies its statements to the catalog.
+func (c *Catalog) Exec(sql string) error {
+	tree, err := pg_query.Parse(sql)
+	if err != nil {
+		return fmt.Errorf("catalog: parsing statements: %w", err)
+	}
+	return c.Apply(tree)
+}
+
+// Apply applies the statements of tree to the catalog in order, stopping at the
+// first statement that fails. Statements that do not define objects tracked by
+// the catalog, such as queries or CREATE INDEX, are ignored.
+func (c *Catalog) Apply(tree *pganalyze.ParseResult) error {
+	for i, raw := range tree.GetStmts() {
+		if err := c.apply(raw.GetStmt()); err != nil {
+			return fmt.Errorf("catalog: statement %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (c *Catalog) apply(n *pganalyze.Node) error {
+	switch s := walk.Unwrap(n).(type) {
+	case *pganalyze.CreateStmt:
+		return c.createTable(s, RelationTable)
+	case *pganalyze.CreateForeignTableStmt:
+		return c.createTable(s.GetBaseStmt(), RelationTable)
+	case *pganalyze.CreateTableAsStmt:
+		return c.createTableAs(s)
+	case *pganalyze.ViewStmt:
+		return c.createView(s)
+	case *pganalyze.AlterTableStmt:
+		return c.alterTable(s)
+	case *pganalyze.RenameStmt:
+		return c.rename(s)
+	case *pganalyze.AlterObjectSchemaStmt:
+		return c.alterObjectSchema(s)
+	case *pganalyze.DropStmt:
+		return c.drop(s)
+	case *pganalyze.CreateSchemaStmt:
+		return c.createSchema(s)
+	case *pganalyze.CreateEnumStmt:
+		schema, name := splitName(stringList(s.GetTypeName()))
+		return c.addType(&Type{Schema: schema, Name: name, Kind: TypeEnum, Values: stringList(s.GetVals())})
+	case *pganalyze.AlterEnumStmt:
+		return c.alterEnum(s)
+	case *pganalyze.CompositeTypeStmt:
+		t := &Type{
+			Schema:  s.GetTypevar().GetSchemaname(),
+			Name:    s.GetTypevar().GetRelname(),
+			Kind:    TypeComposite,
+			Columns: columnDefs(s.GetColdeflist()),
+		}
+		return c.addType(t)
+	case *pganalyze.CreateDomainStmt:
+		schema, name := splitName(stringList(s.GetDomainname()))
+		return c.addType(&Type{Schema: schema, Name: name, Kind: TypeDomain, BaseType: typeName(s.GetTypeName())})
+	case *pganalyze.CreateRangeStmt:
+		schema, name := splitName(stringList(s.GetTypeName()))
+		return c.addType(&Type{Schema: schema, Name: name, Kind: TypeRange})
+	case *pganalyze.CreateFunctionStmt:
+		return c.createFunction(s)
+	case *pganalyze.VariableSetStmt:
+		c.setSearchPath(s)
+	}
+	return nil
+}
+
+func (c *Catalog) createTable(s *pganalyze.CreateStmt, kind RelationKind) error {
+	rv := s.GetRelation()
+	schema, err := c.creationSchema(rv.GetSchemaname())
+	if err != nil {
+		return err
+	}
+	if _, ok := schema.Relations[rv.GetRelname()]; ok {
+		if s.GetIfNotExists() {
+			return nil
+		}
+		return duplicate("relation", rv.GetRelname())
+	}
+
+	rel := &Relation{Schema: schema.Name, Name: rv.GetRelname(), Kind: kind}
+	for _, n := range s.GetInhRelations() {
+		parent := c.Relation(rangeVarName(n.GetRangeVar()))
+		if parent == nil {
+			return undefined("relation", rangeVarName(n.GetRangeVar()).String())
+		}
+		rel.Columns = append(rel.Columns, cloneColumns(parent.Columns)...)
+	}
+	if tn := s.GetOfTypename(); tn != nil {
+		schemaName, name := splitName(stringList(tn.GetNames()))
+		t := c.Type(schemaName, name)
+		if t == nil || t.Kind != TypeComposite {
+			return undefined("type", typeName(tn))
+		}
+		rel.Columns = append(rel.Columns, cloneColumns(t.Columns)...)
+	}
+
+	for _, n := range s.GetTableElts() {
+		switch elt := walk.Unwrap(n).(type) {
+		case *pganalyze.ColumnDef:
+			col := columnDef(elt)
+			if existing := rel.Column(col.Name); existing != nil {
+				// Columns of a partition or typed table only add constraints.
+				if len(s.GetInhRelations()) > 0 || s.GetOfTypename() != nil {
+					existing.NotNull = existing.NotNull || col.NotNull
+					existing.HasDefault = existing.HasDefault || col.HasDefault
+					continue
+				}
+				return duplicate("column", col.Name)
+			}
+			rel.Columns = append(rel.Columns, col)
+		case *pganalyze.TableLikeClause:
+			src := c.Relation(rangeVarName(elt.GetRelation()))
+			if src == nil {
+				return undefined("relation", rangeVarName(elt.GetRelation()).String())
+			}
+			rel.Columns = append(rel.Columns, cloneColumns(src.Columns)...)
+		case *pganalyze.Constraint:
+			if elt.GetContype() == pganalyze.ConstrType_CONSTR_PRIMARY {
+				for _, key := range stringList(elt.GetKeys()) {
+					if col := rel.Column(key); col != nil {
+						col.NotNull = true
+					}
+				}
+			}
+		}
+	}
+
+	schema.Relations[rel.Name] = rel
+	return nil
+}
+
+func (c *Catalog) createTableAs(s *pganalyze.CreateTableAsStmt) error {
+	kind := RelationTable
+	switch s.GetObjtype() {
+	case pganalyze.ObjectType_OBJECT_TABLE:
+	case pganalyze.ObjectType_OBJECT_MATVIEW:
+		kind = RelationMaterializedView
+	default:
+		return nil
+	}
+	into := s.GetInto()
+	return c.createDerived(into.GetRel(), kind, s.GetQuery(), stringList(into.GetColNames()), s.GetIfNotExists(), false)
+}
+
+func (c *Catalog) createView(s *pganalyze.ViewStmt) error {
+	return c.createDerived(s.GetView(), RelationView, s.GetQuery(), stringList(s.GetAliases()), false, s.GetReplace())
+}
+
+// createDerived creates a relation whose columns are those produced by query.
+func (c *Catalog) createDerived(rv *pganalyze.RangeVar, kind RelationKind, query *pganalyze.Node, aliases []string, ifNotExists, replace bool) error {
+	schema, err := c.creationSchema(rv.GetSchemaname())
+	if err != nil {
+		return err
+	}
+	if _, ok := schema.Relations[rv.GetRelname()]; ok && !replace {
+		if ifNotExists {
+			return nil
+		}
+		return duplicate("relation", rv.GetRelname())
+	}
+
+	names, ok := analysis.OutputColumns(query, c)
+	if !ok {
+		return fmt.Errorf("%w: columns of %q", errUnknownColumns, rv.GetRelname())
+	}
+	copy(names, aliases)
+
+	rel := &Relation{Schema: schema.Name, Name: rv.GetRelname(), Kind: kind}
+	for _, name := range names {
+		rel.Columns = append(rel.Columns, &Column{Name: name})
+	}
+	schema.Relations[rel.Name] = rel
+	return nil
+}
+
+func (c *Catalog) alterTable(s *pganalyze.AlterTableStmt) error {
+	switch s.GetObjtype() {
+	case pganalyze.ObjectType_OBJECT_TABLE, pganalyze.ObjectType_OBJECT_FOREIGN_TABLE,
+		pganalyze.ObjectType_OBJECT_VIEW, pganalyze.ObjectType_OBJECT_MATVIEW:
+	default:
+		return nil
+	}
+
+	name := rangeVarName(s.GetRelation())
+	rel := c.Relation(name)
+	if rel == nil {
+		if s.GetMissingOk() {
+			return nil
+		}
+		return undefined("relation", name.String())
+	}
+
+	for _, n := range s.GetCmds() {
+		cmd := n.GetAlterTableCmd()
+		if cmd == nil {
+			continue
+		}
+		if err := alterTableCmd(rel, cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func alterTableCmd(rel *Relation, cmd *pganalyze.AlterTableCmd) error {
+	if cmd.GetSubtype() == pganalyze.AlterTableType_AT_AddColumn {
+		col := columnDef(cmd.GetDef().GetColumnDef())
+		if rel.Column(col.Name) != nil {
+			if cmd.GetMissingOk() {
+				return nil
+			}
+			return duplicate("column", col.Name)
+		}
+		rel.Columns = append(rel.Columns, col)
+		return nil
+	}
+
+	if !columnCmds[cmd.GetSubtype()] {
+		return nil
+	}
+	col := rel.Column(cmd.GetName())
+	if col == nil {
+		if cmd.GetMissingOk() {
+			return nil
+		}
+		return undefined("column", cmd.GetName())
+	}
+
+	switch cmd.GetSubtype() {
+	case pganalyze.AlterTableType_AT_DropColumn:
+		rel.Columns = slices.DeleteFunc(rel.Columns, func(c *Column) bool { return c == col })
+	case pganalyze.AlterTableType_AT_AlterColumnType:
+		col.Type = typeName(cmd.GetDef().GetColumnDef().GetTypeName())
+	case pganalyze.AlterTableType_AT_SetNotNull:
+		col.NotNull = true
+	case pganalyze.AlterTableType_AT_DropNotNull:
+		col.NotNull = false
+	case pganalyze.AlterTableType_AT_ColumnDefault:
+		col.HasDefault = cmd.GetDef() != nil
+	default:
+	}
+	return nil
+}
+
+// columnCmds are the ALTER TABLE subcommands that refer to an existing column.
+var columnCmds = map[pganalyze.AlterTableType]bool{
+	pganalyze.AlterTableType_AT_ColumnDefault:   true,
+	pganalyze.AlterTableType_AT_DropNotNull:     true,
+	pganalyze.AlterTableType_AT_SetNotNull:      true,
+	pganalyze.AlterTableType_AT_SetStatistics:   true,
+	pganalyze.AlterTableType_AT_SetStorage:      true,
+	pganalyze.AlterTableType_AT_DropColumn:      true,
+	pganalyze.AlterTableType_AT_AlterColumnType: true,
+	pganalyze.AlterTableType_AT_AddIdentity:     true,
+	pganalyze.AlterTableType_AT_DropIdentity:    true,
+}
+
+func (c *Catalog) rename(s *pganalyze.RenameStmt) error {
+	switch s.GetRenameType() {
+	case pganalyze.ObjectType_OBJECT_TABLE, pganalyze.ObjectType_OBJECT_VIEW,
+		pganalyze.ObjectType_OBJECT_MATVIEW, pganalyze.ObjectType_OBJECT_FOREIGN_TABLE:
+		name := rangeVarName(s.GetRelation())
+		rel := c.Relation(name)
+		if rel == nil {
+			if s.GetMissingOk() {
+				return nil
+			}
+			return undefined("relation", name.String())
+		}
+		schema := c.schemas[rel.Schema]
+		if _, ok := schema.Relations[s.GetNewname()]; ok {
+			return duplicate("relation", s.GetNewname())
+		}
+		delete(schema.Relations, rel.Name)
+		rel.Name = s.GetNewname()
+		schema.Relations[rel.Name] = rel
+	case pganalyze.ObjectType_OBJECT_COLUMN:
+		name := rangeVarName(s.GetRelation())
+		rel := c.Relation(name)
+		if rel == nil {
+			if s.GetMissingOk() {
+				return nil
+			}
+			return undefined("relation", name.String())
+		}
+		col := rel.Column(s.GetSubname())
+		if col == nil {
+			return undefined("column", s.GetSubname())
+		}
+		if rel.Column(s.GetNewname()) != nil {
+			return duplicate("column", s.GetNewname())
+		}
+		col.Name = s.GetNewname()
+	case pganalyze.ObjectType_OBJECT_TYPE, pganalyze.ObjectType_OBJECT_DOMAIN:
+		schemaName, name := splitName(stringList(s.GetObject().GetList().GetItems()))
+		t := c.Type(schemaName, name)
+		if t == nil {
+			return undefined("type", qualifiedName(schemaName, name))
+		}
+		schema := c.schemas[t.Schema]
+		delete(schema.Types, t.Name)
+		t.Name = s.GetNewname()
+		schema.Types[t.Name] = t
+	case pganalyze.ObjectType_OBJECT_SCHEMA:
+		schema, ok := c.schemas[s.GetSubname()]
+		if !ok {
+			return undefined("schema", s.GetSubname())
+		}
+		if _, ok := c.schemas[s.GetNewname()]; ok {
+			return duplicate("schema", s.GetNewname())
+		}
+		delete(c.schemas, schema.Name)
+		schema.Name = s.GetNewname()
+		for _, r := range schema.Relations {
+			r.Schema = schema.Name
+		}
+		for _, t := range schema.Types {
+			t.Schema = schema.Name
+		}
+		for _, fs := range schema.Functions {
+			for _, f := range fs {
+				f.Schema = schema.Name
+			}
+		}
+		c.schemas[schema.Name] = schema
+	default:
+	}
+	return nil
+}
+
+func (c *Catalog) alterObjectSchema(s *pganalyze.AlterObjectSchemaStmt) error {
+	switch s.GetObjectType() {
+	case pganalyze.ObjectType_OBJECT_TABLE, pganalyze.ObjectType_OBJECT_VIEW,
+		pganalyze.ObjectType_OBJECT_MATVIEW, pganalyze.ObjectType_OBJECT_FOREIGN_TABLE:
+	default:
+		return nil
+	}
+	name := rangeVarName(s.GetRelation())
+	rel := c.Relation(name)
+	if rel == nil {
+		if s.GetMissingOk() {
+			return nil
+		}
+		return undefined("relation", name.String())
+	}
+	target, ok := c.schemas[s.GetNewschema()]
+	if !ok {
+		return undefined("schema", s.GetNewschema())
+	}
+	if _, ok := target.Relations[rel.Name]; ok {
+		return duplicate("relation", rel.Name)
+	}
+	delete(c.schemas[rel.Schema].Relations, rel.Name)
+	rel.Schema = target.Name
+	target.Relations[rel.Name] = rel
+	return nil
+}
+
+func (c *Catalog) drop(s *pganalyze.DropStmt) error {
+	for _, obj := range s.GetObjects() {
+		if err := c.dropObject(s.GetRemoveType(), obj); err != nil {
+			if s.GetMissingOk() && isUndefined(err) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Catalog) dropObject(kind pganalyze.ObjectType, obj *pganalyze.Node) error {
+	switch kind {
+	case pganalyze.ObjectType_OBJECT_TABLE, pganalyze.ObjectType_OBJECT_VIEW,
+		pganalyze.ObjectType_OBJECT_MATVIEW, pganalyze.ObjectType_OBJECT_FOREIGN_TABLE:
+		schema, name := splitName(stringList(obj.GetList().GetItems()))
+		rel := c.Relation(analysis.RelationName{Schema: schema, Name: name})
+		if rel == nil {
+			return undefined("relation", qualifiedName(schema, name))
+		}
+		delete(c.schemas[rel.Schema].Relations, rel.Name)
+	case pganalyze.ObjectType_OBJECT_TYPE, pganalyze.ObjectType_OBJECT_DOMAIN:
+		schema, name := splitName(stringList(obj.GetTypeName().GetNames()))
+		t := c.Type(schema, name)
+		if t == nil {
+			return undefined("type", qualifiedName(schema, name))
+		}
+		delete(c.schemas[t.Schema].Types, t.Name)
+	case pganalyze.ObjectType_OBJECT_FUNCTION, pganalyze.ObjectType_OBJECT_PROCEDURE, pganalyze.ObjectType_OBJECT_ROUTINE:
+		owa := obj.GetObjectWithArgs()
+		schema, name := splitName(stringList(owa.GetObjname()))
+		fs := c.Functions(schema, name)
+		if len(fs) == 0 {
+			return undefined("function", qualifiedName(schema, name))
+		}
+		idx := 0
+		if !owa.GetArgsUnspecified() {
+			var types []string
+			for _, arg := range owa.GetObjargs() {
+				types = append(types, typeName(arg.GetTypeName()))
+			}
+			idx = slices.IndexFunc(fs, func(f *Function) bool { return slices.Equal(f.inputTypes(), types) })
+			if idx < 0 {
+				return undefined("function", qualifiedName(schema, name)+"("+strings.Join(types, ", ")+")")
+			}
+		} else if len(fs) > 1 {
+			return fmt.Errorf("%w: function name %q is not unique", errAmbiguousFunction, name)
+		}
+		f := fs[idx]
+		c.schemas[f.Schema].Functions[f.Name] = slices.Delete(fs, idx, idx+1)
+	case pganalyze.ObjectType_OBJECT_SCHEMA:
+		name := obj.GetString_().GetSval()
+		if _, ok := c.schemas[name]; !ok {
+			return undefined("schema", name)
+		}
+		delete(c.schemas, name)
+	default:
+	}
+	return nil
+}
+
+func (c *Catalog) createSchema(s *pganalyze.CreateSchemaStmt) error {
+	name := s.GetSchemaname()
+	if name == "" {
+		name = s.GetAuthrole().GetRolename()
+	}
+	if _, ok := c.schemas[name]; ok {
+		if s.GetIfNotExists() {
+			return nil
+		}
+		return duplicate("schema", name)
+	}
+	c.schemas[name] = newSchema(name)
+
+	// Elements of CREATE SCHEMA are created in the new schema.
+	searchPath := c.searchPath
+	c.searchPath = []string{name}
+	defer func() { c.searchPath = searchPath }()
+	for _, elt := range s.GetSchemaElts() {
+		if err := c.apply(elt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Catalog) addType(t *Type) error {
+	schema, err := c.creationSchema(t.Schema)
+	if err != nil {
+		return err
+	}
+	if _, ok := schema.Types[t.Name]; ok {
+		return duplicate("type", t.Name)
+	}
+	t.Schema = schema.Name
+	schema.Types[t.Name] = t
+	return nil
+}
+
+func (c *Catalog) alterEnum(s *pganalyze.AlterEnumStmt) error {
+	schema, name := splitName(stringList(s.GetTypeName()))
+	t := c.Type(schema, name)
+	if t == nil || t.Kind != TypeEnum {
+		return undefined("type", qualifiedName(schema, name))
+	}
+
+	if s.GetOldVal() != "" {
+		idx := slices.Index(t.Values, s.GetOldVal())
+		if idx < 0 {
+			return undefined("enum label", s.GetOldVal())
+		}
+		t.Values[idx] = s.GetNewVal()
+		return nil
+	}
+
+	if slices.Contains(t.Values, s.GetNewVal()) {
+		if s.GetSkipIfNewValExists() {
+			return nil
+		}
+		return duplicate("enum label", s.GetNewVal())
+	}
+	idx := len(t.Values)
+	if neighbor := s.GetNewValNeighbor(); neighbor != "" {
+		idx = slices.Index(t.Values, neighbor)
+		if idx < 0 {
+			return undefined("enum label", neighbor)
+		}
+		if s.GetNewValIsAfter() {
+			idx++
+		}
+	}
+	t.Values = slices.Insert(t.Values, idx, s.GetNewVal())
+	return nil
+}
+
+func (c *Catalog) createFunction(s *pganalyze.CreateFunctionStmt) error {
+	schemaName, name := splitName(stringList(s.GetFuncname()))
+	schema, err := c.creationSchema(schemaName)
+	if err != nil {
+		return err
+	}
+
+	f := &Function{
+		Schema:     schema.Name,
+		Name:       name,
+		ReturnType: typeName(s.GetReturnType()),
+		Procedure:  s.GetIsProcedure(),
+	}
+	for _, n := range s.GetParameters() {
+		p := n.GetFunctionParameter()
+		mode := p.GetMode()
+		f.Args = append(f.Args, Arg{
+			Name:       p.GetName(),
+			Type:       typeName(p.GetArgType()),
+			Variadic:   mode == pganalyze.FunctionParameterMode_FUNC_PARAM_VARIADIC,
+			HasDefault: p.GetDefexpr() != nil,
+			Out:        mode == pganalyze.FunctionParameterMode_FUNC_PARAM_OUT || mode == pganalyze.FunctionParameterMode_FUNC_PARAM_TABLE,
+		})
+	}
+
+	fs := schema.Functions[name]
+	for i, existing := range fs {
+		if slices.Equal(existing.inputTypes(), f.inputTypes()) {
+			if !s.GetReplace() {
+				return duplicate("function", name+"("+strings.Join(f.inputTypes(), ", ")+")")
+			}
+			fs[i] = f
+			return nil
+		}
+	}
+	schema.Functions[name] = append(fs, f)
+	return nil
+}
+
+func (c *Catalog) setSearchPath(s *pganalyze.VariableSetStmt) {
+	if s.GetName() != "search_path" {
+		return
+	}
+	switch s.GetKind() {
+	case pganalyze.VariableSetKind_VAR_SET_VALUE:
+		var path []string
+		for _, arg := range s.GetArgs() {
+			if v := arg.GetAConst().GetSval(); v != nil {
+				for _, p := range strings.Split(v.GetSval(), ",") {
+					path = append(path, strings.Trim(strings.TrimSpace(p), `"`))
+				}
+			}
+		}
+		c.searchPath = path
+	case pganalyze.VariableSetKind_VAR_SET_DEFAULT, pganalyze.VariableSetKind_VAR_RESET:
+		c.searchPath = []string{"public"}
+	default:
+	}
+}
+
+var (
+	errUnknownColumns    = errors.New("cannot determine columns")
+	errAmbiguousFunction = errors.New("ambiguous function")
+)
+
+func isUndefined(err error) bool {
+	return errors.Is(err, ErrUndefinedObject)
+}
+
+func columnDefs(nodes []*pganalyze.Node) []*Column {
+	var cols []*Column
+	for _, n := range nodes {
+		if cd := n.GetColumnDef(); cd != nil {
+			cols = append(cols, columnDef(cd))
+		}
+	}
+	return cols
+}
+
+func columnDef(cd *pganalyze.ColumnDef) *Column {
+	col := &Column{
+		Name:       cd.GetColname(),
+		Type:       typeName(cd.GetTypeName()),
+		NotNull:    cd.GetIsNotNull(),
+		HasDefault: cd.GetRawDefault() != nil || cd.GetIdentity() != "",
+	}
+	switch col.Type {
+	case "serial", "bigserial", "smallserial", "serial2", "serial4", "serial8":
+		col.HasDefault = true
+		col.NotNull = true
+	}
+	for _, n := range cd.GetConstraints() {
+		switch n.GetConstraint().GetContype() {
+		case pganalyze.ConstrType_CONSTR_NOTNULL, pganalyze.ConstrType_CONSTR_PRIMARY:
+			col.NotNull = true
+		case pganalyze.ConstrType_CONSTR_DEFAULT, pganalyze.ConstrType_CONSTR_IDENTITY, pganalyze.ConstrType_CONSTR_GENERATED:
+			col.HasDefault = true
+		default:
+		}
+	}
+	return col
+}
+
+// typeName formats a type name as written, omitting a pg_catalog qualification.
+func typeName(tn *pganalyze.TypeName) string {
+	if tn == nil {
+		return ""
+	}
+	names := stringList(tn.GetNames())
+	if len(names) > 1 && names[0] == "pg_catalog" {
+		names = names[1:]
+	}
+	res := strings.Join(names, ".")
+	if tn.GetSetof() {
+		res = "setof " + res
+	}
+	for range tn.GetArrayBounds() {
+		res += "[]"
+	}
+	return res
+}
+
+func rangeVarName(rv *pganalyze.RangeVar) analysis.RelationName {
+	return analysis.RelationName{Schema: rv.GetSchemaname(), Name: rv.GetRelname()}
+}
+
+// splitName splits a possibly qualified object name into schema and name.
+func splitName(names []string) (string, string) {
+	switch len(names) {
+	case 0:
+		return "", ""
+	case 1:
+		return "", names[0]
+	}
+	return names[len(names)-2], names[len(names)-1]
+}
+
+func stringList(nodes []*pganalyze.Node) []string {
+	var res []string
+	for _, n := range nodes {
+		if s := n.GetString_(); s != nil {
+			res = append(res, s.GetSval())
+		}
+	}
+	return res
+}
@@ -0,0 +1,192 @@
+package catalog
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+	"google.golang.org/protobuf/proto"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+	"github.com/wasilibs/go-pgquery/analysis"
+	"github.com/wasilibs/go-pgquery/internal/walk"
+)
+
+// ProblemKind is the kind of a problem found by validation.
+type ProblemKind int
+
+const (
+	// ProblemUnknownRelation is a reference to a relation that does not exist.
+	ProblemUnknownRelation ProblemKind = iota + 1
+	// ProblemUnknownColumn is a reference to a column that does not exist, or
+	// to a table that is not in the FROM clause.
+	ProblemUnknownColumn
+	// ProblemAmbiguousColumn is an unqualified reference to a column that exists
+	// in more than one relation in scope.
+	ProblemAmbiguousColumn
+	// ProblemWrongArity is a call to a function with a number of arguments none
+	// of its overloads accepts.
+	ProblemWrongArity
+	// ProblemInvalidDDL is a DDL statement that cannot be applied to the
+	// catalog, such as creating a table that already exists.
+	ProblemInvalidDDL
+)
+
+// String returns a short name for the kind.
+func (k ProblemKind) String() string {
+	switch k {
+	case ProblemUnknownRelation:
+		return "unknown relation"
+	case ProblemUnknownColumn:
+		return "unknown column"
+	case ProblemAmbiguousColumn:
+		return "ambiguous column"
+	case ProblemWrongArity:
+		return "wrong arity"
+	case ProblemInvalidDDL:
+		return "invalid DDL"
+	}
+	return "unknown"
+}
+
+// Problem is a problem found by validation.
+type Problem struct {
+	Kind ProblemKind
+	// Message describes the problem using the wording of the corresponding
+	// Postgres error where there is one.
+	Message string
+	// Stmt is the index of the statement in ParseResult.Stmts.
+	Stmt int
+	// Location is the byte offset of the problem in the parsed SQL.
+	Location int32
+}
+
+// String formats the problem with its statement number and offset.
+func (p Problem) String() string {
+	return fmt.Sprintf("statement %d, offset %d: %s", p.Stmt+1, p.Location, p.Message)
+}
+
+// systemColumns are the columns every table has without declaring them.
+var systemColumns = map[string]bool{
+	"tableoid": true,
+	"xmin":     true,
+	"cmin":     true,
+	"xmax":     true,
+	"cmax":     true,
+	"ctid":     true,
+}
+
+// ValidateSQL parses sql and validates it as Validate does.
+func (c *Catalog) ValidateSQL(sql string) ([]Problem, error) {
+	tree, err := pg_query.Parse(sql)
+	if err != nil {
+		return nil, fmt.Errorf("catalog: parsing statements: %w", err)
+	}
+	return c.Validate(tree), nil
+}
+
+// Validate checks the statements of tree against the catalog, returning the
+// problems found in order of statement and location. DDL statements are applied
+// to a copy of the catalog as they are encountered, so later statements may
+// refer to objects created by earlier ones; c itself is not modified.
+//
+// References made by DDL statements themselves are not checked beyond what is
+// needed to apply them. Functions are only checked if they are defined in the
+// catalog, calls to built-in functions are always accepted.
+func (c *Catalog) Validate(tree *pganalyze.ParseResult) []Problem {
+	v := &validator{cat: c.Clone()}
+	for i, raw := range tree.GetStmts() {
+		v.statement(i, raw, tree.GetVersion())
+	}
+	slices.SortStableFunc(v.problems, func(a, b Problem) int {
+		return cmp.Or(cmp.Compare(a.Stmt, b.Stmt), cmp.Compare(a.Location, b.Location))
+	})
+	return v.problems
+}
+
+type validator struct {
+	cat      *Catalog
+	problems []Problem
+}
+
+func (v *validator) statement(idx int, raw *pganalyze.RawStmt, version int32) {
+	single := &pganalyze.ParseResult{Version: version, Stmts: []*pganalyze.RawStmt{raw}}
+	tables, columns := analysis.ExtractReferencesWithSchema(single, v.cat)
+
+	for _, t := range tables {
+		if t.Context == analysis.ContextDDL {
+			continue
+		}
+		if v.cat.Relation(t.Relation) == nil {
+			v.report(ProblemUnknownRelation, idx, t.Location, undefined("relation", t.Relation.String()).Error())
+		}
+	}
+
+	for _, col := range columns {
+		if col.Context == analysis.ContextDDL || col.Name == "*" || col.WholeRow || systemColumns[col.Name] {
+			continue
+		}
+		v.column(idx, col)
+	}
+
+	v.functions(idx, raw.GetStmt())
+
+	if err := v.cat.apply(raw.GetStmt()); err != nil {
+		v.report(ProblemInvalidDDL, idx, raw.GetStmtLocation(), err.Error())
+	}
+}
+
+func (v *validator) column(idx int, col analysis.ColumnRef) {
+	switch {
+	case col.Ambiguous:
+		v.report(ProblemAmbiguousColumn, idx, col.Location, fmt.Sprintf("column reference %q is ambiguous", col.Name))
+	case col.Undefined:
+		v.report(ProblemUnknownColumn, idx, col.Location, columnNotFound(col))
+	case col.Table != nil:
+		// Unknown relations have already been reported.
+		if rel := v.cat.Relation(*col.Table); rel != nil && rel.Column(cmp.Or(col.Column, col.Name)) == nil {
+			v.report(ProblemUnknownColumn, idx, col.Location, columnNotFound(col))
+		}
+	case col.Source != "":
+	case len(col.Qualifier) > 0:
+		v.report(ProblemUnknownColumn, idx, col.Location,
+			fmt.Sprintf("missing FROM-clause entry for table %q", col.Qualifier[len(col.Qualifier)-1]))
+	case len(col.Candidates) == 0 && len(col.CandidateSources) == 0:
+		v.report(ProblemUnknownColumn, idx, col.Location, undefined("column", col.Name).Error())
+	}
+}
+
+func columnNotFound(col analysis.ColumnRef) string {
+	if len(col.Qualifier) > 0 {
+		return undefined("column", col.Qualifier[len(col.Qualifier)-1]+"."+col.Name).Error()
+	}
+	return undefined("column", col.Name).Error()
+}
+
+func (v *validator) functions(idx int, stmt *pganalyze.Node) {
+	walk.Walk(stmt, func(msg proto.Message) bool {
+		fc, ok := msg.(*pganalyze.FuncCall)
+		if !ok {
+			return true
+		}
+		schema, name := splitName(stringList(fc.GetFuncname()))
+		fs := v.cat.Functions(schema, name)
+		if len(fs) == 0 {
+			return true
+		}
+		n := len(fc.GetArgs())
+		for _, f := range fs {
+			if f.Accepts(n) {
+				return true
+			}
+		}
+		v.report(ProblemWrongArity, idx, fc.GetLocation(),
+			fmt.Sprintf("function %s does not accept %d arguments", qualifiedName(schema, name), n))
+		return true
+	})
+}
+
+func (v *validator) report(kind ProblemKind, stmt int, loc int32, msg string) {
+	v.problems = append(v.problems, Problem{Kind: kind, Message: msg, Stmt: stmt, Location: loc})
+}
@@ -0,0 +1,177 @@
+// Command pgfmt formats PostgreSQL SQL files.
+//
+// Usage:
+//
+//	pgfmt [flags] [path ...]
+//
+// Files are rewritten in place, and directories are searched recursively for
+// files with the .sql extension. Without paths, standard input is formatted
+// to standard output. With -check, files are not rewritten; instead a unified
+// diff is written for each file whose formatting differs and the exit status
+// is 1.
+//
+// Formatting preserves comments, and a file is not rewritten if the formatted
+// form of any statement has a different fingerprint from the original.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/wasilibs/go-pgquery/format"
+	"github.com/wasilibs/go-pgquery/internal/diff"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+const usage = `usage: pgfmt [flags] [path ...]
+
+Formats SQL files in place, searching directories for .sql files. Without
+paths, formats standard input to standard output.
+
+Flags:
+`
+
+// Exit statuses.
+const (
+	exitOK      = 0
+	exitDiffers = 1
+	// exitError is used for both invalid input and invalid usage.
+	exitError = 2
+)
+
+type formatter struct {
+	opts   format.Options
+	check  bool
+	stdout io.Writer
+	stderr io.Writer
+	// status is the exit status, the most severe of the files processed.
+	status int
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("pgfmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+	check := flags.Bool("check", false, "write a diff instead of rewriting files, exiting with status 1 if any differ")
+	indent := flags.Int("indent", 2, "number of spaces per indentation level")
+	maxLineLength := flags.Int("max-line-length", 80, "maximum length of lines where possible")
+	commasFirst := flags.Bool("commas-first", false, "place list commas at the start of lines")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitError
+	}
+
+	f := &formatter{
+		opts: format.Options{
+			Indent:        *indent,
+			MaxLineLength: *maxLineLength,
+			CommasFirst:   *commasFirst,
+		},
+		check:  *check,
+		stdout: stdout,
+		stderr: stderr,
+	}
+
+	if flags.NArg() == 0 {
+		f.stdin(stdin)
+		return f.status
+	}
+	for _, path := range flags.Args() {
+		f.path(path)
+	}
+	return f.status
+}
+
+func (f *formatter) fail(name string, err error) {
+	fmt.Fprintf(f.stderr, "%s: %s\n", name, err)
+	f.status = exitError
+}
+
+func (f *formatter) stdin(r io.Reader) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		f.fail("<stdin>", err)
+		return
+	}
+	res, err := format.Format(string(b), f.opts)
+	if err != nil {
+		f.fail("<stdin>", err)
+		return
+	}
+	if !f.check {
+		_, _ = io.WriteString(f.stdout, res)
+		return
+	}
+	if d := diff.Unified("<stdin>", "<stdin>", string(b), res); d != "" {
+		_, _ = io.WriteString(f.stdout, d)
+		f.status = max(f.status, exitDiffers)
+	}
+}
+
+func (f *formatter) path(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		f.fail(path, err)
+		return
+	}
+	if !info.IsDir() {
+		f.file(path, info.Mode())
+		return
+	}
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			f.fail(p, err)
+			return nil
+		}
+		if d.IsDir() || filepath.Ext(p) != ".sql" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			f.fail(p, err)
+			return nil
+		}
+		f.file(p, info.Mode())
+		return nil
+	})
+	if err != nil {
+		f.fail(path, err)
+	}
+}
+
+func (f *formatter) file(path string, mode fs.FileMode) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		f.fail(path, err)
+		return
+	}
+	res, err := format.Format(string(b), f.opts)
+	if err != nil {
+		f.fail(path, err)
+		return
+	}
+	if res == string(b) {
+		return
+	}
+	if f.check {
+		_, _ = io.WriteString(f.stdout, diff.Unified(path, path, string(b), res))
+		f.status = max(f.status, exitDiffers)
+		return
+	}
+	if err := os.WriteFile(path, []byte(res), mode.Perm()); err != nil {
+		f.fail(path, err)
+	}
+}
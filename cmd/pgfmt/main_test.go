@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRunCheck(t *testing.T) {
+	dir := t.TempDir()
+	formatted := filepath.Join(dir, "formatted.sql")
+	unformatted := filepath.Join(dir, "sub", "unformatted.sql")
+	if err := os.MkdirAll(filepath.Dir(unformatted), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(formatted, []byte("SELECT 1;\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(unformatted, []byte("-- keep\nselect a,b from t;\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if status := run([]string{"-check", dir}, nil, &stdout, &stderr); status != 1 {
+		t.Errorf("unexpected status %d, stderr: %s", status, stderr.String())
+	}
+	want := "--- " + unformatted + "\n+++ " + unformatted + "\n@@ -1,2 +1,3 @@\n -- keep\n-select a,b from t;\n+SELECT a, b\n+FROM t;\n"
+	if diff := cmp.Diff(want, stdout.String()); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+
+	// Check mode does not rewrite files.
+	b, err := os.ReadFile(unformatted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "-- keep\nselect a,b from t;\n" {
+		t.Errorf("file rewritten in check mode: %q", b)
+	}
+}
+
+func TestRunRewrite(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "query.sql")
+	if err := os.WriteFile(file, []byte("select a,b from t where x=1"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if status := run([]string{"-commas-first", "-max-line-length", "10", file}, nil, &stdout, &stderr); status != 0 {
+		t.Fatalf("unexpected status %d, stderr: %s", status, stderr.String())
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "SELECT\n    a\n  , b\nFROM t\nWHERE x = 1;\n"
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Errorf("unexpected file contents (-want +got):\n%s", diff)
+	}
+	if stdout.Len() > 0 {
+		t.Errorf("unexpected output: %s", stdout.String())
+	}
+}
+
+func TestRunStdin(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		stdin  string
+		status int
+		stdout string
+	}{
+		{
+			name:   "format",
+			stdin:  "select 1",
+			stdout: "SELECT 1;\n",
+		},
+		{
+			name:   "check formatted",
+			args:   []string{"-check"},
+			stdin:  "SELECT 1;\n",
+			stdout: "",
+		},
+		{
+			name:   "check unformatted",
+			args:   []string{"-check"},
+			stdin:  "select 1",
+			status: 1,
+			stdout: "--- <stdin>\n+++ <stdin>\n@@ -1 +1 @@\n-select 1\n\\ No newline at end of file\n+SELECT 1;\n",
+		},
+		{
+			name:   "invalid",
+			stdin:  "select from where",
+			status: 2,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			status := run(tc.args, bytes.NewBufferString(tc.stdin), &stdout, &stderr)
+			if status != tc.status {
+				t.Errorf("unexpected status %d, stderr: %s", status, stderr.String())
+			}
+			if diff := cmp.Diff(tc.stdout, stdout.String()); diff != "" {
+				t.Errorf("unexpected output (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+)
+
+// document is an open text document. Positions in the protocol count UTF-16
+// code units, while offsets into the text count bytes.
+type document struct {
+	uri     string
+	version int
+	text    string
+	// lineStarts are the offsets of the start of each line.
+	lineStarts []int
+}
+
+func newDocument(uri string, version int, text string) *document {
+	d := &document{uri: uri, version: version, text: text, lineStarts: []int{0}}
+	for i := range len(text) {
+		if text[i] == '\n' {
+			d.lineStarts = append(d.lineStarts, i+1)
+		}
+	}
+	return d
+}
+
+// position returns the position of the byte offset.
+func (d *document) position(offset int) position {
+	offset = min(max(offset, 0), len(d.text))
+	line := sort.SearchInts(d.lineStarts, offset+1) - 1
+	char := 0
+	for _, r := range d.text[d.lineStarts[line]:offset] {
+		char += utf16.RuneLen(r)
+	}
+	return position{Line: line, Character: char}
+}
+
+// offset returns the byte offset of the position, clamped to the document.
+func (d *document) offset(pos position) int {
+	if pos.Line < 0 {
+		return 0
+	}
+	if pos.Line >= len(d.lineStarts) {
+		return len(d.text)
+	}
+	offset := d.lineStarts[pos.Line]
+	for char := 0; char < pos.Character && offset < len(d.text); {
+		r, size := utf8.DecodeRuneInString(d.text[offset:])
+		if r == '\n' {
+			break
+		}
+		char += utf16.RuneLen(r)
+		offset += size
+	}
+	return offset
+}
+
+func (d *document) rangeOf(start, end int) lspRange {
+	return lspRange{Start: d.position(start), End: d.position(end)}
+}
+
+// runeOffset returns the byte offset of the 0-based rune index, such as the
+// cursor position of a parser error less one.
+func (d *document) runeOffset(idx int) int {
+	n := 0
+	for offset := range d.text {
+		if n == idx {
+			return offset
+		}
+		n++
+	}
+	return len(d.text)
+}
+
+// statement is the range of the code of a statement in a document, excluding
+// surrounding whitespace and comments.
+type statement struct {
+	start, end int
+	// kind is the type of the parse tree node of the statement, such as
+	// SelectStmt, or empty if the document does not parse.
+	kind string
+}
+
+// statements returns the statements of the document. Statements are split by
+// the parser if the document parses, which handles semicolons within function
+// bodies, and at semicolons found by the scanner otherwise.
+func (d *document) statements() []statement {
+	scan, err := pg_query.Scan(d.text)
+	if err != nil {
+		return nil
+	}
+	tokens := scan.GetTokens()
+
+	parsed := true
+	chunks, err := pg_query.SplitWithParser(d.text, false)
+	if err != nil {
+		parsed = false
+		if chunks, err = pg_query.SplitWithScanner(d.text, false); err != nil {
+			return nil
+		}
+	}
+
+	var stmts []statement
+	pos := 0
+	for _, c := range chunks {
+		// Without trimming, chunks are consecutive substrings of the text.
+		start := pos + strings.Index(d.text[pos:], c)
+		end := start + len(c)
+		pos = end
+
+		st := statement{start: -1}
+		for _, t := range tokens {
+			tstart, tend := int(t.GetStart()), int(t.GetEnd())
+			if tstart < start || tstart >= end || isComment(t) || t.GetToken() == pganalyze.Token_ASCII_59 {
+				continue
+			}
+			if st.start < 0 {
+				st.start = tstart
+			}
+			st.end = tend
+		}
+		if st.start < 0 {
+			continue
+		}
+		if parsed {
+			if tree, err := pg_query.Parse(c); err == nil && len(tree.GetStmts()) == 1 {
+				st.kind = nodeKind(tree.GetStmts()[0].GetStmt())
+			}
+		}
+		stmts = append(stmts, st)
+	}
+	return stmts
+}
+
+func nodeKind(n *pganalyze.Node) string {
+	m := n.ProtoReflect()
+	fd := m.WhichOneof(m.Descriptor().Oneofs().ByName("node"))
+	if fd == nil {
+		return ""
+	}
+	return string(fd.Message().Name())
+}
+
+func isComment(t *pganalyze.ScanToken) bool {
+	return t.GetToken() == pganalyze.Token_SQL_COMMENT || t.GetToken() == pganalyze.Token_C_COMMENT
+}
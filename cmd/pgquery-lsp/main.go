@@ -0,0 +1,25 @@
+// Command pgquery-lsp is a Language Server Protocol server for PostgreSQL SQL
+// files, communicating over standard input and output.
+//
+// It provides diagnostics for syntax errors, semantic tokens for keywords,
+// literals, operators and comments, document formatting, a document symbol per
+// statement, and hover showing the normalized form and fingerprint of the
+// statement under the cursor. Documents are synchronized in full on every
+// change, and positions are in UTF-16 code units.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	ok, err := serve(os.Stdin, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pgquery-lsp: %v\n", err)
+		os.Exit(1)
+	}
+	if !ok {
+		os.Exit(1)
+	}
+}
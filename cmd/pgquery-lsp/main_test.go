@@ -0,0 +1,278 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// client is an in-process client of a server connected through pipes.
+type client struct {
+	t      *testing.T
+	conn   *conn
+	nextID int
+	// messages are the messages received from the server.
+	messages chan *message
+	// notifications are received notifications not yet consumed.
+	notifications []*message
+	done          chan bool
+}
+
+func newClient(t *testing.T) *client {
+	t.Helper()
+	serverR, clientW := io.Pipe()
+	clientR, serverW := io.Pipe()
+	c := &client{
+		t:        t,
+		conn:     newConn(clientR, clientW),
+		messages: make(chan *message, 16),
+		done:     make(chan bool, 1),
+	}
+	go func() {
+		ok, err := serve(serverR, serverW)
+		if err != nil {
+			t.Errorf("serve: %v", err)
+		}
+		_ = serverW.Close()
+		c.done <- ok
+	}()
+	go func() {
+		defer close(c.messages)
+		for {
+			msg, err := c.conn.read()
+			if err != nil {
+				return
+			}
+			c.messages <- msg
+		}
+	}()
+	t.Cleanup(func() { _ = clientW.Close() })
+	return c
+}
+
+func (c *client) receive() *message {
+	c.t.Helper()
+	select {
+	case msg, ok := <-c.messages:
+		if !ok {
+			c.t.Fatal("connection closed")
+		}
+		return msg
+	case <-time.After(time.Minute):
+		c.t.Fatal("timed out waiting for message")
+	}
+	return nil
+}
+
+// call sends a request and decodes the result of its response into result.
+func (c *client) call(method string, params, result any) *responseError {
+	c.t.Helper()
+	c.nextID++
+	id := json.RawMessage(strconv.Itoa(c.nextID))
+	if err := c.conn.write(map[string]any{"jsonrpc": "2.0", "id": id, "method": method, "params": params}); err != nil {
+		c.t.Fatal(err)
+	}
+	for {
+		msg := c.receive()
+		if msg.Method != "" {
+			c.notifications = append(c.notifications, msg)
+			continue
+		}
+		if string(msg.ID) != string(id) {
+			c.t.Fatalf("unexpected response ID %s", msg.ID)
+		}
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if result != nil {
+			if err := json.Unmarshal(msg.Result, result); err != nil {
+				c.t.Fatal(err)
+			}
+		}
+		return nil
+	}
+}
+
+func (c *client) notify(method string, params any) {
+	c.t.Helper()
+	if err := c.conn.notify(method, params); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// notification returns the parameters of the next notification.
+func (c *client) notification(method string, params any) {
+	c.t.Helper()
+	var msg *message
+	if len(c.notifications) > 0 {
+		msg, c.notifications = c.notifications[0], c.notifications[1:]
+	} else {
+		msg = c.receive()
+	}
+	if msg.Method != method {
+		c.t.Fatalf("unexpected notification %s, want %s", msg.Method, method)
+	}
+	if err := json.Unmarshal(msg.Params, params); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+const uri = "file:///query.sql"
+
+func TestServer(t *testing.T) {
+	c := newClient(t)
+
+	var init initializeResult
+	if err := c.call("initialize", map[string]any{"capabilities": map[string]any{}}, &init); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(tokenTypes, init.Capabilities.SemanticTokensProvider.Legend.TokenTypes); diff != "" {
+		t.Errorf("unexpected token types (-want +got):\n%s", diff)
+	}
+	c.notify("initialized", map[string]any{})
+
+	// An invalid document has a diagnostic at the error.
+	c.notify("textDocument/didOpen", didOpenTextDocumentParams{
+		TextDocument: textDocumentItem{URI: uri, Version: 1, Text: "SELECT 1;\nSELECT * FORM t;\n"},
+	})
+	var diags publishDiagnosticsParams
+	c.notification("textDocument/publishDiagnostics", &diags)
+	wantDiags := publishDiagnosticsParams{
+		URI:     uri,
+		Version: 1,
+		Diagnostics: []diagnostic{{
+			Range:    lspRange{Start: position{Line: 1, Character: 9}, End: position{Line: 1, Character: 13}},
+			Severity: diagnosticSeverityError,
+			Source:   "pgquery",
+			Message:  `syntax error at or near "FORM"`,
+		}},
+	}
+	if diff := cmp.Diff(wantDiags, diags); diff != "" {
+		t.Errorf("unexpected diagnostics (-want +got):\n%s", diff)
+	}
+
+	// Fixing the document clears the diagnostics.
+	text := "-- names\nselect 'é', name from users where id = 1;\nselect 2"
+	c.notify("textDocument/didChange", didChangeTextDocumentParams{
+		TextDocument:   versionedTextDocumentIdentifier{URI: uri, Version: 2},
+		ContentChanges: []textDocumentContentChangeEvent{{Text: text}},
+	})
+	c.notification("textDocument/publishDiagnostics", &diags)
+	if diff := cmp.Diff(publishDiagnosticsParams{URI: uri, Version: 2, Diagnostics: []diagnostic{}}, diags); diff != "" {
+		t.Errorf("unexpected diagnostics (-want +got):\n%s", diff)
+	}
+
+	var symbols []documentSymbol
+	if err := c.call("textDocument/documentSymbol", documentSymbolParams{TextDocument: textDocumentIdentifier{URI: uri}}, &symbols); err != nil {
+		t.Fatal(err)
+	}
+	first := lspRange{Start: position{Line: 1, Character: 0}, End: position{Line: 1, Character: 40}}
+	second := lspRange{Start: position{Line: 2, Character: 0}, End: position{Line: 2, Character: 8}}
+	wantSymbols := []documentSymbol{
+		{Name: "select 'é', name from users where id = 1", Detail: "SelectStmt", Kind: symbolKindObject, Range: first, SelectionRange: first},
+		{Name: "select 2", Detail: "SelectStmt", Kind: symbolKindObject, Range: second, SelectionRange: second},
+	}
+	if diff := cmp.Diff(wantSymbols, symbols); diff != "" {
+		t.Errorf("unexpected symbols (-want +got):\n%s", diff)
+	}
+
+	var h hover
+	if err := c.call("textDocument/hover", textDocumentPositionParams{
+		TextDocument: textDocumentIdentifier{URI: uri},
+		Position:     position{Line: 1, Character: 20},
+	}, &h); err != nil {
+		t.Fatal(err)
+	}
+	wantHover := hover{
+		Contents: markupContent{
+			Kind:  "markdown",
+			Value: "```sql\nselect $1, name from users where id = $2\n```\n\nFingerprint: `c935f09cc9d85190`",
+		},
+		Range: first,
+	}
+	if diff := cmp.Diff(wantHover, h); diff != "" {
+		t.Errorf("unexpected hover (-want +got):\n%s", diff)
+	}
+
+	var tokens semanticTokens
+	if err := c.call("textDocument/semanticTokens/full", semanticTokensParams{TextDocument: textDocumentIdentifier{URI: uri}}, &tokens); err != nil {
+		t.Fatal(err)
+	}
+	wantTokens := []int{
+		0, 0, 8, tokenComment, 0, // -- names
+		1, 0, 6, tokenKeyword, 0, // select
+		0, 7, 3, tokenString, 0, // 'é'
+		0, 5, 4, tokenKeyword, 0, // name
+		0, 5, 4, tokenKeyword, 0, // from
+		0, 11, 5, tokenKeyword, 0, // where
+		0, 9, 1, tokenOperator, 0, // =
+		0, 2, 1, tokenNumber, 0, // 1
+		1, 0, 6, tokenKeyword, 0, // select
+		0, 7, 1, tokenNumber, 0, // 2
+	}
+	if diff := cmp.Diff(wantTokens, tokens.Data); diff != "" {
+		t.Errorf("unexpected semantic tokens (-want +got):\n%s", diff)
+	}
+
+	var edits []textEdit
+	if err := c.call("textDocument/formatting", documentFormattingParams{
+		TextDocument: textDocumentIdentifier{URI: uri},
+		Options:      formattingOptions{TabSize: 4},
+	}, &edits); err != nil {
+		t.Fatal(err)
+	}
+	wantEdits := []textEdit{{
+		Range:   lspRange{Start: position{Line: 0, Character: 0}, End: position{Line: 2, Character: 8}},
+		NewText: "-- names\nSELECT 'é', name\nFROM users\nWHERE id = 1;\nSELECT 2;\n",
+	}}
+	if diff := cmp.Diff(wantEdits, edits); diff != "" {
+		t.Errorf("unexpected edits (-want +got):\n%s", diff)
+	}
+
+	if err := c.call("textDocument/hover", map[string]any{"textDocument": map[string]any{"uri": "file:///other.sql"}}, nil); err == nil || err.Code != codeRequestFailed {
+		t.Errorf("unexpected error for unknown document: %v", err)
+	}
+	if err := c.call("unknown/method", nil, nil); err == nil || err.Code != codeMethodNotFound {
+		t.Errorf("unexpected error for unknown method: %v", err)
+	}
+
+	c.notify("textDocument/didClose", didCloseTextDocumentParams{TextDocument: textDocumentIdentifier{URI: uri}})
+	c.notification("textDocument/publishDiagnostics", &diags)
+	if len(diags.Diagnostics) != 0 {
+		t.Errorf("unexpected diagnostics after close: %v", diags.Diagnostics)
+	}
+
+	if err := c.call("shutdown", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	c.notify("exit", nil)
+	if ok := <-c.done; !ok {
+		t.Error("unsuccessful exit after shutdown")
+	}
+}
+
+func TestDocumentPositions(t *testing.T) {
+	d := newDocument(uri, 1, "a\n😀b\r\nc")
+	tests := []struct {
+		offset int
+		pos    position
+	}{
+		{0, position{Line: 0, Character: 0}},
+		{2, position{Line: 1, Character: 0}},
+		{6, position{Line: 1, Character: 2}},
+		{7, position{Line: 1, Character: 3}},
+		{10, position{Line: 2, Character: 1}},
+	}
+	for _, tc := range tests {
+		if diff := cmp.Diff(tc.pos, d.position(tc.offset)); diff != "" {
+			t.Errorf("unexpected position of %d (-want +got):\n%s", tc.offset, diff)
+		}
+		if got := d.offset(tc.pos); got != tc.offset {
+			t.Errorf("unexpected offset of %v: %d, want %d", tc.pos, got, tc.offset)
+		}
+	}
+}
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf16"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+	"github.com/wasilibs/go-pgquery/format"
+	"github.com/wasilibs/go-pgquery/parser"
+)
+
+// tokenTypes is the legend of semantic token types, indexed by the values of
+// the types in encoded tokens.
+var tokenTypes = []string{"keyword", "string", "number", "comment", "operator", "parameter"}
+
+const (
+	tokenKeyword = iota
+	tokenString
+	tokenNumber
+	tokenComment
+	tokenOperator
+	tokenParameter
+)
+
+type server struct {
+	conn *conn
+	docs map[string]*document
+	// shutdown is whether a shutdown request has been received, after which
+	// only the exit notification is expected.
+	shutdown bool
+}
+
+// serve runs a server communicating over r and w until the exit notification
+// is received or r is closed. It returns whether the exit was preceded by a
+// shutdown request, as required for a successful exit.
+func serve(r io.Reader, w io.Writer) (bool, error) {
+	s := &server{conn: newConn(r, w), docs: map[string]*document{}}
+	for {
+		msg, err := s.conn.read()
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		var rerr *responseError
+		if errors.As(err, &rerr) {
+			if err := s.conn.reply(json.RawMessage("null"), nil, rerr); err != nil {
+				return false, err
+			}
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+
+		switch {
+		case msg.Method == "exit":
+			return s.shutdown, nil
+		case msg.isRequest():
+			res, err := s.request(msg.Method, msg.Params)
+			if err := s.conn.reply(msg.ID, res, err); err != nil {
+				return false, err
+			}
+		case msg.Method != "":
+			if err := s.notification(msg.Method, msg.Params); err != nil {
+				if err := s.conn.notify("window/logMessage", map[string]any{"type": 1, "message": err.Error()}); err != nil {
+					return false, err
+				}
+			}
+		default:
+			// Responses to requests from the server, which it does not send.
+		}
+	}
+}
+
+func (s *server) request(method string, params json.RawMessage) (any, error) {
+	if s.shutdown {
+		return nil, &responseError{Code: codeInvalidRequest, Message: "server is shut down"}
+	}
+	switch method {
+	case "initialize":
+		return s.initialize()
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil //nolint:nilnil // the result of shutdown is null
+	case "textDocument/formatting":
+		return handle(s, params, s.formatting)
+	case "textDocument/documentSymbol":
+		return handle(s, params, s.documentSymbol)
+	case "textDocument/hover":
+		return handle(s, params, s.hover)
+	case "textDocument/semanticTokens/full":
+		return handle(s, params, s.semanticTokens)
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not found: %s", method)}
+}
+
+// documentParams are the parameters of requests for a document.
+type documentParams interface {
+	documentFormattingParams | documentSymbolParams | textDocumentPositionParams | semanticTokensParams
+	uri() string
+}
+
+func (p documentFormattingParams) uri() string   { return p.TextDocument.URI }
+func (p documentSymbolParams) uri() string       { return p.TextDocument.URI }
+func (p textDocumentPositionParams) uri() string { return p.TextDocument.URI }
+func (p semanticTokensParams) uri() string       { return p.TextDocument.URI }
+
+// handle decodes the parameters of a request for a document and calls fn with
+// them and the open document.
+func handle[P documentParams, R any](s *server, raw json.RawMessage, fn func(*document, P) (R, error)) (any, error) {
+	params, err := decode[P](raw)
+	if err != nil {
+		return nil, err
+	}
+	d, ok := s.docs[params.uri()]
+	if !ok {
+		return nil, &responseError{Code: codeRequestFailed, Message: fmt.Sprintf("document not open: %s", params.uri())}
+	}
+	return fn(d, params)
+}
+
+func decode[T any](raw json.RawMessage) (T, error) {
+	var v T
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return v, &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return v, nil
+}
+
+func (s *server) notification(method string, raw json.RawMessage) error {
+	switch method {
+	case "textDocument/didOpen":
+		params, err := decode[didOpenTextDocumentParams](raw)
+		if err != nil {
+			return err
+		}
+		d := newDocument(params.TextDocument.URI, params.TextDocument.Version, params.TextDocument.Text)
+		s.docs[d.uri] = d
+		return s.publishDiagnostics(d, diagnostics(d))
+	case "textDocument/didChange":
+		params, err := decode[didChangeTextDocumentParams](raw)
+		if err != nil {
+			return err
+		}
+		if _, ok := s.docs[params.TextDocument.URI]; !ok || len(params.ContentChanges) == 0 {
+			return nil
+		}
+		// With full synchronization, the last change is the whole document.
+		text := params.ContentChanges[len(params.ContentChanges)-1].Text
+		d := newDocument(params.TextDocument.URI, params.TextDocument.Version, text)
+		s.docs[d.uri] = d
+		return s.publishDiagnostics(d, diagnostics(d))
+	case "textDocument/didClose":
+		params, err := decode[didCloseTextDocumentParams](raw)
+		if err != nil {
+			return err
+		}
+		d, ok := s.docs[params.TextDocument.URI]
+		if !ok {
+			return nil
+		}
+		delete(s.docs, d.uri)
+		return s.publishDiagnostics(d, []diagnostic{})
+	}
+	return nil
+}
+
+func (s *server) initialize() (any, error) {
+	return initializeResult{
+		Capabilities: serverCapabilities{
+			TextDocumentSync:           textDocumentSyncOptions{OpenClose: true, Change: textDocumentSyncKindFull},
+			HoverProvider:              true,
+			DocumentFormattingProvider: true,
+			DocumentSymbolProvider:     true,
+			SemanticTokensProvider: semanticTokensOptions{
+				Legend: semanticTokensLegend{TokenTypes: tokenTypes, TokenModifiers: []string{}},
+				Full:   true,
+			},
+		},
+		ServerInfo: serverInfo{Name: "pgquery-lsp"},
+	}, nil
+}
+
+func (s *server) publishDiagnostics(d *document, diags []diagnostic) error {
+	return s.conn.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         d.uri,
+		Version:     d.version,
+		Diagnostics: diags,
+	})
+}
+
+// diagnostics returns the parse error of the document, if any, spanning the
+// token at its cursor position.
+func diagnostics(d *document) []diagnostic {
+	_, err := pg_query.Parse(d.text)
+	if err == nil {
+		return []diagnostic{}
+	}
+	start := 0
+	var perr *parser.Error
+	if errors.As(err, &perr) && perr.Cursorpos > 0 {
+		start = d.runeOffset(perr.Cursorpos - 1)
+	}
+	end := start
+	if scan, err := pg_query.Scan(d.text); err == nil {
+		for _, t := range scan.GetTokens() {
+			if int(t.GetStart()) <= start && start < int(t.GetEnd()) {
+				start, end = int(t.GetStart()), int(t.GetEnd())
+				break
+			}
+		}
+	}
+	return []diagnostic{{
+		Range:    d.rangeOf(start, end),
+		Severity: diagnosticSeverityError,
+		Source:   "pgquery",
+		Message:  err.Error(),
+	}}
+}
+
+func (s *server) formatting(d *document, params documentFormattingParams) ([]textEdit, error) {
+	formatted, err := format.Format(d.text, format.Options{Indent: params.Options.TabSize})
+	if err != nil {
+		return nil, err //nolint:wrapcheck // reported to the client as is
+	}
+	if formatted == d.text {
+		return []textEdit{}, nil
+	}
+	return []textEdit{{Range: d.rangeOf(0, len(d.text)), NewText: formatted}}, nil
+}
+
+func (s *server) documentSymbol(d *document, _ documentSymbolParams) ([]documentSymbol, error) {
+	symbols := []documentSymbol{}
+	for _, st := range d.statements() {
+		r := d.rangeOf(st.start, st.end)
+		symbols = append(symbols, documentSymbol{
+			Name:           symbolName(d.text[st.start:st.end]),
+			Detail:         st.kind,
+			Kind:           symbolKindObject,
+			Range:          r,
+			SelectionRange: r,
+		})
+	}
+	return symbols, nil
+}
+
+// symbolName returns the start of the statement text with whitespace
+// collapsed, truncated to a reasonable length for display.
+func symbolName(text string) string {
+	const maxLen = 60
+	var name []rune
+	space := false
+	for _, r := range text {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' {
+			space = true
+			continue
+		}
+		if space && len(name) > 0 {
+			name = append(name, ' ')
+		}
+		space = false
+		name = append(name, r)
+		if len(name) > maxLen {
+			return string(name[:maxLen]) + "…"
+		}
+	}
+	return string(name)
+}
+
+// hover shows the normalized form and fingerprint of the statement at the
+// position.
+func (s *server) hover(d *document, params textDocumentPositionParams) (*hover, error) {
+	offset := d.offset(params.Position)
+	for _, st := range d.statements() {
+		if offset < st.start || offset > st.end {
+			continue
+		}
+		text := d.text[st.start:st.end]
+		normalized, err := pg_query.Normalize(text)
+		if err != nil {
+			return nil, nil //nolint:nilerr // no hover for invalid statements
+		}
+		fingerprint, err := pg_query.Fingerprint(text)
+		if err != nil {
+			return nil, nil //nolint:nilerr // no hover for invalid statements
+		}
+		return &hover{
+			Contents: markupContent{
+				Kind:  "markdown",
+				Value: fmt.Sprintf("```sql\n%s\n```\n\nFingerprint: `%s`", normalized, fingerprint),
+			},
+			Range: d.rangeOf(st.start, st.end),
+		}, nil
+	}
+	return nil, nil //nolint:nilnil // no hover outside of statements
+}
+
+// semanticTokens returns the semantic tokens of the document, encoded relative
+// to the previous token. Tokens spanning multiple lines are split into one per
+// line.
+func (s *server) semanticTokens(d *document, _ semanticTokensParams) (semanticTokens, error) {
+	res := semanticTokens{Data: []int{}}
+	scan, err := pg_query.Scan(d.text)
+	if err != nil {
+		return res, nil //nolint:nilerr // no tokens for input that does not scan
+	}
+	var prev position
+	for _, t := range scan.GetTokens() {
+		typ, ok := tokenType(t)
+		if !ok {
+			continue
+		}
+		for start, end := int(t.GetStart()), int(t.GetStart()); start < int(t.GetEnd()); start = end + 1 {
+			end = start
+			for end < int(t.GetEnd()) && d.text[end] != '\n' {
+				end++
+			}
+			length := 0
+			for _, r := range d.text[start:end] {
+				if r != '\r' {
+					length += utf16.RuneLen(r)
+				}
+			}
+			if length == 0 {
+				continue
+			}
+			pos := d.position(start)
+			char := pos.Character
+			if pos.Line == prev.Line {
+				char -= prev.Character
+			}
+			res.Data = append(res.Data, pos.Line-prev.Line, char, length, typ, 0)
+			prev = pos
+		}
+	}
+	return res, nil
+}
+
+func tokenType(t *pganalyze.ScanToken) (int, bool) {
+	if t.GetKeywordKind() != pganalyze.KeywordKind_NO_KEYWORD {
+		return tokenKeyword, true
+	}
+	switch t.GetToken() {
+	case pganalyze.Token_SCONST, pganalyze.Token_USCONST, pganalyze.Token_BCONST, pganalyze.Token_XCONST:
+		return tokenString, true
+	case pganalyze.Token_ICONST, pganalyze.Token_FCONST:
+		return tokenNumber, true
+	case pganalyze.Token_SQL_COMMENT, pganalyze.Token_C_COMMENT:
+		return tokenComment, true
+	case pganalyze.Token_PARAM:
+		return tokenParameter, true
+	case pganalyze.Token_Op, pganalyze.Token_TYPECAST, pganalyze.Token_LESS_EQUALS, pganalyze.Token_GREATER_EQUALS,
+		pganalyze.Token_NOT_EQUALS, pganalyze.Token_COLON_EQUALS, pganalyze.Token_EQUALS_GREATER,
+		pganalyze.Token_ASCII_37, pganalyze.Token_ASCII_42, pganalyze.Token_ASCII_43, pganalyze.Token_ASCII_45,
+		pganalyze.Token_ASCII_47, pganalyze.Token_ASCII_60, pganalyze.Token_ASCII_61, pganalyze.Token_ASCII_62,
+		pganalyze.Token_ASCII_94:
+		return tokenOperator, true
+	default:
+		return 0, false
+	}
+}
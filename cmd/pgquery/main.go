@@ -0,0 +1,413 @@
+// Command pgquery parses, scans, normalizes, fingerprints, deparses and splits
+// PostgreSQL SQL using the Postgres parser.
+//
+// Usage:
+//
+//	pgquery <command> [flags] [file ...]
+//
+// Input is read from the given files, or from standard input if there are none
+// or a file is named "-". With -ndjson, one JSON object is written per input
+// file, holding the file name and either the result or the error, which is
+// convenient for processing many files in batch.
+//
+// The stats command instead aggregates all of its inputs into a single report
+// of queries grouped by fingerprint and ranked by total time.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+	"github.com/wasilibs/go-pgquery/parser"
+	"github.com/wasilibs/go-pgquery/stats"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+const usage = `usage: pgquery <command> [flags] [file ...]
+
+Commands:
+  parse        print the parse tree (-format json, protobuf or text)
+  scan         print the tokens (-format text or json)
+  normalize    replace constants with parameter references
+  fingerprint  print the fingerprint identifying equivalent statements
+  deparse      print SQL for a JSON parse tree as output by parse
+  split        split input into statements (-parser, -trim)
+  plpgsql      print the parse tree of PL/pgSQL functions as JSON
+  stats        rank queries from pg_stat_statements exports or server logs
+               (-input, -format text, csv or json, -limit)
+
+Run pgquery <command> -h for the flags of a command.
+`
+
+var errUnknownFormat = errors.New("unknown format")
+
+// command processes the contents of an input file. The result is written
+// as is in plain mode and embedded in the object written in NDJSON mode.
+type command func(input []byte) (result, error)
+
+// result is the output of a command for a single input.
+type result interface {
+	// plain returns the output in plain mode.
+	plain() []byte
+	// json returns the output embedded in NDJSON mode.
+	json() json.RawMessage
+}
+
+// text is a textual result, written as a JSON string in NDJSON mode.
+type text string
+
+func (t text) plain() []byte {
+	if t == "" || strings.HasSuffix(string(t), "\n") {
+		return []byte(t)
+	}
+	return []byte(t + "\n")
+}
+
+func (t text) json() json.RawMessage {
+	b, _ := json.Marshal(string(t))
+	return b
+}
+
+// rawJSON is a result that is already JSON.
+type rawJSON string
+
+func (r rawJSON) plain() []byte {
+	return []byte(r + "\n")
+}
+
+func (r rawJSON) json() json.RawMessage {
+	return json.RawMessage(r)
+}
+
+// binary is a binary result, written base64 encoded in NDJSON mode.
+type binary []byte
+
+func (b binary) plain() []byte {
+	return b
+}
+
+func (b binary) json() json.RawMessage {
+	res, _ := json.Marshal([]byte(b))
+	return res
+}
+
+// statements are the statements of an input, each terminated by a semicolon
+// in plain mode.
+type statements []string
+
+func (s statements) plain() []byte {
+	var sb strings.Builder
+	for _, stmt := range s {
+		sb.WriteString(stmt)
+		sb.WriteString(";\n")
+	}
+	return []byte(sb.String())
+}
+
+func (s statements) json() json.RawMessage {
+	if s == nil {
+		s = statements{}
+	}
+	b, _ := json.Marshal([]string(s))
+	return b
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	if args[0] == "stats" {
+		return runStats(args[1:], stdin, stdout, stderr)
+	}
+
+	fs := flag.NewFlagSet("pgquery "+args[0], flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	ndjson := fs.Bool("ndjson", false, "write one JSON object per input file")
+
+	var cmd command
+	switch args[0] {
+	case "parse":
+		format := fs.String("format", "json", "output format: json, protobuf or text")
+		cmd = func(input []byte) (result, error) { return parse(string(input), *format) }
+	case "scan":
+		format := fs.String("format", "text", "output format: text or json")
+		cmd = func(input []byte) (result, error) { return scan(string(input), *format) }
+	case "normalize":
+		cmd = func(input []byte) (result, error) {
+			res, err := pg_query.Normalize(string(input))
+			return text(res), err
+		}
+	case "fingerprint":
+		cmd = func(input []byte) (result, error) {
+			res, err := pg_query.Fingerprint(string(input))
+			return text(res), err
+		}
+	case "deparse":
+		cmd = deparse
+	case "split":
+		useParser := fs.Bool("parser", false, "split using the parser instead of the scanner, failing for invalid input")
+		trim := fs.Bool("trim", true, "trim whitespace around statements")
+		cmd = func(input []byte) (result, error) {
+			split := pg_query.SplitWithScanner
+			if *useParser {
+				split = pg_query.SplitWithParser
+			}
+			res, err := split(string(input), *trim)
+			return statements(res), err
+		}
+	case "plpgsql":
+		cmd = func(input []byte) (result, error) {
+			res, err := pg_query.ParsePlPgSqlToJSON(string(input))
+			return rawJSON(res), err
+		}
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "pgquery: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	status := 0
+	for _, file := range files {
+		input, err := readInput(file, stdin)
+		var res result
+		if err == nil {
+			res, err = cmd(input)
+		}
+		if err != nil {
+			status = 1
+		}
+
+		if *ndjson {
+			writeRecord(stdout, file, input, res, err)
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %s\n", displayName(file), formatError(input, err))
+			continue
+		}
+		_, _ = stdout.Write(res.plain())
+	}
+	return status
+}
+
+func readInput(file string, stdin io.Reader) ([]byte, error) {
+	if file == "-" {
+		b, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("reading standard input: %w", err)
+		}
+		return b, nil
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading input: %w", err)
+	}
+	return b, nil
+}
+
+func displayName(file string) string {
+	if file == "-" {
+		return "<stdin>"
+	}
+	return file
+}
+
+// record is the object written per input file in NDJSON mode.
+type record struct {
+	File   string          `json:"file"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *recordError    `json:"error,omitempty"`
+}
+
+type recordError struct {
+	Message string `json:"message"`
+	// Line and Column are the 1-based position of the error in the input, if
+	// known.
+	Line   int `json:"line,omitempty"`
+	Column int `json:"column,omitempty"`
+}
+
+func writeRecord(w io.Writer, file string, input []byte, res result, err error) {
+	rec := record{File: displayName(file)}
+	if err != nil {
+		rec.Error = &recordError{Message: err.Error()}
+		rec.Error.Line, rec.Error.Column = errorPosition(input, err)
+	} else {
+		rec.Result = res.json()
+	}
+	b, _ := json.Marshal(rec)
+	_, _ = w.Write(append(b, '\n'))
+}
+
+// formatError formats err prefixed with its position in input if known.
+func formatError(input []byte, err error) string {
+	if line, col := errorPosition(input, err); line > 0 {
+		return fmt.Sprintf("%d:%d: %s", line, col, err)
+	}
+	return err.Error()
+}
+
+// errorPosition returns the 1-based line and column of the cursor position of
+// a parser error, or zeros if err does not have one.
+func errorPosition(input []byte, err error) (int, int) {
+	var perr *parser.Error
+	if !errors.As(err, &perr) || perr.Cursorpos <= 0 {
+		return 0, 0
+	}
+	// The cursor position counts characters, not bytes.
+	line, col, pos := 1, 1, 1
+	for _, r := range string(input) {
+		if pos == perr.Cursorpos {
+			break
+		}
+		if r == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+		pos++
+	}
+	return line, col
+}
+
+func parse(input, format string) (result, error) {
+	switch format {
+	case "json":
+		res, err := pg_query.ParseToJSON(input)
+		return rawJSON(res), err //nolint:wrapcheck // parser errors are reported as is
+	case "protobuf":
+		tree, err := pg_query.Parse(input)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // parser errors are reported as is
+		}
+		b, err := proto.Marshal(tree)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling parse tree: %w", err)
+		}
+		return binary(b), nil
+	case "text":
+		tree, err := pg_query.Parse(input)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // parser errors are reported as is
+		}
+		return text(prototext.MarshalOptions{Multiline: true}.Format(tree)), nil
+	}
+	return nil, fmt.Errorf("%w %q", errUnknownFormat, format)
+}
+
+func scan(input, format string) (result, error) {
+	res, err := pg_query.Scan(input)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // parser errors are reported as is
+	}
+	switch format {
+	case "json":
+		b, err := protojson.Marshal(res)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling tokens: %w", err)
+		}
+		return rawJSON(b), nil
+	case "text":
+		var sb strings.Builder
+		for _, t := range res.GetTokens() {
+			fmt.Fprintf(&sb, "%d\t%d\t%s\t%s\t%s\n", t.GetStart(), t.GetEnd(), t.GetToken(), t.GetKeywordKind(), input[t.GetStart():t.GetEnd()])
+		}
+		return text(sb.String()), nil
+	}
+	return nil, fmt.Errorf("%w %q", errUnknownFormat, format)
+}
+
+func deparse(input []byte) (result, error) {
+	var tree pganalyze.ParseResult
+	if err := protojson.Unmarshal(input, &tree); err != nil {
+		return nil, fmt.Errorf("reading JSON parse tree: %w", err)
+	}
+	res, err := pg_query.Deparse(&tree)
+	return text(res), err //nolint:wrapcheck // parser errors are reported as is
+}
+
+func runStats(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("pgquery stats", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	input := fs.String("input", "auto", "input format: auto, statements, csvlog or jsonlog")
+	format := fs.String("format", "text", "output format: text, csv or json")
+	limit := fs.Int("limit", 0, "maximum number of queries to report, 0 for all")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	var write func(r *stats.Report, w io.Writer) error
+	switch *format {
+	case "text":
+		write = (*stats.Report).WriteText
+	case "csv":
+		write = (*stats.Report).WriteCSV
+	case "json":
+		write = (*stats.Report).WriteJSON
+	default:
+		fmt.Fprintf(stderr, "pgquery stats: %s %q\n", errUnknownFormat, *format)
+		return 2
+	}
+	inputFormat := stats.Format(*input)
+	if *input == "auto" {
+		inputFormat = stats.FormatAuto
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	status := 0
+	agg := stats.NewAggregator()
+	for _, file := range files {
+		input, err := readInput(file, stdin)
+		if err == nil {
+			err = agg.Read(bytes.NewReader(input), inputFormat)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %s\n", displayName(file), err)
+			status = 1
+		}
+	}
+	if err := write(agg.Report().Top(*limit), stdout); err != nil {
+		fmt.Fprintf(stderr, "pgquery stats: %s\n", err)
+		return 1
+	}
+	return status
+}
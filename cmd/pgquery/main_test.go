@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.sql")
+	invalid := filepath.Join(dir, "invalid.sql")
+	if err := os.WriteFile(valid, []byte("SELECT 1;\nSELECT a FROM t WHERE b = 'x'"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(invalid, []byte("SELECT 1;\nSELECT FROM WHERE"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		args   []string
+		stdin  string
+		status int
+		stdout string
+		stderr string
+	}{
+		{
+			name:   "normalize stdin",
+			args:   []string{"normalize"},
+			stdin:  "SELECT 1",
+			stdout: "SELECT $1\n",
+		},
+		{
+			name:   "fingerprint",
+			args:   []string{"fingerprint", valid},
+			stdout: "088adf0a6bf3fb2c\n",
+		},
+		{
+			name:   "split",
+			args:   []string{"split", valid},
+			stdout: "SELECT 1;\nSELECT a FROM t WHERE b = 'x';\n",
+		},
+		{
+			name:   "parse text",
+			args:   []string{"parse", "-format", "text"},
+			stdin:  "SELECT",
+			stdout: "version: 170007\nstmts: {\n  stmt: {\n    select_stmt: {\n      limit_option: LIMIT_OPTION_DEFAULT\n      op: SETOP_NONE\n    }\n  }\n}\n",
+		},
+		{
+			name:   "scan",
+			args:   []string{"scan"},
+			stdin:  "SELECT x",
+			stdout: "0\t6\tSELECT\tRESERVED_KEYWORD\tSELECT\n7\t8\tIDENT\tNO_KEYWORD\tx\n",
+		},
+		{
+			name:   "deparse",
+			args:   []string{"deparse"},
+			stdin:  `{"version":170007,"stmts":[{"stmt":{"SelectStmt":{"targetList":[{"ResTarget":{"val":{"A_Const":{"ival":{"ival":1}}}}}],"limitOption":"LIMIT_OPTION_DEFAULT","op":"SETOP_NONE"}}}]}`,
+			stdout: "SELECT 1\n",
+		},
+		{
+			name:   "error",
+			args:   []string{"normalize", invalid},
+			status: 1,
+			stderr: invalid + ": 2:13: syntax error at or near \"WHERE\"\n",
+		},
+		{
+			name:   "ndjson",
+			args:   []string{"split", "-ndjson", "-parser", valid, invalid},
+			status: 1,
+			stdout: `{"file":"` + valid + `","result":["SELECT 1","SELECT a FROM t WHERE b = 'x'"]}` + "\n" +
+				`{"file":"` + invalid + `","error":{"message":"syntax error at or near \"WHERE\"","line":2,"column":13}}` + "\n",
+		},
+		{
+			name:  "stats",
+			args:  []string{"stats", "-format", "csv"},
+			stdin: "queryid,query,calls,total_exec_time\n1,\"SELECT $1\",2,3.5\n2,\"SELECT $1, $2\",1,1\n3,\"SELECT a FROM t\",4,10\n",
+			stdout: "rank,fingerprint,calls,total_time_ms,mean_time_ms,query_ids,query\n" +
+				"1,2f2a6b825f662fa2,4,10.000,2.500,3,SELECT a FROM t\n" +
+				"2,50fde20626009aba,3,4.500,1.500,1 2,SELECT $1\n",
+		},
+		{
+			name:   "unknown command",
+			args:   []string{"explode"},
+			status: 2,
+			stderr: "pgquery: unknown command \"explode\"\n\n" + usage,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			status := run(tc.args, strings.NewReader(tc.stdin), &stdout, &stderr)
+			if status != tc.status {
+				t.Errorf("expected status %d, got %d (stderr %q)", tc.status, status, stderr.String())
+			}
+			// prototext randomly inserts spaces to discourage depending on its
+			// output being stable.
+			if got := strings.ReplaceAll(stdout.String(), ":  ", ": "); got != tc.stdout {
+				t.Errorf("expected stdout %q, got %q", tc.stdout, got)
+			}
+			if stderr.String() != tc.stderr {
+				t.Errorf("expected stderr %q, got %q", tc.stderr, stderr.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package pg_query //nolint:revive // Keep package name aligned with existing public API.
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/wasilibs/go-pgquery/internal/diff"
+	"github.com/wasilibs/go-pgquery/internal/fingerprint"
+	"github.com/wasilibs/go-pgquery/internal/walk"
+)
+
+// FingerprintExplanation is the fingerprint of a query with the tokens hashed
+// to compute it.
+type FingerprintExplanation struct {
+	// Fingerprint is the fingerprint as returned by Fingerprint.
+	Fingerprint string
+	// Tokens are the node types, field names and values hashed, in order.
+	// Constants, locations and aliases are not part of the fingerprint and do
+	// not appear.
+	Tokens []string
+}
+
+// FingerprintExplain returns the fingerprint of input with the tokens hashed to
+// compute it, to understand why queries have the same or different
+// fingerprints.
+func FingerprintExplain(input string) (FingerprintExplanation, error) {
+	tree, err := Parse(input)
+	if err != nil {
+		return FingerprintExplanation{}, err
+	}
+	tokens := fingerprint.Tokens(tree)
+	return FingerprintExplanation{
+		Fingerprint: fmt.Sprintf("%016x", fingerprint.Hash(tokens)),
+		Tokens:      tokens,
+	}, nil
+}
+
+// FingerprintDiff returns a unified diff of the tokens hashed to fingerprint a
+// and b, one quoted token per line, or an empty string if the queries have the
+// same fingerprint.
+func FingerprintDiff(a, b string) (string, error) {
+	ea, err := FingerprintExplain(a)
+	if err != nil {
+		return "", err
+	}
+	eb, err := FingerprintExplain(b)
+	if err != nil {
+		return "", err
+	}
+	return diff.Unified("a", "b", tokenLines(ea.Tokens), tokenLines(eb.Tokens)), nil
+}
+
+func tokenLines(tokens []string) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		b.WriteString(strconv.Quote(t))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// FingerprintOptions configure FingerprintTree. The zero value fingerprints
+// like FingerprintToUInt64.
+type FingerprintOptions struct {
+	// IgnoreSchema ignores schema and database qualification of tables and
+	// columns, so that public.users and users have the same fingerprint.
+	IgnoreSchema bool
+	// IgnoreTableAliases fingerprints columns qualified by a table alias as if
+	// they were qualified by the table name, so that SELECT u.id FROM users u
+	// and SELECT users.id FROM users have the same fingerprint. Aliases
+	// themselves are never part of the fingerprint.
+	IgnoreTableAliases bool
+	// IgnoreLimit ignores LIMIT, OFFSET and FETCH clauses. Constant limits are
+	// always ignored, this also ignores their presence and expressions.
+	IgnoreLimit bool
+}
+
+// FingerprintTree returns the fingerprint of the statements of an already
+// parsed tree, such as one that was rewritten, without deparsing it. With the
+// zero FingerprintOptions, it returns the same value as FingerprintToUInt64
+// for the query tree was parsed from. The tree is not modified.
+func FingerprintTree(tree *pganalyze.ParseResult, opts FingerprintOptions) uint64 {
+	if opts != (FingerprintOptions{}) {
+		tree = proto.CloneOf(tree)
+		for _, raw := range tree.GetStmts() {
+			opts.apply(raw)
+		}
+	}
+	return fingerprint.Hash(fingerprint.Tokens(tree))
+}
+
+// apply rewrites raw so that it fingerprints as configured.
+func (o FingerprintOptions) apply(raw *pganalyze.RawStmt) {
+	o.rewrite(raw, nil)
+}
+
+// rewrite rewrites msg and the nodes below it, with sc the table aliases
+// visible at msg. Statements with a FROM clause are rewritten with a scope of
+// their own, so that aliases of subqueries shadow those of outer queries.
+func (o FingerprintOptions) rewrite(msg proto.Message, sc *aliasScope) {
+	walk.Walk(msg, func(m proto.Message) bool {
+		if m != msg && o.IgnoreTableAliases {
+			if from, ok := fromItems(m); ok {
+				o.rewrite(m, sc.child(from))
+				return false
+			}
+		}
+		switch m := m.(type) {
+		case *pganalyze.RangeVar:
+			if o.IgnoreSchema {
+				m.Catalogname, m.Schemaname = "", ""
+			}
+		case *pganalyze.ColumnRef:
+			o.applyColumnRef(m, sc)
+		case *pganalyze.SelectStmt:
+			if o.IgnoreLimit {
+				m.LimitCount, m.LimitOffset = nil, nil
+				m.LimitOption = pganalyze.LimitOption_LIMIT_OPTION_DEFAULT
+			}
+		}
+		return true
+	})
+}
+
+// aliasScope holds the names tables are referred to by in a query, mapping
+// table aliases to their table. Other names, such as those of unaliased tables
+// and subqueries, map to nil and only shadow the names of outer queries.
+type aliasScope struct {
+	parent *aliasScope
+	names  map[string]*pganalyze.RangeVar
+}
+
+func (s *aliasScope) child(from []*pganalyze.Node) *aliasScope {
+	c := &aliasScope{parent: s, names: map[string]*pganalyze.RangeVar{}}
+	for _, n := range from {
+		c.add(n)
+	}
+	return c
+}
+
+func (s *aliasScope) add(n *pganalyze.Node) {
+	switch f := walk.Unwrap(n).(type) {
+	case *pganalyze.RangeVar:
+		if a := f.GetAlias(); a != nil {
+			s.names[a.GetAliasname()] = f
+		} else {
+			s.names[f.GetRelname()] = nil
+		}
+	case *pganalyze.JoinExpr:
+		s.add(f.GetLarg())
+		s.add(f.GetRarg())
+		if a := f.GetAlias(); a != nil {
+			s.names[a.GetAliasname()] = nil
+		}
+	case *pganalyze.RangeTableSample:
+		s.add(f.GetRelation())
+	case *pganalyze.RangeSubselect:
+		s.names[f.GetAlias().GetAliasname()] = nil
+	case *pganalyze.RangeFunction:
+		if a := f.GetAlias(); a != nil {
+			s.names[a.GetAliasname()] = nil
+		}
+	}
+}
+
+// table returns the table name refers to by alias, searching from the
+// innermost scope outwards.
+func (s *aliasScope) table(name string) *pganalyze.RangeVar {
+	for ; s != nil; s = s.parent {
+		if rv, ok := s.names[name]; ok {
+			return rv
+		}
+	}
+	return nil
+}
+
+// fromItems returns the FROM items of a statement, including the target
+// relation of statements modifying one, and whether msg is such a statement.
+func fromItems(msg proto.Message) ([]*pganalyze.Node, bool) {
+	rangeVar := func(rv *pganalyze.RangeVar) *pganalyze.Node {
+		return &pganalyze.Node{Node: &pganalyze.Node_RangeVar{RangeVar: rv}}
+	}
+	switch m := msg.(type) {
+	case *pganalyze.SelectStmt:
+		return m.GetFromClause(), true
+	case *pganalyze.InsertStmt:
+		return []*pganalyze.Node{rangeVar(m.GetRelation())}, true
+	case *pganalyze.UpdateStmt:
+		return append([]*pganalyze.Node{rangeVar(m.GetRelation())}, m.GetFromClause()...), true
+	case *pganalyze.DeleteStmt:
+		return append([]*pganalyze.Node{rangeVar(m.GetRelation())}, m.GetUsingClause()...), true
+	case *pganalyze.MergeStmt:
+		return []*pganalyze.Node{rangeVar(m.GetRelation()), m.GetSourceRelation()}, true
+	}
+	return nil, false
+}
+
+func (o FingerprintOptions) applyColumnRef(ref *pganalyze.ColumnRef, sc *aliasScope) {
+	fields := ref.GetFields()
+	if len(fields) < 2 {
+		return
+	}
+	// Fields are [[[catalog.]schema.]table.]column.
+	if o.IgnoreSchema && len(fields) > 2 {
+		fields = fields[len(fields)-2:]
+	}
+	if rv := sc.table(fields[0].GetString_().GetSval()); rv != nil && len(fields) == 2 {
+		table := &pganalyze.Node{Node: &pganalyze.Node_String_{String_: &pganalyze.String{Sval: rv.GetRelname()}}}
+		fields = []*pganalyze.Node{table, fields[1]}
+		if rv.GetSchemaname() != "" && !o.IgnoreSchema {
+			schema := &pganalyze.Node{Node: &pganalyze.Node_String_{String_: &pganalyze.String{Sval: rv.GetSchemaname()}}}
+			fields = append([]*pganalyze.Node{schema}, fields...)
+		}
+	}
+	ref.Fields = fields
+}
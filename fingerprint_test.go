@@ -7,6 +7,10 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/protobuf/proto"
+
 	pg_query "github.com/wasilibs/go-pgquery"
 )
 
@@ -87,3 +91,166 @@ func TestHashXXH3_64(t *testing.T) {
 		}
 	}
 }
+
+func TestFingerprintExplain(t *testing.T) {
+	var fingerprintTests []fingerprintTest
+	file, err := os.ReadFile("./testdata/fingerprint.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(file, &fingerprintTests); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range fingerprintTests {
+		t.Run(test.Input, func(t *testing.T) {
+			e, err := pg_query.FingerprintExplain(test.Input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e.Fingerprint != test.ExpectedHash {
+				t.Errorf("expected fingerprint %s, actual %s", test.ExpectedHash, e.Fingerprint)
+			}
+			if diff := cmp.Diff(test.ExpectedParts, e.Tokens, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("unexpected tokens (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFingerprintExplainMatchesFingerprint(t *testing.T) {
+	tests := libpgqueryFingerprintTests
+	for i := 0; i < len(tests); i += 2 {
+		e, err := pg_query.FingerprintExplain(tests[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.Fingerprint != tests[i+1] {
+			t.Errorf("FingerprintExplain(%s)\nexpected %s\nactual %s", tests[i], tests[i+1], e.Fingerprint)
+		}
+	}
+}
+
+func TestFingerprintDiff(t *testing.T) {
+	d, err := pg_query.FingerprintDiff("SELECT a AS x FROM t WHERE b IN (1, 2)", "SELECT a FROM t WHERE b = 3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d != "" {
+		t.Errorf("expected no differences, actual:\n%s", d)
+	}
+
+	d, err = pg_query.FingerprintDiff("SELECT a FROM t", "SELECT a FROM u")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `--- a
++++ b
+@@ -6,7 +6,7 @@
+ "inh"
+ "true"
+ "relname"
+-"t"
++"u"
+ "relpersistence"
+ "p"
+ "limitOption"
+`
+	if d != want {
+		t.Errorf("unexpected diff:\n%s", d)
+	}
+
+	if _, err := pg_query.FingerprintDiff("SELECT a", "SELECT !"); err == nil {
+		t.Error("expected error for invalid query")
+	}
+}
+
+func TestFingerprintTree(t *testing.T) {
+	var fingerprintTests []fingerprintTest
+	file, err := os.ReadFile("./testdata/fingerprint.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(file, &fingerprintTests); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range fingerprintTests {
+		tree, err := pg_query.Parse(test.Input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, _ := strconv.ParseUint(test.ExpectedHash, 16, 64)
+		if actual := pg_query.FingerprintTree(tree, pg_query.FingerprintOptions{}); actual != expected {
+			t.Errorf("FingerprintTree(%s)\nexpected %x\nactual %x", test.Input, expected, actual)
+		}
+	}
+}
+
+func TestFingerprintTreeOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		opts pg_query.FingerprintOptions
+	}{
+		{
+			name: "schema",
+			a:    "SELECT public.users.id FROM public.users JOIN db.s.orders ON true",
+			b:    "SELECT users.id FROM users JOIN orders ON true",
+			opts: pg_query.FingerprintOptions{IgnoreSchema: true},
+		},
+		{
+			name: "table aliases",
+			a:    "SELECT u.id, u.* FROM users u WHERE u.name = $1",
+			b:    "SELECT users.id, users.* FROM users WHERE users.name = $1",
+			opts: pg_query.FingerprintOptions{IgnoreTableAliases: true},
+		},
+		{
+			name: "qualified table aliases",
+			a:    "SELECT u.id FROM app.users u",
+			b:    "SELECT app.users.id FROM app.users",
+			opts: pg_query.FingerprintOptions{IgnoreTableAliases: true},
+		},
+		{
+			name: "shadowed table aliases",
+			a:    "SELECT x.id FROM users x WHERE EXISTS (SELECT 1 FROM orders x WHERE x.uid = 1) AND x.id IN (SELECT x.uid FROM orders)",
+			b:    "SELECT users.id FROM users WHERE EXISTS (SELECT 1 FROM orders WHERE orders.uid = 1) AND users.id IN (SELECT users.uid FROM orders)",
+			opts: pg_query.FingerprintOptions{IgnoreTableAliases: true},
+		},
+		{
+			name: "table aliases and schema",
+			a:    "SELECT u.id FROM app.users u",
+			b:    "SELECT users.id FROM users",
+			opts: pg_query.FingerprintOptions{IgnoreSchema: true, IgnoreTableAliases: true},
+		},
+		{
+			name: "limit",
+			a:    "SELECT a FROM t ORDER BY a LIMIT (SELECT n FROM c) OFFSET 5 + 5",
+			b:    "SELECT a FROM t ORDER BY a FETCH FIRST 3 ROWS WITH TIES",
+			opts: pg_query.FingerprintOptions{IgnoreLimit: true},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			a, err := pg_query.Parse(tc.a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := pg_query.Parse(tc.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pg_query.FingerprintTree(a, pg_query.FingerprintOptions{}) == pg_query.FingerprintTree(b, pg_query.FingerprintOptions{}) {
+				t.Errorf("expected different fingerprints without options")
+			}
+			before := proto.CloneOf(a)
+			if pg_query.FingerprintTree(a, tc.opts) != pg_query.FingerprintTree(b, tc.opts) {
+				t.Errorf("expected same fingerprint with %+v", tc.opts)
+			}
+			if !proto.Equal(before, a) {
+				t.Error("tree was modified")
+			}
+		})
+	}
+}
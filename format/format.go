@@ -0,0 +1,371 @@
+// Package format pretty-prints SQL. Statements are deparsed from their parse
+// tree, which yields a canonical form of keywords, quoting and spacing, and then
+// broken into lines at clause boundaries and list items to fit a maximum line
+// length.
+//
+// The parse tree does not contain comments. Comments preceding a statement are
+// placed on their own lines before the statement, comments within it at the
+// end of the line of the token they follow, and comments following a statement
+// on the same line are kept after it.
+package format
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+)
+
+// ErrFingerprintMismatch is returned when the formatted form of a statement
+// does not have the same fingerprint as the original, which would indicate a
+// change in meaning.
+var ErrFingerprintMismatch = errors.New("format: formatted statement has a different fingerprint")
+
+// Options configures formatting.
+type Options struct {
+	// Indent is the number of spaces per indentation level, 2 if zero.
+	Indent int
+	// MaxLineLength is the length lines are broken to fit within where
+	// possible, 80 if zero.
+	MaxLineLength int
+	// CommasFirst places the commas separating list items at the start of the
+	// lines of the items following them instead of at the end of the preceding
+	// lines.
+	CommasFirst bool
+}
+
+// Format formats the statements of sql, each terminated by a semicolon.
+func Format(sql string, opts Options) (string, error) {
+	if opts.Indent <= 0 {
+		opts.Indent = 2
+	}
+	if opts.MaxLineLength <= 0 {
+		opts.MaxLineLength = 80
+	}
+
+	tree, err := pg_query.Parse(sql)
+	if err != nil {
+		return "", fmt.Errorf("format: parsing input: %w", err)
+	}
+	scan, err := pg_query.Scan(sql)
+	if err != nil {
+		return "", fmt.Errorf("format: scanning input: %w", err)
+	}
+
+	stmts := layoutStatements(sql, tree, scan.GetTokens())
+	if len(stmts) == 0 {
+		return formatComments(sql, scan.GetTokens()), nil
+	}
+
+	var sb strings.Builder
+	for i, st := range stmts {
+		if i > 0 {
+			sb.WriteByte('\n')
+			if st.blankBefore {
+				sb.WriteByte('\n')
+			}
+		}
+		for _, c := range st.leading {
+			sb.WriteString(c)
+			sb.WriteByte('\n')
+		}
+
+		formatted, trailing, err := formatStatement(sql, tree, i, st, opts)
+		if err != nil {
+			return "", err
+		}
+		if err := checkFingerprint(sql[st.start:st.end], formatted, i); err != nil {
+			return "", err
+		}
+		sb.WriteString(formatted)
+		sb.WriteByte(';')
+		for _, c := range append(trailing, st.trailing...) {
+			sb.WriteByte(' ')
+			sb.WriteString(c)
+		}
+		for _, c := range st.footer {
+			sb.WriteByte('\n')
+			sb.WriteString(c)
+		}
+	}
+	sb.WriteByte('\n')
+	return sb.String(), nil
+}
+
+// formatStatement formats statement idx of tree, placed at st in sql. The
+// comments within the statement that follow a token of its last line are
+// returned separately, to be placed after the semicolon.
+func formatStatement(sql string, tree *pganalyze.ParseResult, idx int, st *statement, opts Options) (string, []string, error) {
+	single := &pganalyze.ParseResult{
+		Version: tree.GetVersion(),
+		Stmts:   []*pganalyze.RawStmt{{Stmt: tree.GetStmts()[idx].GetStmt()}},
+	}
+	deparsed, err := pg_query.Deparse(single)
+	if err != nil {
+		return "", nil, fmt.Errorf("format: deparsing statement %d: %w", idx+1, err)
+	}
+	scan, err := pg_query.Scan(deparsed)
+	if err != nil {
+		return "", nil, fmt.Errorf("format: scanning statement %d: %w", idx+1, err)
+	}
+	p := newPrinter(deparsed, scan.GetTokens(), opts)
+	lines := p.statement()
+	if len(st.inner) == 0 || len(lines) == 0 {
+		return strings.Join(lines, "\n"), nil, nil
+	}
+
+	// Each comment follows the deparsed token matching the token it follows
+	// in the input, or the closest preceding token that has a match.
+	matches := matchTokens(sql, st.tokens, deparsed, scan.GetTokens())
+	lineOf := tokenLines(lines, deparsed, scan.GetTokens())
+	comments := make([][]string, len(lines))
+	for _, c := range st.inner {
+		line := 0
+		for k := c.after; k >= 0; k-- {
+			if m := matches[k]; m >= 0 {
+				line = lineOf[m]
+				break
+			}
+		}
+		comments[line] = append(comments[line], c.text)
+	}
+	for n, cs := range comments[:len(lines)-1] {
+		for _, c := range cs {
+			lines[n] += " " + c
+		}
+	}
+	return strings.Join(lines, "\n"), comments[len(lines)-1], nil
+}
+
+func checkFingerprint(original, formatted string, idx int) error {
+	want, err := pg_query.Fingerprint(original)
+	if err != nil {
+		return fmt.Errorf("format: fingerprinting statement %d: %w", idx+1, err)
+	}
+	got, err := pg_query.Fingerprint(formatted)
+	if err != nil {
+		return fmt.Errorf("format: fingerprinting formatted statement %d: %w", idx+1, err)
+	}
+	if got != want {
+		return fmt.Errorf("%w: statement %d", ErrFingerprintMismatch, idx+1)
+	}
+	return nil
+}
+
+// statement is the placement of a statement and its comments in the input.
+type statement struct {
+	// start and end are the range of the code of the statement, without
+	// surrounding comments and whitespace.
+	start, end int
+	// tokens are the tokens of the code of the statement.
+	tokens      []*pganalyze.ScanToken
+	inner       []innerComment
+	leading     []string
+	trailing    []string
+	footer      []string
+	blankBefore bool
+}
+
+// innerComment is a comment within the code of a statement.
+type innerComment struct {
+	text string
+	// after is the index of the token of the statement the comment follows.
+	after int
+}
+
+// layoutStatements assigns the comments of the input to statements.
+func layoutStatements(sql string, tree *pganalyze.ParseResult, tokens []*pganalyze.ScanToken) []*statement {
+	raws := tree.GetStmts()
+	if len(raws) == 0 {
+		return nil
+	}
+	stmts := make([]*statement, len(raws))
+	for i, raw := range raws {
+		start := int(raw.GetStmtLocation())
+		end := len(sql)
+		if raw.GetStmtLen() > 0 {
+			end = start + int(raw.GetStmtLen())
+		}
+		st := &statement{start: -1}
+		for _, t := range tokens {
+			if int(t.GetStart()) < start || int(t.GetStart()) >= end || isComment(t) {
+				continue
+			}
+			if st.start < 0 {
+				st.start = int(t.GetStart())
+			}
+			st.end = int(t.GetEnd())
+			st.tokens = append(st.tokens, t)
+		}
+		stmts[i] = st
+	}
+
+	// lineEnd is the end of the code of the statement, including its
+	// semicolon, after which a comment on the same line trails it.
+	lineEnd := func(i int) int {
+		end := stmts[i].end
+		for end < len(sql) && (sql[end] == ' ' || sql[end] == '\t') {
+			end++
+		}
+		if end < len(sql) && sql[end] == ';' {
+			end++
+		}
+		return end
+	}
+
+	// firstItem is the start of the first comment or code of each statement,
+	// used to detect blank lines between statements.
+	firstItem := make([]int, len(stmts))
+	for i, st := range stmts {
+		firstItem[i] = st.start
+	}
+
+	for _, t := range tokens {
+		if !isComment(t) {
+			continue
+		}
+		text := sql[t.GetStart():t.GetEnd()]
+		pos := int(t.GetStart())
+
+		// The statement the comment precedes or is within, or the last one.
+		idx := len(stmts) - 1
+		for i, st := range stmts {
+			if pos < st.end {
+				idx = i
+				break
+			}
+		}
+		st := stmts[idx]
+
+		switch {
+		case pos < st.start:
+			if idx > 0 && !strings.Contains(sql[lineEnd(idx-1):pos], "\n") {
+				stmts[idx-1].trailing = append(stmts[idx-1].trailing, text)
+				continue
+			}
+			st.leading = append(st.leading, text)
+			firstItem[idx] = min(firstItem[idx], pos)
+		case pos >= st.end:
+			if !strings.Contains(sql[lineEnd(idx):pos], "\n") {
+				st.trailing = append(st.trailing, text)
+			} else {
+				st.footer = append(st.footer, text)
+			}
+		default:
+			after := 0
+			for after+1 < len(st.tokens) && int(st.tokens[after+1].GetEnd()) <= pos {
+				after++
+			}
+			st.inner = append(st.inner, innerComment{text: text, after: after})
+		}
+	}
+
+	for i := 1; i < len(stmts); i++ {
+		stmts[i].blankBefore = hasBlankLine(sql[lineEnd(i-1):firstItem[i]])
+	}
+	return stmts
+}
+
+// matchTokens matches the tokens of the input to the tokens of the deparsed
+// statement by their longest common subsequence, returning the index of the
+// deparsed token matching each input token, or -1.
+func matchTokens(sql string, tokens []*pganalyze.ScanToken, deparsed string, deparsedTokens []*pganalyze.ScanToken) []int {
+	key := func(src string, t *pganalyze.ScanToken) string {
+		text := src[t.GetStart():t.GetEnd()]
+		if t.GetToken() == pganalyze.Token_IDENT || t.GetKeywordKind() != pganalyze.KeywordKind_NO_KEYWORD {
+			text = strings.ToLower(strings.Trim(text, `"`))
+		}
+		return t.GetToken().String() + " " + text
+	}
+	a := make([]string, len(tokens))
+	for i, t := range tokens {
+		a[i] = key(sql, t)
+	}
+	b := make([]string, len(deparsedTokens))
+	for i, t := range deparsedTokens {
+		b[i] = key(deparsed, t)
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and
+	// b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	matches := make([]int, len(a))
+	for i, j := 0, 0; i < len(a); {
+		switch {
+		case j < len(b) && a[i] == b[j]:
+			matches[i] = j
+			i++
+			j++
+		case j < len(b) && lcs[i][j+1] >= lcs[i+1][j]:
+			j++
+		default:
+			matches[i] = -1
+			i++
+		}
+	}
+	return matches
+}
+
+// tokenLines returns the index of the line of lines each deparsed token is
+// printed on. The lines contain the tokens in order, separated by whitespace.
+func tokenLines(lines []string, deparsed string, tokens []*pganalyze.ScanToken) []int {
+	lineOf := make([]int, len(tokens))
+	line, col := 0, 0
+	for k, t := range tokens {
+		text := deparsed[t.GetStart():t.GetEnd()]
+		for line < len(lines)-1 && strings.TrimSpace(lines[line][col:]) == "" {
+			line, col = line+1, 0
+		}
+		rest := lines[line][col:]
+		trimmed := strings.TrimLeft(rest, " ")
+		if strings.HasPrefix(trimmed, text) {
+			col += len(rest) - len(trimmed) + len(text)
+		}
+		lineOf[k] = line
+	}
+	return lineOf
+}
+
+// formatComments formats input without statements, keeping only its comments.
+func formatComments(sql string, tokens []*pganalyze.ScanToken) string {
+	var sb strings.Builder
+	for _, t := range tokens {
+		if isComment(t) {
+			sb.WriteString(sql[t.GetStart():t.GetEnd()])
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
+
+// hasBlankLine returns whether s contains a line with only whitespace between
+// two newlines.
+func hasBlankLine(s string) bool {
+	lines := strings.Split(s, "\n")
+	for _, l := range lines[min(1, len(lines)):max(len(lines)-1, 0)] {
+		if strings.TrimSpace(l) == "" {
+			return true
+		}
+	}
+	return false
+}
+
+func isComment(t *pganalyze.ScanToken) bool {
+	return t.GetToken() == pganalyze.Token_SQL_COMMENT || t.GetToken() == pganalyze.Token_C_COMMENT
+}
@@ -0,0 +1,90 @@
+package format_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/wasilibs/go-pgquery/format"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  format.Options
+		want  string
+	}{
+		{
+			name:  "clauses",
+			input: "select a,b from t where x=1",
+			want:  "SELECT a, b\nFROM t\nWHERE x = 1;\n",
+		},
+		{
+			name:  "comments",
+			input: "-- header\nselect 1; -- one\n\n/* two */ select 2;\n-- footer",
+			want:  "-- header\nSELECT 1; -- one\n\n/* two */\nSELECT 2;\n-- footer\n",
+		},
+		{
+			name:  "end of line comments",
+			input: "select a, -- note\n b from t /* source */ where x = 1 -- last\n and y = 2",
+			want:  "SELECT a, b -- note\nFROM t /* source */\nWHERE x = 1 AND y = 2; -- last\n",
+		},
+		{
+			name:  "end of line comments in lists",
+			input: "select aaaaaaaaaa, -- first\n bbbbbbbbbbbb /* second */, \"cccccccccccc\" -- third\n from tttttttttt",
+			opts:  format.Options{MaxLineLength: 40},
+			want:  "SELECT\n  aaaaaaaaaa, -- first\n  bbbbbbbbbbbb, /* second */\n  cccccccccccc -- third\nFROM tttttttttt;\n",
+		},
+		{
+			name:  "comments only",
+			input: "-- only",
+			want:  "-- only\n",
+		},
+		{
+			name:  "long lists and conditions",
+			input: "select aaaaaaaaaa, bbbbbbbbbbbb, cccccccccccc from tttttttttt where xxxxxxxxxxx = 1 and yyyyyyyyyyyyy = 2",
+			opts:  format.Options{MaxLineLength: 40},
+			want:  "SELECT\n  aaaaaaaaaa,\n  bbbbbbbbbbbb,\n  cccccccccccc\nFROM tttttttttt\nWHERE xxxxxxxxxxx = 1\n  AND yyyyyyyyyyyyy = 2;\n",
+		},
+		{
+			name:  "commas first",
+			input: "select aaaaaaaaaa, bbbbbbbbbbbb, cccccccccccc from tttttttttt",
+			opts:  format.Options{MaxLineLength: 40, CommasFirst: true, Indent: 4},
+			want:  "SELECT\n      aaaaaaaaaa\n    , bbbbbbbbbbbb\n    , cccccccccccc\nFROM tttttttttt;\n",
+		},
+		{
+			name:  "subquery",
+			input: "select * from (select aaaaaaaaaa, bbbbbbbbbbbb from tttttttttt) s",
+			opts:  format.Options{MaxLineLength: 30},
+			want:  "SELECT *\nFROM (\n  SELECT\n    aaaaaaaaaa,\n    bbbbbbbbbbbb\n  FROM tttttttttt\n) s;\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := format.Format(tc.input, tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected output (-want +got):\n%s", diff)
+			}
+
+			// Formatting is idempotent.
+			again, err := format.Format(got, tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(got, again); diff != "" {
+				t.Errorf("formatting is not idempotent (-first +second):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFormatError(t *testing.T) {
+	if _, err := format.Format("select from where", format.Options{}); err == nil {
+		t.Error("expected error for invalid input")
+	}
+}
@@ -0,0 +1,404 @@
+package format
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+)
+
+// printer lays out a deparsed statement. Lines are only broken between tokens,
+// and tokens on the same line keep the spacing of the deparsed text.
+type printer struct {
+	opts   Options
+	src    string
+	tokens []*pganalyze.ScanToken
+	// words are the uppercased keywords, empty for other tokens.
+	words []string
+	// match is the index of the closing parenthesis or bracket for opening
+	// ones.
+	match []int
+}
+
+func newPrinter(src string, tokens []*pganalyze.ScanToken, opts Options) *printer {
+	p := &printer{opts: opts, src: src, tokens: tokens, words: make([]string, len(tokens)), match: make([]int, len(tokens))}
+	var stack []int
+	for i, t := range tokens {
+		if t.GetKeywordKind() != pganalyze.KeywordKind_NO_KEYWORD {
+			p.words[i] = strings.ToUpper(src[t.GetStart():t.GetEnd()])
+		}
+		switch t.GetToken() {
+		case pganalyze.Token_ASCII_40, pganalyze.Token_ASCII_91:
+			stack = append(stack, i)
+		case pganalyze.Token_ASCII_41, pganalyze.Token_ASCII_93:
+			if len(stack) > 0 {
+				p.match[stack[len(stack)-1]] = i
+				stack = stack[:len(stack)-1]
+			}
+		default:
+		}
+	}
+	return p
+}
+
+// queryStarts are the keywords starting a query, which is laid out by clause.
+var queryStarts = map[string]bool{
+	"SELECT": true,
+	"WITH":   true,
+	"VALUES": true,
+	"INSERT": true,
+	"UPDATE": true,
+	"DELETE": true,
+	"MERGE":  true,
+}
+
+// listClauses are the clauses whose body is a comma-separated list.
+var listClauses = map[string]bool{
+	"SELECT":    true,
+	"GROUP":     true,
+	"ORDER":     true,
+	"RETURNING": true,
+	"SET":       true,
+	"VALUES":    true,
+	"WITH":      true,
+	"WINDOW":    true,
+}
+
+// joinWords are the keywords that may precede JOIN.
+var joinWords = map[string]bool{
+	"INNER":   true,
+	"LEFT":    true,
+	"RIGHT":   true,
+	"FULL":    true,
+	"OUTER":   true,
+	"CROSS":   true,
+	"NATURAL": true,
+}
+
+// statement returns the lines of the statement. Utility statements containing
+// a query, such as CREATE VIEW, are laid out as a prefix followed by the query.
+func (p *printer) statement() []string {
+	n := len(p.tokens)
+	if n == 0 {
+		return nil
+	}
+	if queryStarts[p.words[0]] {
+		return p.query(0, n, 0)
+	}
+	for k := 1; k < n; k = p.next(k) {
+		if queryStarts[p.words[k]] && p.words[k] != "WITH" {
+			return append(p.expr(0, k, 0, ""), p.query(k, n, 0)...)
+		}
+	}
+	return p.expr(0, n, 0, "")
+}
+
+// next returns the index of the token after the one at k, skipping over the
+// contents of parentheses.
+func (p *printer) next(k int) int {
+	if p.isOpen(k) {
+		return p.match[k] + 1
+	}
+	return k + 1
+}
+
+func (p *printer) isOpen(k int) bool {
+	t := p.tokens[k].GetToken()
+	return (t == pganalyze.Token_ASCII_40 || t == pganalyze.Token_ASCII_91) && p.match[k] > k
+}
+
+func (p *printer) isComma(k int) bool {
+	return p.tokens[k].GetToken() == pganalyze.Token_ASCII_44
+}
+
+// text returns the deparsed text of tokens i to j.
+func (p *printer) text(i, j int) string {
+	if i >= j {
+		return ""
+	}
+	return p.src[p.tokens[i].GetStart():p.tokens[j-1].GetEnd()]
+}
+
+func (p *printer) indent(depth int) string {
+	return strings.Repeat(" ", depth*p.opts.Indent)
+}
+
+func (p *printer) fits(line string) bool {
+	return !strings.Contains(line, "\n") && utf8.RuneCountInString(line) <= p.opts.MaxLineLength
+}
+
+// query returns the lines of the query in tokens i to j, one clause per line
+// unless a clause does not fit.
+func (p *printer) query(i, j, depth int) []string {
+	var lines []string
+	start := i
+	for k := p.next(i); k < j; k = p.next(k) {
+		if p.startsClause(k, i) {
+			lines = append(lines, p.clause(start, k, depth)...)
+			start = k
+		}
+	}
+	return append(lines, p.clause(start, j, depth)...)
+}
+
+func (p *printer) startsClause(k, first int) bool {
+	word := p.words[k]
+	nextWord := ""
+	if k+1 < len(p.words) {
+		nextWord = p.words[k+1]
+	}
+	switch word {
+	case "SELECT", "WHERE", "HAVING", "WINDOW", "LIMIT", "OFFSET", "FETCH", "RETURNING", "VALUES",
+		"UNION", "INTERSECT", "EXCEPT":
+		return true
+	case "FROM":
+		return p.words[k-1] != "DISTINCT" && !(p.words[first] == "DELETE" && k == first+1)
+	case "GROUP", "ORDER":
+		return nextWord == "BY"
+	case "SET":
+		return p.words[first] == "UPDATE" || p.words[first] == "INSERT"
+	case "USING":
+		return p.words[first] == "DELETE" || p.words[first] == "MERGE"
+	case "ON":
+		return nextWord == "CONFLICT"
+	case "WHEN":
+		return p.words[first] == "MERGE"
+	}
+	return false
+}
+
+// headerLen returns the number of keywords forming the header of the clause
+// starting at k, such as 2 for ORDER BY.
+func (p *printer) headerLen(k, j int) int {
+	if p.words[k] == "" {
+		return 0
+	}
+	if k+1 < j {
+		switch p.words[k] + " " + p.words[k+1] {
+		case "GROUP BY", "ORDER BY", "ON CONFLICT", "INSERT INTO", "DELETE FROM", "MERGE INTO",
+			"UNION ALL", "UNION DISTINCT", "INTERSECT ALL", "EXCEPT ALL", "WITH RECURSIVE":
+			return 2
+		case "SELECT DISTINCT":
+			if k+2 < j && p.words[k+2] != "ON" {
+				return 2
+			}
+		}
+	}
+	return 1
+}
+
+// clause returns the lines of the clause in tokens i to j.
+func (p *printer) clause(i, j, depth int) []string {
+	if line := p.indent(depth) + p.text(i, j); p.fits(line) {
+		return []string{line}
+	}
+
+	h := p.headerLen(i, j)
+	if h == 0 {
+		return p.expr(i, j, depth, "")
+	}
+	header := p.text(i, i+h)
+	body := i + h
+	if body == j {
+		return []string{p.indent(depth) + header}
+	}
+
+	switch word := p.words[i]; {
+	case listClauses[word]:
+		items := p.split(body, j)
+		if len(items) == 1 {
+			return p.expr(body, j, depth, header+" ")
+		}
+		return append([]string{p.indent(depth) + header}, p.list(items, depth+1)...)
+	case word == "FROM" || word == "USING":
+		items := p.split(body, j)
+		if len(items) == 1 {
+			return p.joins(body, j, depth, header+" ")
+		}
+		lines := []string{p.indent(depth) + header}
+		for n, item := range items {
+			itemLines := p.joins(item[0], item[1], depth+1, p.itemPrefix(n))
+			lines = append(lines, p.separate(itemLines, n, len(items))...)
+		}
+		return lines
+	case word == "WHERE" || word == "HAVING":
+		return p.conditions(body, j, depth, header+" ")
+	}
+	return p.expr(body, j, depth, header+" ")
+}
+
+// split splits tokens i to j at commas outside of parentheses.
+func (p *printer) split(i, j int) [][2]int {
+	var items [][2]int
+	start := i
+	for k := i; k < j; k = p.next(k) {
+		if p.isComma(k) {
+			items = append(items, [2]int{start, k})
+			start = k + 1
+		}
+	}
+	return append(items, [2]int{start, j})
+}
+
+// list returns the lines of list items, one per line.
+func (p *printer) list(items [][2]int, depth int) []string {
+	var lines []string
+	for n, item := range items {
+		lines = append(lines, p.separate(p.expr(item[0], item[1], depth, p.itemPrefix(n)), n, len(items))...)
+	}
+	return lines
+}
+
+// itemPrefix returns the prefix of the first line of list item n.
+func (p *printer) itemPrefix(n int) string {
+	switch {
+	case !p.opts.CommasFirst:
+		return ""
+	case n == 0:
+		return "  "
+	default:
+		return ", "
+	}
+}
+
+// separate adds the separating comma to the lines of list item n of count
+// items when commas trail items.
+func (p *printer) separate(lines []string, n, count int) []string {
+	if !p.opts.CommasFirst && n < count-1 {
+		lines[len(lines)-1] += ","
+	}
+	return lines
+}
+
+// joins returns the lines of a FROM item, with each join on its own line.
+func (p *printer) joins(i, j, depth int, prefix string) []string {
+	if line := p.indent(depth) + prefix + p.text(i, j); p.fits(line) {
+		return []string{line}
+	}
+	var starts []int
+	for k := i; k < j; k = p.next(k) {
+		if p.words[k] != "JOIN" {
+			continue
+		}
+		start := k
+		for start > i && joinWords[p.words[start-1]] {
+			start--
+		}
+		starts = append(starts, start)
+	}
+
+	lines := p.expr(i, firstOr(starts, j), depth, prefix)
+	for n, start := range starts {
+		end := j
+		if n+1 < len(starts) {
+			end = starts[n+1]
+		}
+		lines = append(lines, p.join(start, end, depth+1)...)
+	}
+	return lines
+}
+
+// join returns the lines of a join, with a join condition that does not fit
+// on a line of its own.
+func (p *printer) join(i, j, depth int) []string {
+	if line := p.indent(depth) + p.text(i, j); p.fits(line) {
+		return []string{line}
+	}
+	for k := i; k < j; k = p.next(k) {
+		if p.words[k] == "ON" {
+			lines := p.expr(i, k, depth, "")
+			return append(lines, p.conditions(k+1, j, depth+1, p.text(k, k+1)+" ")...)
+		}
+	}
+	return p.expr(i, j, depth, "")
+}
+
+func firstOr(s []int, def int) int {
+	if len(s) == 0 {
+		return def
+	}
+	return s[0]
+}
+
+// conditions returns the lines of a condition, with each operand of a top-level
+// AND or OR on its own line.
+func (p *printer) conditions(i, j, depth int, prefix string) []string {
+	if line := p.indent(depth) + prefix + p.text(i, j); p.fits(line) {
+		return []string{line}
+	}
+	var starts []int
+	between := false
+	for k := i; k < j; k = p.next(k) {
+		switch p.words[k] {
+		case "BETWEEN":
+			between = true
+		case "AND":
+			if between {
+				between = false
+				continue
+			}
+			starts = append(starts, k)
+		case "OR":
+			starts = append(starts, k)
+		}
+	}
+
+	lines := p.expr(i, firstOr(starts, j), depth, prefix)
+	for n, start := range starts {
+		end := j
+		if n+1 < len(starts) {
+			end = starts[n+1]
+		}
+		lines = append(lines, p.expr(start, end, depth+1, "")...)
+	}
+	return lines
+}
+
+// expr returns the lines of tokens i to j starting on a line with prefix,
+// expanding parenthesized queries and lists that do not fit onto lines of
+// their own.
+func (p *printer) expr(i, j, depth int, prefix string) []string {
+	line := p.indent(depth) + prefix
+	if p.fits(line + p.text(i, j)) {
+		return []string{line + p.text(i, j)}
+	}
+
+	var lines []string
+	fresh := true
+	for k := i; k < j; {
+		if !fresh {
+			line += p.src[p.tokens[k-1].GetEnd():p.tokens[k].GetStart()]
+		}
+		fresh = false
+
+		if !p.isOpen(k) || p.match[k] >= j {
+			line += p.text(k, k+1)
+			k++
+			continue
+		}
+
+		m := p.match[k]
+		if p.fits(line + p.text(k, m+1)) {
+			line += p.text(k, m+1)
+			k = m + 1
+			continue
+		}
+
+		inner := p.split(k+1, m)
+		switch {
+		case k+1 < m && queryStarts[p.words[k+1]]:
+			lines = append(lines, line+p.text(k, k+1))
+			lines = append(lines, p.query(k+1, m, depth+1)...)
+		case len(inner) > 1:
+			lines = append(lines, line+p.text(k, k+1))
+			lines = append(lines, p.list(inner, depth+1)...)
+		default:
+			line += p.text(k, m+1)
+			k = m + 1
+			continue
+		}
+		line = p.indent(depth) + p.text(m, m+1)
+		k = m + 1
+	}
+	return append(lines, line)
+}
@@ -0,0 +1,43 @@
+//go:build ignore
+
+// gen_keywords generates keywords_table.go from the keyword list of Postgres.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"regexp"
+)
+
+const kwlistPath = "internal/cparser/include/postgres/parser/kwlist.h"
+
+var keywordRe = regexp.MustCompile(`(?m)^PG_KEYWORD\("(\w+)", (\w+), (\w+), (\w+)\)`)
+
+func main() {
+	src, err := os.ReadFile(kwlistPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen_keywords.go from Postgres's kwlist.h. DO NOT EDIT.\n\n")
+	buf.WriteString("package pg_query //nolint:revive // Keep package name aligned with existing public API.\n\n")
+	buf.WriteString("import pganalyze \"github.com/pganalyze/pg_query_go/v6\"\n\n")
+	buf.WriteString("// keywords are the keywords of Postgres in ASCII order.\n")
+	buf.WriteString("var keywords = []Keyword{\n")
+	for _, m := range keywordRe.FindAllStringSubmatch(string(src), -1) {
+		fmt.Fprintf(&buf, "{%q, pganalyze.Token_%s, pganalyze.KeywordKind_%s, %t},\n", m[1], m[2], m[3], m[4] == "BARE_LABEL")
+	}
+	buf.WriteString("}\n")
+
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("keywords_table.go", out, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,222 @@
+// Package highlight renders SQL with syntax highlighting for terminals and
+// HTML, classifying the tokens returned by pg_query.Scan.
+//
+// The input is kept exactly, including whitespace and comments, with only
+// styling added. Input that cannot be scanned, such as SQL with an unterminated
+// string, is rendered as plain text:
+//
+//	fmt.Println(highlight.HTML("SELECT 1 -- one"))
+//	// <span class="sql-keyword">SELECT</span> <span class="sql-number">1</span> <span class="sql-comment">-- one</span>
+package highlight
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+)
+
+// Category is the kind of a token for highlighting.
+type Category int
+
+const (
+	// CategoryText is whitespace and any text that is not part of a token.
+	CategoryText Category = iota
+	// CategoryKeyword is a keyword, reserved or not.
+	CategoryKeyword
+	// CategoryIdentifier is an identifier that is not a keyword.
+	CategoryIdentifier
+	// CategoryString is a string or bit string constant.
+	CategoryString
+	// CategoryNumber is a numeric constant.
+	CategoryNumber
+	// CategoryOperator is an operator, including the :: of casts.
+	CategoryOperator
+	// CategoryPunctuation is a parenthesis, bracket, comma, semicolon, period
+	// or colon.
+	CategoryPunctuation
+	// CategoryComment is a comment.
+	CategoryComment
+	// CategoryParameter is a parameter reference such as $1.
+	CategoryParameter
+)
+
+// categories are the categories in order, for listing them.
+var categories = []Category{
+	CategoryText, CategoryKeyword, CategoryIdentifier, CategoryString, CategoryNumber,
+	CategoryOperator, CategoryPunctuation, CategoryComment, CategoryParameter,
+}
+
+// String returns the name of c, which is also used in the CSS classes of HTML.
+func (c Category) String() string {
+	switch c {
+	case CategoryKeyword:
+		return "keyword"
+	case CategoryIdentifier:
+		return "identifier"
+	case CategoryString:
+		return "string"
+	case CategoryNumber:
+		return "number"
+	case CategoryOperator:
+		return "operator"
+	case CategoryPunctuation:
+		return "punctuation"
+	case CategoryComment:
+		return "comment"
+	case CategoryParameter:
+		return "parameter"
+	case CategoryText:
+	}
+	return "text"
+}
+
+// Class returns the CSS class of c in HTML, such as "sql-keyword".
+func (c Category) Class() string {
+	return "sql-" + c.String()
+}
+
+// Segment is a part of the input of a single category.
+type Segment struct {
+	// Start and End are the byte offsets of the segment in the input.
+	Start, End int
+	// Category is the category of the segment.
+	Category Category
+}
+
+// Classify splits sql into consecutive segments covering all of it, with
+// tokens in their category and the text between them in CategoryText.
+func Classify(sql string) ([]Segment, error) {
+	scan, err := pg_query.Scan(sql)
+	if err != nil {
+		return nil, fmt.Errorf("highlight: scanning input: %w", err)
+	}
+	var segments []Segment
+	last := 0
+	for _, t := range scan.GetTokens() {
+		start, end := int(t.GetStart()), int(t.GetEnd())
+		if start < last || end > len(sql) {
+			continue
+		}
+		// Unicode escape strings are scanned with following whitespace.
+		end = max(start, len(strings.TrimRight(sql[:end], " \t\n\r\f\v")))
+		if start > last {
+			segments = append(segments, Segment{Start: last, End: start, Category: CategoryText})
+		}
+		segments = append(segments, Segment{Start: start, End: end, Category: category(t)})
+		last = end
+	}
+	if last < len(sql) {
+		segments = append(segments, Segment{Start: last, End: len(sql), Category: CategoryText})
+	}
+	return segments, nil
+}
+
+func category(t *pganalyze.ScanToken) Category {
+	if t.GetKeywordKind() != pganalyze.KeywordKind_NO_KEYWORD {
+		return CategoryKeyword
+	}
+	switch t.GetToken() { //nolint:exhaustive // Keywords are handled above.
+	case pganalyze.Token_IDENT, pganalyze.Token_UIDENT:
+		return CategoryIdentifier
+	case pganalyze.Token_SCONST, pganalyze.Token_USCONST, pganalyze.Token_BCONST, pganalyze.Token_XCONST:
+		return CategoryString
+	case pganalyze.Token_ICONST, pganalyze.Token_FCONST:
+		return CategoryNumber
+	case pganalyze.Token_SQL_COMMENT, pganalyze.Token_C_COMMENT:
+		return CategoryComment
+	case pganalyze.Token_PARAM:
+		return CategoryParameter
+	case pganalyze.Token_ASCII_40, pganalyze.Token_ASCII_41, pganalyze.Token_ASCII_44, pganalyze.Token_ASCII_46,
+		pganalyze.Token_ASCII_58, pganalyze.Token_ASCII_59, pganalyze.Token_ASCII_91, pganalyze.Token_ASCII_93:
+		return CategoryPunctuation
+	case pganalyze.Token_Op, pganalyze.Token_TYPECAST, pganalyze.Token_DOT_DOT, pganalyze.Token_LESS_EQUALS,
+		pganalyze.Token_GREATER_EQUALS, pganalyze.Token_NOT_EQUALS, pganalyze.Token_COLON_EQUALS,
+		pganalyze.Token_EQUALS_GREATER, pganalyze.Token_ASCII_37, pganalyze.Token_ASCII_42, pganalyze.Token_ASCII_43,
+		pganalyze.Token_ASCII_45, pganalyze.Token_ASCII_47, pganalyze.Token_ASCII_60, pganalyze.Token_ASCII_61,
+		pganalyze.Token_ASCII_62, pganalyze.Token_ASCII_94:
+		return CategoryOperator
+	}
+	return CategoryText
+}
+
+// Style is the styling of a category.
+type Style struct {
+	// ANSI are the parameters of the ANSI escape sequence selecting the
+	// terminal style, such as "1;34" for bold blue.
+	ANSI string
+	// CSS are the declarations of the CSS rule of the class of the category,
+	// such as "color: #00f; font-weight: bold".
+	CSS string
+}
+
+// Theme maps categories to their style. Categories without a style are not
+// styled.
+type Theme map[Category]Style
+
+// DefaultTheme is a theme using the basic colors of terminals.
+var DefaultTheme = Theme{
+	CategoryKeyword:   {ANSI: "1;34", CSS: "color: #0000aa; font-weight: bold"},
+	CategoryString:    {ANSI: "32", CSS: "color: #00aa00"},
+	CategoryNumber:    {ANSI: "36", CSS: "color: #00aaaa"},
+	CategoryOperator:  {ANSI: "33", CSS: "color: #aa5500"},
+	CategoryComment:   {ANSI: "2;3", CSS: "color: #808080; font-style: italic"},
+	CategoryParameter: {ANSI: "35", CSS: "color: #aa00aa"},
+}
+
+// CSS returns a style sheet with a rule for the class of each category with
+// a style, for use with HTML.
+func (t Theme) CSS() string {
+	var b strings.Builder
+	for _, c := range categories {
+		if s := t[c].CSS; s != "" {
+			fmt.Fprintf(&b, ".%s { %s }\n", c.Class(), s)
+		}
+	}
+	return b.String()
+}
+
+// ANSI returns sql with ANSI escape sequences styling its tokens with theme.
+// Each styled token is followed by a reset of the style.
+func ANSI(sql string, theme Theme) string {
+	var b strings.Builder
+	for _, s := range segments(sql) {
+		text := sql[s.Start:s.End]
+		style := theme[s.Category].ANSI
+		if style == "" {
+			b.WriteString(text)
+			continue
+		}
+		b.WriteString("\x1b[" + style + "m" + text + "\x1b[0m")
+	}
+	return b.String()
+}
+
+// HTML returns sql escaped as HTML, with tokens other than CategoryText in
+// span elements with the class of their category. The result is usually
+// placed in a pre element, with the rules of Theme.CSS.
+func HTML(sql string) string {
+	var b strings.Builder
+	for _, s := range segments(sql) {
+		text := html.EscapeString(sql[s.Start:s.End])
+		if s.Category == CategoryText {
+			b.WriteString(text)
+			continue
+		}
+		b.WriteString(`<span class="` + s.Category.Class() + `">` + text + "</span>")
+	}
+	return b.String()
+}
+
+// segments returns the segments of sql, or a single segment of text if it
+// cannot be scanned.
+func segments(sql string) []Segment {
+	segs, err := Classify(sql)
+	if err != nil {
+		return []Segment{{Start: 0, End: len(sql), Category: CategoryText}}
+	}
+	return segs
+}
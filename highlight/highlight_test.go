@@ -0,0 +1,138 @@
+package highlight_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/wasilibs/go-pgquery/highlight"
+)
+
+func TestClassify(t *testing.T) {
+	sql := "SELECT a.b::int, $1 + 2.5 -- c\nFROM t WHERE x <> U&'z' UESCAPE '!';"
+	segments, err := highlight.Classify(sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got [][2]string
+	for _, s := range segments {
+		got = append(got, [2]string{s.Category.String(), sql[s.Start:s.End]})
+	}
+	want := [][2]string{
+		{"keyword", "SELECT"},
+		{"text", " "},
+		{"identifier", "a"},
+		{"punctuation", "."},
+		{"identifier", "b"},
+		{"operator", "::"},
+		{"keyword", "int"},
+		{"punctuation", ","},
+		{"text", " "},
+		{"parameter", "$1"},
+		{"text", " "},
+		{"operator", "+"},
+		{"text", " "},
+		{"number", "2.5"},
+		{"text", " "},
+		{"comment", "-- c"},
+		{"text", "\n"},
+		{"keyword", "FROM"},
+		{"text", " "},
+		{"identifier", "t"},
+		{"text", " "},
+		{"keyword", "WHERE"},
+		{"text", " "},
+		{"identifier", "x"},
+		{"text", " "},
+		{"operator", "<>"},
+		{"text", " "},
+		{"string", "U&'z'"},
+		{"text", " "},
+		{"keyword", "UESCAPE"},
+		{"text", " "},
+		{"string", "'!'"},
+		{"punctuation", ";"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("segments mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestANSI(t *testing.T) {
+	tests := []struct {
+		name  string
+		sql   string
+		theme highlight.Theme
+		want  string
+	}{
+		{
+			name:  "default theme",
+			sql:   "select 'a' /* b */ , 1",
+			theme: highlight.DefaultTheme,
+			want:  "\x1b[1;34mselect\x1b[0m \x1b[32m'a'\x1b[0m \x1b[2;3m/* b */\x1b[0m , \x1b[36m1\x1b[0m",
+		},
+		{
+			name:  "custom theme",
+			sql:   "SELECT x\n\tFROM t",
+			theme: highlight.Theme{highlight.CategoryIdentifier: {ANSI: "4"}},
+			want:  "SELECT \x1b[4mx\x1b[0m\n\tFROM \x1b[4mt\x1b[0m",
+		},
+		{
+			name:  "scan error",
+			sql:   "SELECT 'unterminated",
+			theme: highlight.DefaultTheme,
+			want:  "SELECT 'unterminated",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := highlight.ANSI(tc.sql, tc.theme); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{
+			name: "tokens",
+			sql:  "SELECT 1 -- one",
+			want: `<span class="sql-keyword">SELECT</span> <span class="sql-number">1</span> <span class="sql-comment">-- one</span>`,
+		},
+		{
+			name: "escaping",
+			sql:  "SELECT '<b>' & x",
+			want: `<span class="sql-keyword">SELECT</span> <span class="sql-string">&#39;&lt;b&gt;&#39;</span> <span class="sql-operator">&amp;</span> <span class="sql-identifier">x</span>`,
+		},
+		{
+			name: "scan error",
+			sql:  "SELECT \"<unterminated",
+			want: "SELECT &#34;&lt;unterminated",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := highlight.HTML(tc.sql); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCSS(t *testing.T) {
+	theme := highlight.Theme{
+		highlight.CategoryComment: {CSS: "color: gray"},
+		highlight.CategoryKeyword: {ANSI: "1", CSS: "font-weight: bold"},
+		highlight.CategoryString:  {ANSI: "32"},
+	}
+	want := ".sql-keyword { font-weight: bold }\n.sql-comment { color: gray }\n"
+	if got := theme.CSS(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
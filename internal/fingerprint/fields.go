@@ -0,0 +1,526 @@
+// Code generated by gen.go from libpg_query's pg_query_fingerprint_defs.c. DO NOT EDIT.
+
+package fingerprint
+
+// nodeFields are the fingerprinted fields of node types by their names in
+// Postgres, in the order they are fingerprinted.
+var nodeFields = map[string][]field{
+	"A_ArrayExpr":                  {{"elements", kindList}},
+	"A_Expr":                       {{"kind", kindEnum}, {"lexpr", kindNode}, {"name", kindList}, {"rexpr", kindNode}},
+	"A_Indices":                    {{"is_slice", kindBool}, {"lidx", kindNode}, {"uidx", kindNode}},
+	"A_Indirection":                {{"arg", kindNode}, {"indirection", kindList}},
+	"A_Star":                       {},
+	"AccessPriv":                   {{"cols", kindList}, {"priv_name", kindString}},
+	"Aggref":                       {{"aggargtypes", kindList}, {"aggcollid", kindInt}, {"aggdirectargs", kindList}, {"aggdistinct", kindList}, {"aggfilter", kindNode}, {"aggfnoid", kindInt}, {"aggkind", kindChar}, {"agglevelsup", kindInt}, {"aggno", kindInt}, {"aggorder", kindList}, {"aggsplit", kindEnum}, {"aggstar", kindBool}, {"aggtransno", kindInt}, {"aggtype", kindInt}, {"aggvariadic", kindBool}, {"args", kindList}, {"inputcollid", kindInt}},
+	"Alias":                        {},
+	"AlterCollationStmt":           {{"collname", kindList}},
+	"AlterDatabaseRefreshCollStmt": {{"dbname", kindString}},
+	"AlterDatabaseSetStmt":         {{"dbname", kindString}, {"setstmt", kindStruct}},
+	"AlterDatabaseStmt":            {{"dbname", kindString}, {"options", kindList}},
+	"AlterDefaultPrivilegesStmt":   {{"action", kindStruct}, {"options", kindList}},
+	"AlterDomainStmt":              {{"behavior", kindEnum}, {"def", kindNode}, {"missing_ok", kindBool}, {"name", kindString}, {"subtype", kindChar}, {"typeName", kindList}},
+	"AlterEnumStmt":                {{"newVal", kindString}, {"newValIsAfter", kindBool}, {"newValNeighbor", kindString}, {"oldVal", kindString}, {"skipIfNewValExists", kindBool}, {"typeName", kindList}},
+	"AlterEventTrigStmt":           {{"tgenabled", kindChar}, {"trigname", kindString}},
+	"AlterExtensionContentsStmt":   {{"action", kindInt}, {"extname", kindString}, {"object", kindNode}, {"objtype", kindEnum}},
+	"AlterExtensionStmt":           {{"extname", kindString}, {"options", kindList}},
+	"AlterFdwStmt":                 {{"fdwname", kindString}, {"func_options", kindList}, {"options", kindList}},
+	"AlterForeignServerStmt":       {{"has_version", kindBool}, {"options", kindList}, {"servername", kindString}, {"version", kindString}},
+	"AlterFunctionStmt":            {{"actions", kindList}, {"func", kindStruct}, {"objtype", kindEnum}},
+	"AlterObjectDependsStmt":       {{"extname", kindStringNode}, {"object", kindNode}, {"objectType", kindEnum}, {"relation", kindStruct}, {"remove", kindBool}},
+	"AlterObjectSchemaStmt":        {{"missing_ok", kindBool}, {"newschema", kindString}, {"object", kindNode}, {"objectType", kindEnum}, {"relation", kindStruct}},
+	"AlterOpFamilyStmt":            {{"amname", kindString}, {"isDrop", kindBool}, {"items", kindList}, {"opfamilyname", kindList}},
+	"AlterOperatorStmt":            {{"opername", kindStruct}, {"options", kindList}},
+	"AlterOwnerStmt":               {{"newowner", kindStruct}, {"object", kindNode}, {"objectType", kindEnum}, {"relation", kindStruct}},
+	"AlterPolicyStmt":              {{"policy_name", kindString}, {"qual", kindNode}, {"roles", kindList}, {"table", kindStruct}, {"with_check", kindNode}},
+	"AlterPublicationStmt":         {{"action", kindEnum}, {"for_all_tables", kindBool}, {"options", kindList}, {"pubname", kindString}, {"pubobjects", kindList}},
+	"AlterRoleSetStmt":             {{"database", kindString}, {"role", kindStruct}, {"setstmt", kindStruct}},
+	"AlterRoleStmt":                {{"action", kindInt}, {"options", kindList}, {"role", kindStruct}},
+	"AlterSeqStmt":                 {{"for_identity", kindBool}, {"missing_ok", kindBool}, {"options", kindList}, {"sequence", kindStruct}},
+	"AlterStatsStmt":               {{"defnames", kindList}, {"missing_ok", kindBool}, {"stxstattarget", kindNode}},
+	"AlterSubscriptionStmt":        {{"conninfo", kindString}, {"kind", kindEnum}, {"options", kindList}, {"publication", kindList}, {"subname", kindString}},
+	"AlterSystemStmt":              {{"setstmt", kindStruct}},
+	"AlterTSConfigurationStmt":     {{"cfgname", kindList}, {"dicts", kindList}, {"kind", kindEnum}, {"missing_ok", kindBool}, {"override", kindBool}, {"replace", kindBool}, {"tokentype", kindList}},
+	"AlterTSDictionaryStmt":        {{"dictname", kindList}, {"options", kindList}},
+	"AlterTableCmd":                {{"behavior", kindEnum}, {"def", kindNode}, {"missing_ok", kindBool}, {"name", kindString}, {"newowner", kindStruct}, {"num", kindInt}, {"recurse", kindBool}, {"subtype", kindEnum}},
+	"AlterTableMoveAllStmt":        {{"new_tablespacename", kindString}, {"nowait", kindBool}, {"objtype", kindEnum}, {"orig_tablespacename", kindString}, {"roles", kindList}},
+	"AlterTableSpaceOptionsStmt":   {{"isReset", kindBool}, {"options", kindList}, {"tablespacename", kindString}},
+	"AlterTableStmt":               {{"cmds", kindList}, {"missing_ok", kindBool}, {"objtype", kindEnum}, {"relation", kindStruct}},
+	"AlterTypeStmt":                {{"options", kindList}, {"typeName", kindList}},
+	"AlterUserMappingStmt":         {{"options", kindList}, {"servername", kindString}, {"user", kindStruct}},
+	"AlternativeSubPlan":           {{"subplans", kindList}},
+	"ArrayCoerceExpr":              {{"arg", kindNode}, {"coerceformat", kindEnum}, {"elemexpr", kindNode}, {"resultcollid", kindInt}, {"resulttype", kindInt}, {"resulttypmod", kindInt}},
+	"ArrayExpr":                    {{"array_collid", kindInt}, {"array_typeid", kindInt}, {"element_typeid", kindInt}, {"elements", kindList}, {"multidims", kindBool}},
+	"BoolExpr":                     {{"args", kindList}, {"boolop", kindEnum}},
+	"BooleanTest":                  {{"arg", kindNode}, {"booltesttype", kindEnum}},
+	"CTECycleClause":               {{"cycle_col_list", kindList}, {"cycle_mark_collation", kindInt}, {"cycle_mark_column", kindString}, {"cycle_mark_default", kindNode}, {"cycle_mark_neop", kindInt}, {"cycle_mark_type", kindInt}, {"cycle_mark_typmod", kindInt}, {"cycle_mark_value", kindNode}, {"cycle_path_column", kindString}},
+	"CTESearchClause":              {{"search_breadth_first", kindBool}, {"search_col_list", kindList}, {"search_seq_column", kindString}},
+	"CallContext":                  {{"atomic", kindBool}},
+	"CallStmt":                     {{"funccall", kindStruct}, {"funcexpr", kindStruct}, {"outargs", kindList}},
+	"CaseExpr":                     {{"arg", kindNode}, {"args", kindList}, {"casecollid", kindInt}, {"casetype", kindInt}, {"defresult", kindNode}},
+	"CaseTestExpr":                 {{"collation", kindInt}, {"typeId", kindInt}, {"typeMod", kindInt}},
+	"CaseWhen":                     {{"expr", kindNode}, {"result", kindNode}},
+	"CheckPointStmt":               {},
+	"ClosePortalStmt":              {},
+	"ClusterStmt":                  {{"indexname", kindString}, {"params", kindList}, {"relation", kindStruct}},
+	"CoalesceExpr":                 {{"args", kindList}, {"coalescecollid", kindInt}, {"coalescetype", kindInt}},
+	"CoerceToDomain":               {{"arg", kindNode}, {"coercionformat", kindEnum}, {"resultcollid", kindInt}, {"resulttype", kindInt}, {"resulttypmod", kindInt}},
+	"CoerceToDomainValue":          {{"collation", kindInt}, {"typeId", kindInt}, {"typeMod", kindInt}},
+	"CoerceViaIO":                  {{"arg", kindNode}, {"coerceformat", kindEnum}, {"resultcollid", kindInt}, {"resulttype", kindInt}},
+	"CollateClause":                {{"arg", kindNode}, {"collname", kindList}},
+	"CollateExpr":                  {{"arg", kindNode}, {"collOid", kindInt}},
+	"ColumnDef":                    {{"collClause", kindStruct}, {"collOid", kindInt}, {"colname", kindString}, {"compression", kindString}, {"constraints", kindList}, {"cooked_default", kindNode}, {"fdwoptions", kindList}, {"generated", kindChar}, {"identity", kindChar}, {"identitySequence", kindStruct}, {"inhcount", kindInt}, {"is_from_type", kindBool}, {"is_local", kindBool}, {"is_not_null", kindBool}, {"raw_default", kindNode}, {"storage", kindChar}, {"storage_name", kindString}, {"typeName", kindStruct}},
+	"ColumnRef":                    {{"fields", kindList}},
+	"CommentStmt":                  {{"comment", kindString}, {"object", kindNode}, {"objtype", kindEnum}},
+	"CommonTableExpr":              {{"aliascolnames", kindList}, {"ctecolcollations", kindList}, {"ctecolnames", kindList}, {"ctecoltypes", kindList}, {"ctecoltypmods", kindList}, {"ctematerialized", kindEnum}, {"ctename", kindString}, {"ctequery", kindNode}, {"cterecursive", kindBool}, {"cterefcount", kindInt}, {"cycle_clause", kindStruct}, {"search_clause", kindStruct}},
+	"CompositeTypeStmt":            {{"coldeflist", kindList}, {"typevar", kindStruct}},
+	"Const":                        {{"constbyval", kindBool}, {"constcollid", kindInt}, {"constisnull", kindBool}, {"constlen", kindInt}, {"consttype", kindInt}, {"consttypmod", kindInt}},
+	"Constraint":                   {{"access_method", kindString}, {"conname", kindString}, {"contype", kindEnum}, {"cooked_expr", kindString}, {"deferrable", kindBool}, {"exclusions", kindList}, {"fk_attrs", kindList}, {"fk_del_action", kindChar}, {"fk_del_set_cols", kindList}, {"fk_matchtype", kindChar}, {"fk_upd_action", kindChar}, {"generated_when", kindChar}, {"including", kindList}, {"indexname", kindString}, {"indexspace", kindString}, {"inhcount", kindInt}, {"initdeferred", kindBool}, {"initially_valid", kindBool}, {"is_no_inherit", kindBool}, {"keys", kindList}, {"nulls_not_distinct", kindBool}, {"old_conpfeqop", kindList}, {"old_pktable_oid", kindInt}, {"options", kindList}, {"pk_attrs", kindList}, {"pktable", kindStruct}, {"raw_expr", kindNode}, {"reset_default_tblspc", kindBool}, {"skip_validation", kindBool}, {"where_clause", kindNode}},
+	"ConstraintsSetStmt":           {{"constraints", kindList}, {"deferred", kindBool}},
+	"ConvertRowtypeExpr":           {{"arg", kindNode}, {"convertformat", kindEnum}, {"resulttype", kindInt}},
+	"CopyStmt":                     {{"attlist", kindList}, {"filename", kindString}, {"is_from", kindBool}, {"is_program", kindBool}, {"options", kindList}, {"query", kindNode}, {"relation", kindStruct}, {"whereClause", kindNode}},
+	"CreateAmStmt":                 {{"amname", kindString}, {"amtype", kindChar}, {"handler_name", kindList}},
+	"CreateCastStmt":               {{"context", kindEnum}, {"func", kindStruct}, {"inout", kindBool}, {"sourcetype", kindStruct}, {"targettype", kindStruct}},
+	"CreateConversionStmt":         {{"conversion_name", kindList}, {"def", kindBool}, {"for_encoding_name", kindString}, {"func_name", kindList}, {"to_encoding_name", kindString}},
+	"CreateDomainStmt":             {{"collClause", kindStruct}, {"constraints", kindList}, {"domainname", kindList}, {"typeName", kindStruct}},
+	"CreateEnumStmt":               {{"typeName", kindList}, {"vals", kindList}},
+	"CreateEventTrigStmt":          {{"eventname", kindString}, {"funcname", kindList}, {"trigname", kindString}, {"whenclause", kindList}},
+	"CreateExtensionStmt":          {{"extname", kindString}, {"if_not_exists", kindBool}, {"options", kindList}},
+	"CreateFdwStmt":                {{"fdwname", kindString}, {"func_options", kindList}, {"options", kindList}},
+	"CreateForeignServerStmt":      {{"fdwname", kindString}, {"if_not_exists", kindBool}, {"options", kindList}, {"servername", kindString}, {"servertype", kindString}, {"version", kindString}},
+	"CreateForeignTableStmt":       {{"base", kindInline}, {"options", kindList}, {"servername", kindString}},
+	"CreateFunctionStmt":           {{"funcname", kindList}, {"is_procedure", kindBool}, {"parameters", kindList}, {"replace", kindBool}, {"returnType", kindStruct}, {"sql_body", kindNode}},
+	"CreateOpClassItem":            {{"class_args", kindList}, {"itemtype", kindInt}, {"name", kindStruct}, {"number", kindInt}, {"order_family", kindList}, {"storedtype", kindStruct}},
+	"CreateOpClassStmt":            {{"amname", kindString}, {"datatype", kindStruct}, {"isDefault", kindBool}, {"items", kindList}, {"opclassname", kindList}, {"opfamilyname", kindList}},
+	"CreateOpFamilyStmt":           {{"amname", kindString}, {"opfamilyname", kindList}},
+	"CreatePLangStmt":              {{"plhandler", kindList}, {"plinline", kindList}, {"plname", kindString}, {"pltrusted", kindBool}, {"plvalidator", kindList}, {"replace", kindBool}},
+	"CreatePolicyStmt":             {{"cmd_name", kindString}, {"permissive", kindBool}, {"policy_name", kindString}, {"qual", kindNode}, {"roles", kindList}, {"table", kindStruct}, {"with_check", kindNode}},
+	"CreatePublicationStmt":        {{"for_all_tables", kindBool}, {"options", kindList}, {"pubname", kindString}, {"pubobjects", kindList}},
+	"CreateRangeStmt":              {{"params", kindList}, {"typeName", kindList}},
+	"CreateRoleStmt":               {{"options", kindList}, {"role", kindString}, {"stmt_type", kindEnum}},
+	"CreateSchemaStmt":             {{"authrole", kindStruct}, {"if_not_exists", kindBool}, {"schemaElts", kindList}, {"schemaname", kindString}},
+	"CreateSeqStmt":                {{"for_identity", kindBool}, {"if_not_exists", kindBool}, {"options", kindList}, {"ownerId", kindInt}, {"sequence", kindStruct}},
+	"CreateStatsStmt":              {{"defnames", kindList}, {"exprs", kindList}, {"if_not_exists", kindBool}, {"relations", kindList}, {"stat_types", kindList}, {"stxcomment", kindString}, {"transformed", kindBool}},
+	"CreateStmt":                   {{"accessMethod", kindString}, {"constraints", kindList}, {"if_not_exists", kindBool}, {"inhRelations", kindList}, {"ofTypename", kindStruct}, {"oncommit", kindEnum}, {"options", kindList}, {"partbound", kindStruct}, {"partspec", kindStruct}, {"relation", kindStruct}, {"tableElts", kindList}, {"tablespacename", kindString}},
+	"CreateSubscriptionStmt":       {{"conninfo", kindString}, {"options", kindList}, {"publication", kindList}, {"subname", kindString}},
+	"CreateTableAsStmt":            {{"if_not_exists", kindBool}, {"into", kindStruct}, {"is_select_into", kindBool}, {"objtype", kindEnum}, {"query", kindNode}},
+	"CreateTableSpaceStmt":         {{"options", kindList}, {"owner", kindStruct}, {"tablespacename", kindString}},
+	"CreateTransformStmt":          {{"fromsql", kindStruct}, {"lang", kindString}, {"replace", kindBool}, {"tosql", kindStruct}, {"type_name", kindStruct}},
+	"CreateTrigStmt":               {{"args", kindList}, {"columns", kindList}, {"constrrel", kindStruct}, {"deferrable", kindBool}, {"events", kindInt}, {"funcname", kindList}, {"initdeferred", kindBool}, {"isconstraint", kindBool}, {"relation", kindStruct}, {"replace", kindBool}, {"row", kindBool}, {"timing", kindInt}, {"transitionRels", kindList}, {"trigname", kindString}, {"whenClause", kindNode}},
+	"CreateUserMappingStmt":        {{"if_not_exists", kindBool}, {"options", kindList}, {"servername", kindString}, {"user", kindStruct}},
+	"CreatedbStmt":                 {{"dbname", kindString}, {"options", kindList}},
+	"CurrentOfExpr":                {{"cursor_name", kindString}, {"cursor_param", kindInt}, {"cvarno", kindInt}},
+	"DeallocateStmt":               {{"isall", kindBool}},
+	"DeclareCursorStmt":            {{"options", kindInt}, {"query", kindNode}},
+	"DefElem":                      {{"arg", kindNode}, {"defaction", kindEnum}, {"defname", kindString}, {"defnamespace", kindString}},
+	"DefineStmt":                   {{"args", kindList}, {"definition", kindList}, {"defnames", kindList}, {"if_not_exists", kindBool}, {"kind", kindEnum}, {"oldstyle", kindBool}, {"replace", kindBool}},
+	"DeleteStmt":                   {{"relation", kindStruct}, {"returningList", kindList}, {"usingClause", kindList}, {"whereClause", kindNode}, {"withClause", kindStruct}},
+	"DiscardStmt":                  {{"target", kindEnum}},
+	"DoStmt":                       {},
+	"DropOwnedStmt":                {{"behavior", kindEnum}, {"roles", kindList}},
+	"DropRoleStmt":                 {{"missing_ok", kindBool}, {"roles", kindList}},
+	"DropStmt":                     {{"behavior", kindEnum}, {"concurrent", kindBool}, {"missing_ok", kindBool}, {"objects", kindList}, {"removeType", kindEnum}},
+	"DropSubscriptionStmt":         {{"behavior", kindEnum}, {"missing_ok", kindBool}, {"subname", kindString}},
+	"DropTableSpaceStmt":           {{"missing_ok", kindBool}, {"tablespacename", kindString}},
+	"DropUserMappingStmt":          {{"missing_ok", kindBool}, {"servername", kindString}, {"user", kindStruct}},
+	"DropdbStmt":                   {{"dbname", kindString}, {"missing_ok", kindBool}, {"options", kindList}},
+	"ExecuteStmt":                  {{"params", kindList}},
+	"ExplainStmt":                  {{"options", kindList}, {"query", kindNode}},
+	"FetchStmt":                    {{"direction", kindEnum}, {"howMany", kindInt}, {"ismove", kindBool}},
+	"FieldSelect":                  {{"arg", kindNode}, {"fieldnum", kindInt}, {"resultcollid", kindInt}, {"resulttype", kindInt}, {"resulttypmod", kindInt}},
+	"FieldStore":                   {{"arg", kindNode}, {"fieldnums", kindList}, {"newvals", kindList}, {"resulttype", kindInt}},
+	"FromExpr":                     {{"fromlist", kindList}, {"quals", kindNode}},
+	"FuncCall":                     {{"agg_distinct", kindBool}, {"agg_filter", kindNode}, {"agg_order", kindList}, {"agg_star", kindBool}, {"agg_within_group", kindBool}, {"args", kindList}, {"func_variadic", kindBool}, {"funcformat", kindEnum}, {"funcname", kindList}, {"over", kindStruct}},
+	"FuncExpr":                     {{"args", kindList}, {"funccollid", kindInt}, {"funcformat", kindEnum}, {"funcid", kindInt}, {"funcresulttype", kindInt}, {"funcretset", kindBool}, {"funcvariadic", kindBool}, {"inputcollid", kindInt}},
+	"FunctionParameter":            {{"argType", kindStruct}, {"defexpr", kindNode}, {"mode", kindEnum}},
+	"GrantRoleStmt":                {{"behavior", kindEnum}, {"granted_roles", kindList}, {"grantee_roles", kindList}, {"grantor", kindStruct}, {"is_grant", kindBool}, {"opt", kindList}},
+	"GrantStmt":                    {{"behavior", kindEnum}, {"grant_option", kindBool}, {"grantees", kindList}, {"grantor", kindStruct}, {"is_grant", kindBool}, {"objects", kindList}, {"objtype", kindEnum}, {"privileges", kindList}, {"targtype", kindEnum}},
+	"GroupingFunc":                 {{"agglevelsup", kindInt}, {"args", kindList}, {"refs", kindList}},
+	"GroupingSet":                  {{"content", kindList}, {"kind", kindEnum}},
+	"ImportForeignSchemaStmt":      {{"list_type", kindEnum}, {"local_schema", kindString}, {"options", kindList}, {"remote_schema", kindString}, {"server_name", kindString}, {"table_list", kindList}},
+	"IndexElem":                    {{"collation", kindList}, {"expr", kindNode}, {"indexcolname", kindString}, {"name", kindString}, {"nulls_ordering", kindEnum}, {"opclass", kindList}, {"opclassopts", kindList}, {"ordering", kindEnum}},
+	"IndexStmt":                    {{"accessMethod", kindString}, {"concurrent", kindBool}, {"deferrable", kindBool}, {"excludeOpNames", kindList}, {"idxcomment", kindString}, {"idxname", kindString}, {"if_not_exists", kindBool}, {"indexIncludingParams", kindList}, {"indexOid", kindInt}, {"indexParams", kindList}, {"initdeferred", kindBool}, {"isconstraint", kindBool}, {"nulls_not_distinct", kindBool}, {"oldCreateSubid", kindInt}, {"oldFirstRelfilelocatorSubid", kindInt}, {"oldNumber", kindInt}, {"options", kindList}, {"primary", kindBool}, {"relation", kindStruct}, {"reset_default_tblspc", kindBool}, {"tableSpace", kindString}, {"transformed", kindBool}, {"unique", kindBool}, {"whereClause", kindNode}},
+	"InferClause":                  {{"conname", kindString}, {"indexElems", kindList}, {"whereClause", kindNode}},
+	"InferenceElem":                {{"expr", kindNode}, {"infercollid", kindInt}, {"inferopclass", kindInt}},
+	"InlineCodeBlock":              {{"atomic", kindBool}, {"langIsTrusted", kindBool}, {"langOid", kindInt}, {"source_text", kindString}},
+	"InsertStmt":                   {{"cols", kindList}, {"onConflictClause", kindStruct}, {"override", kindEnum}, {"relation", kindStruct}, {"returningList", kindList}, {"selectStmt", kindNode}, {"withClause", kindStruct}},
+	"IntoClause":                   {{"accessMethod", kindString}, {"colNames", kindList}, {"onCommit", kindEnum}, {"options", kindList}, {"rel", kindStruct}, {"skipData", kindBool}, {"tableSpaceName", kindString}, {"viewQuery", kindNode}},
+	"JoinExpr":                     {{"alias", kindStruct}, {"isNatural", kindBool}, {"join_using_alias", kindStruct}, {"jointype", kindEnum}, {"larg", kindNode}, {"quals", kindNode}, {"rarg", kindNode}, {"rtindex", kindInt}, {"usingClause", kindList}},
+	"JsonAggConstructor":           {{"agg_filter", kindNode}, {"agg_order", kindList}, {"output", kindStruct}, {"over", kindStruct}},
+	"JsonArgument":                 {{"name", kindString}, {"val", kindStruct}},
+	"JsonArrayAgg":                 {{"absent_on_null", kindBool}, {"arg", kindStruct}, {"constructor", kindStruct}},
+	"JsonArrayConstructor":         {{"absent_on_null", kindBool}, {"exprs", kindList}, {"output", kindStruct}},
+	"JsonArrayQueryConstructor":    {{"absent_on_null", kindBool}, {"format", kindStruct}, {"output", kindStruct}, {"query", kindNode}},
+	"JsonBehavior":                 {{"btype", kindEnum}, {"coerce", kindBool}, {"expr", kindNode}},
+	"JsonConstructorExpr":          {{"absent_on_null", kindBool}, {"args", kindList}, {"coercion", kindNode}, {"func", kindNode}, {"returning", kindStruct}, {"type", kindEnum}, {"unique", kindBool}},
+	"JsonExpr":                     {{"collation", kindInt}, {"column_name", kindString}, {"format", kindStruct}, {"formatted_expr", kindNode}, {"omit_quotes", kindBool}, {"on_empty", kindStruct}, {"on_error", kindStruct}, {"op", kindEnum}, {"passing_names", kindList}, {"passing_values", kindList}, {"path_spec", kindNode}, {"returning", kindStruct}, {"use_io_coercion", kindBool}, {"use_json_coercion", kindBool}, {"wrapper", kindEnum}},
+	"JsonFormat":                   {{"encoding", kindEnum}, {"format_type", kindEnum}},
+	"JsonFuncExpr":                 {{"column_name", kindString}, {"context_item", kindStruct}, {"on_empty", kindStruct}, {"on_error", kindStruct}, {"op", kindEnum}, {"output", kindStruct}, {"passing", kindList}, {"pathspec", kindNode}, {"quotes", kindEnum}, {"wrapper", kindEnum}},
+	"JsonIsPredicate":              {{"expr", kindNode}, {"format", kindStruct}, {"item_type", kindEnum}, {"unique_keys", kindBool}},
+	"JsonKeyValue":                 {{"key", kindNode}, {"value", kindStruct}},
+	"JsonObjectAgg":                {{"absent_on_null", kindBool}, {"arg", kindStruct}, {"constructor", kindStruct}, {"unique", kindBool}},
+	"JsonObjectConstructor":        {{"absent_on_null", kindBool}, {"exprs", kindList}, {"output", kindStruct}, {"unique", kindBool}},
+	"JsonOutput":                   {{"returning", kindStruct}, {"typeName", kindStruct}},
+	"JsonParseExpr":                {{"expr", kindStruct}, {"output", kindStruct}, {"unique_keys", kindBool}},
+	"JsonReturning":                {{"format", kindStruct}, {"typid", kindInt}, {"typmod", kindInt}},
+	"JsonScalarExpr":               {{"expr", kindNode}, {"output", kindStruct}},
+	"JsonSerializeExpr":            {{"expr", kindStruct}, {"output", kindStruct}},
+	"JsonTable":                    {{"alias", kindStruct}, {"columns", kindList}, {"context_item", kindStruct}, {"lateral", kindBool}, {"on_error", kindStruct}, {"passing", kindList}, {"pathspec", kindStruct}},
+	"JsonTableColumn":              {{"coltype", kindEnum}, {"columns", kindList}, {"format", kindStruct}, {"name", kindString}, {"on_empty", kindStruct}, {"on_error", kindStruct}, {"pathspec", kindStruct}, {"quotes", kindEnum}, {"typeName", kindStruct}, {"wrapper", kindEnum}},
+	"JsonTablePath":                {{"name", kindString}},
+	"JsonTablePathScan":            {{"child", kindNode}, {"colMax", kindInt}, {"colMin", kindInt}, {"errorOnError", kindBool}, {"path", kindStruct}},
+	"JsonTablePathSpec":            {{"name", kindString}, {"string", kindNode}},
+	"JsonTableSiblingJoin":         {{"lplan", kindNode}, {"rplan", kindNode}},
+	"JsonValueExpr":                {{"format", kindStruct}, {"formatted_expr", kindNode}, {"raw_expr", kindNode}},
+	"ListenStmt":                   {},
+	"LoadStmt":                     {{"filename", kindString}},
+	"LockStmt":                     {{"mode", kindInt}, {"nowait", kindBool}, {"relations", kindList}},
+	"LockingClause":                {{"lockedRels", kindList}, {"strength", kindEnum}, {"waitPolicy", kindEnum}},
+	"MergeAction":                  {{"commandType", kindEnum}, {"matchKind", kindEnum}, {"override", kindEnum}, {"qual", kindNode}, {"targetList", kindList}, {"updateColnos", kindList}},
+	"MergeStmt":                    {{"joinCondition", kindNode}, {"mergeWhenClauses", kindList}, {"relation", kindStruct}, {"returningList", kindList}, {"sourceRelation", kindNode}, {"withClause", kindStruct}},
+	"MergeSupportFunc":             {{"msfcollid", kindInt}, {"msftype", kindInt}},
+	"MergeWhenClause":              {{"commandType", kindEnum}, {"condition", kindNode}, {"matchKind", kindEnum}, {"override", kindEnum}, {"targetList", kindList}, {"values", kindList}},
+	"MinMaxExpr":                   {{"args", kindList}, {"inputcollid", kindInt}, {"minmaxcollid", kindInt}, {"minmaxtype", kindInt}, {"op", kindEnum}},
+	"MultiAssignRef":               {{"colno", kindInt}, {"ncolumns", kindInt}, {"source", kindNode}},
+	"NamedArgExpr":                 {{"arg", kindNode}, {"argnumber", kindInt}, {"name", kindString}},
+	"NextValueExpr":                {{"seqid", kindInt}, {"typeId", kindInt}},
+	"NotifyStmt":                   {{"payload", kindString}},
+	"NullTest":                     {{"arg", kindNode}, {"argisrow", kindBool}, {"nulltesttype", kindEnum}},
+	"ObjectWithArgs":               {{"args_unspecified", kindBool}, {"objargs", kindList}, {"objfuncargs", kindList}, {"objname", kindList}},
+	"OnConflictClause":             {{"action", kindEnum}, {"infer", kindStruct}, {"targetList", kindList}, {"whereClause", kindNode}},
+	"OnConflictExpr":               {{"action", kindEnum}, {"arbiterElems", kindList}, {"arbiterWhere", kindNode}, {"constraint", kindInt}, {"exclRelIndex", kindInt}, {"exclRelTlist", kindList}, {"onConflictSet", kindList}, {"onConflictWhere", kindNode}},
+	"OpExpr":                       {{"args", kindList}, {"inputcollid", kindInt}, {"opcollid", kindInt}, {"opno", kindInt}, {"opresulttype", kindInt}, {"opretset", kindBool}},
+	"PLAssignStmt":                 {{"indirection", kindList}, {"name", kindString}, {"nnames", kindInt}, {"val", kindStruct}},
+	"Param":                        {{"paramcollid", kindInt}, {"paramid", kindInt}, {"paramkind", kindEnum}, {"paramtype", kindInt}, {"paramtypmod", kindInt}},
+	"ParamRef":                     {},
+	"PartitionBoundSpec":           {{"is_default", kindBool}, {"listdatums", kindList}, {"lowerdatums", kindList}, {"modulus", kindInt}, {"remainder", kindInt}, {"strategy", kindChar}, {"upperdatums", kindList}},
+	"PartitionCmd":                 {{"bound", kindStruct}, {"concurrent", kindBool}, {"name", kindStruct}},
+	"PartitionElem":                {{"collation", kindList}, {"expr", kindNode}, {"name", kindString}, {"opclass", kindList}},
+	"PartitionRangeDatum":          {{"kind", kindEnum}, {"value", kindNode}},
+	"PartitionSpec":                {{"partParams", kindList}, {"strategy", kindEnum}},
+	"PrepareStmt":                  {{"argtypes", kindList}, {"query", kindNode}},
+	"PublicationObjSpec":           {{"name", kindString}, {"pubobjtype", kindEnum}, {"pubtable", kindStruct}},
+	"PublicationTable":             {{"columns", kindList}, {"relation", kindStruct}, {"whereClause", kindNode}},
+	"Query":                        {{"canSetTag", kindBool}, {"commandType", kindEnum}, {"constraintDeps", kindList}, {"cteList", kindList}, {"distinctClause", kindList}, {"groupClause", kindList}, {"groupDistinct", kindBool}, {"groupingSets", kindList}, {"hasAggs", kindBool}, {"hasDistinctOn", kindBool}, {"hasForUpdate", kindBool}, {"hasModifyingCTE", kindBool}, {"hasRecursive", kindBool}, {"hasRowSecurity", kindBool}, {"hasSubLinks", kindBool}, {"hasTargetSRFs", kindBool}, {"hasWindowFuncs", kindBool}, {"havingQual", kindNode}, {"isReturn", kindBool}, {"jointree", kindStruct}, {"limitCount", kindNode}, {"limitOffset", kindNode}, {"limitOption", kindEnum}, {"mergeActionList", kindList}, {"mergeJoinCondition", kindNode}, {"mergeTargetRelation", kindInt}, {"onConflict", kindStruct}, {"override", kindEnum}, {"querySource", kindEnum}, {"resultRelation", kindInt}, {"returningList", kindList}, {"rowMarks", kindList}, {"rtable", kindList}, {"rteperminfos", kindList}, {"setOperations", kindNode}, {"sortClause", kindList}, {"stmt_len", kindInt}, {"stmt_location", kindInt}, {"targetList", kindList}, {"utilityStmt", kindNode}, {"windowClause", kindList}, {"withCheckOptions", kindList}},
+	"RTEPermissionInfo":            {{"checkAsUser", kindInt}, {"inh", kindBool}, {"relid", kindInt}, {"requiredPerms", kindInt}},
+	"RangeFunction":                {{"alias", kindStruct}, {"coldeflist", kindList}, {"functions", kindList}, {"is_rowsfrom", kindBool}, {"lateral", kindBool}, {"ordinality", kindBool}},
+	"RangeSubselect":               {{"alias", kindStruct}, {"lateral", kindBool}, {"subquery", kindNode}},
+	"RangeTableFunc":               {{"alias", kindStruct}, {"columns", kindList}, {"docexpr", kindNode}, {"lateral", kindBool}, {"namespaces", kindList}, {"rowexpr", kindNode}},
+	"RangeTableFuncCol":            {{"coldefexpr", kindNode}, {"colexpr", kindNode}, {"colname", kindString}, {"for_ordinality", kindBool}, {"is_not_null", kindBool}, {"typeName", kindStruct}},
+	"RangeTableSample":             {{"args", kindList}, {"method", kindList}, {"relation", kindNode}, {"repeatable", kindNode}},
+	"RangeTblEntry":                {{"alias", kindStruct}, {"colcollations", kindList}, {"coltypes", kindList}, {"coltypmods", kindList}, {"ctelevelsup", kindInt}, {"ctename", kindString}, {"enrname", kindString}, {"enrtuples", kindInt}, {"eref", kindStruct}, {"funcordinality", kindBool}, {"functions", kindList}, {"inFromCl", kindBool}, {"inh", kindBool}, {"join_using_alias", kindStruct}, {"joinaliasvars", kindList}, {"joinleftcols", kindList}, {"joinmergedcols", kindInt}, {"joinrightcols", kindList}, {"jointype", kindEnum}, {"lateral", kindBool}, {"perminfoindex", kindInt}, {"relid", kindInt}, {"relkind", kindChar}, {"rellockmode", kindInt}, {"rtekind", kindEnum}, {"securityQuals", kindList}, {"security_barrier", kindBool}, {"self_reference", kindBool}, {"subquery", kindStruct}, {"tablefunc", kindStruct}, {"tablesample", kindStruct}, {"values_lists", kindList}},
+	"RangeTblFunction":             {{"funccolcollations", kindList}, {"funccolcount", kindInt}, {"funccolnames", kindList}, {"funccoltypes", kindList}, {"funccoltypmods", kindList}, {"funcexpr", kindNode}},
+	"RangeTblRef":                  {{"rtindex", kindInt}},
+	"RangeVar":                     {{"alias", kindStruct}, {"catalogname", kindString}, {"inh", kindBool}, {"relname", kindString}, {"relpersistence", kindChar}, {"schemaname", kindString}},
+	"RawStmt":                      {{"stmt", kindNode}},
+	"ReassignOwnedStmt":            {{"newrole", kindStruct}, {"roles", kindList}},
+	"RefreshMatViewStmt":           {{"concurrent", kindBool}, {"relation", kindStruct}, {"skipData", kindBool}},
+	"ReindexStmt":                  {{"kind", kindEnum}, {"name", kindString}, {"params", kindList}, {"relation", kindStruct}},
+	"RelabelType":                  {{"arg", kindNode}, {"relabelformat", kindEnum}, {"resultcollid", kindInt}, {"resulttype", kindInt}, {"resulttypmod", kindInt}},
+	"RenameStmt":                   {{"behavior", kindEnum}, {"missing_ok", kindBool}, {"newname", kindString}, {"object", kindNode}, {"relation", kindStruct}, {"relationType", kindEnum}, {"renameType", kindEnum}, {"subname", kindString}},
+	"ReplicaIdentityStmt":          {{"identity_type", kindChar}, {"name", kindString}},
+	"ResTarget":                    {{"indirection", kindList}, {"name", kindString}, {"val", kindNode}},
+	"ReturnStmt":                   {{"returnval", kindNode}},
+	"RoleSpec":                     {{"rolename", kindString}, {"roletype", kindEnum}},
+	"RowCompareExpr":               {{"inputcollids", kindList}, {"largs", kindList}, {"opfamilies", kindList}, {"opnos", kindList}, {"rargs", kindList}, {"rctype", kindEnum}},
+	"RowExpr":                      {{"args", kindList}, {"colnames", kindList}, {"row_format", kindEnum}, {"row_typeid", kindInt}},
+	"RowMarkClause":                {{"pushedDown", kindBool}, {"rti", kindInt}, {"strength", kindEnum}, {"waitPolicy", kindEnum}},
+	"RuleStmt":                     {{"actions", kindList}, {"event", kindEnum}, {"instead", kindBool}, {"relation", kindStruct}, {"replace", kindBool}, {"rulename", kindString}, {"whereClause", kindNode}},
+	"SQLValueFunction":             {{"op", kindEnum}, {"type", kindInt}, {"typmod", kindInt}},
+	"ScalarArrayOpExpr":            {{"args", kindList}, {"inputcollid", kindInt}, {"opno", kindInt}, {"useOr", kindBool}},
+	"SecLabelStmt":                 {{"label", kindString}, {"object", kindNode}, {"objtype", kindEnum}, {"provider", kindString}},
+	"SelectStmt":                   {{"all", kindBool}, {"distinctClause", kindList}, {"fromClause", kindList}, {"groupClause", kindList}, {"groupDistinct", kindBool}, {"havingClause", kindNode}, {"intoClause", kindStruct}, {"larg", kindStruct}, {"limitCount", kindNode}, {"limitOffset", kindNode}, {"limitOption", kindEnum}, {"lockingClause", kindList}, {"op", kindEnum}, {"rarg", kindStruct}, {"sortClause", kindList}, {"targetList", kindList}, {"valuesLists", kindList}, {"whereClause", kindNode}, {"windowClause", kindList}, {"withClause", kindStruct}},
+	"SetOperationStmt":             {{"all", kindBool}, {"colCollations", kindList}, {"colTypes", kindList}, {"colTypmods", kindList}, {"groupClauses", kindList}, {"larg", kindNode}, {"op", kindEnum}, {"rarg", kindNode}},
+	"SetToDefault":                 {},
+	"SinglePartitionSpec":          {},
+	"SortBy":                       {{"node", kindNode}, {"sortby_dir", kindEnum}, {"sortby_nulls", kindEnum}, {"useOp", kindList}},
+	"SortGroupClause":              {{"eqop", kindInt}, {"hashable", kindBool}, {"nulls_first", kindBool}, {"sortop", kindInt}, {"tleSortGroupRef", kindInt}},
+	"StatsElem":                    {{"expr", kindNode}, {"name", kindString}},
+	"SubLink":                      {{"operName", kindList}, {"subLinkId", kindInt}, {"subLinkType", kindEnum}, {"subselect", kindNode}, {"testexpr", kindNode}},
+	"SubPlan":                      {{"args", kindList}, {"firstColCollation", kindInt}, {"firstColType", kindInt}, {"firstColTypmod", kindInt}, {"parParam", kindList}, {"parallel_safe", kindBool}, {"paramIds", kindList}, {"per_call_cost", kindInt}, {"plan_id", kindInt}, {"plan_name", kindString}, {"setParam", kindList}, {"startup_cost", kindInt}, {"subLinkType", kindEnum}, {"testexpr", kindNode}, {"unknownEqFalse", kindBool}, {"useHashTable", kindBool}},
+	"SubscriptingRef":              {{"refassgnexpr", kindNode}, {"refcollid", kindInt}, {"refcontainertype", kindInt}, {"refelemtype", kindInt}, {"refexpr", kindNode}, {"reflowerindexpr", kindList}, {"refrestype", kindInt}, {"reftypmod", kindInt}, {"refupperindexpr", kindList}},
+	"TableFunc":                    {{"colcollations", kindList}, {"coldefexprs", kindList}, {"colexprs", kindList}, {"colnames", kindList}, {"coltypes", kindList}, {"coltypmods", kindList}, {"colvalexprs", kindList}, {"docexpr", kindNode}, {"functype", kindEnum}, {"ns_names", kindList}, {"ns_uris", kindList}, {"ordinalitycol", kindInt}, {"passingvalexprs", kindList}, {"plan", kindNode}, {"rowexpr", kindNode}},
+	"TableLikeClause":              {{"options", kindInt}, {"relation", kindStruct}, {"relationOid", kindInt}},
+	"TableSampleClause":            {{"args", kindList}, {"repeatable", kindNode}, {"tsmhandler", kindInt}},
+	"TargetEntry":                  {{"expr", kindNode}, {"resjunk", kindBool}, {"resname", kindString}, {"resno", kindInt}, {"resorigcol", kindInt}, {"resorigtbl", kindInt}, {"ressortgroupref", kindInt}},
+	"TransactionStmt":              {{"chain", kindBool}, {"kind", kindEnum}},
+	"TriggerTransition":            {{"isNew", kindBool}, {"isTable", kindBool}, {"name", kindString}},
+	"TruncateStmt":                 {{"behavior", kindEnum}, {"relations", kindList}, {"restart_seqs", kindBool}},
+	"TypeCast":                     {{"arg", kindNode}, {"typeName", kindStruct}},
+	"TypeName":                     {{"arrayBounds", kindList}, {"names", kindList}, {"pct_type", kindBool}, {"setof", kindBool}, {"typeOid", kindInt}, {"typemod", kindInt}, {"typmods", kindList}},
+	"UnlistenStmt":                 {},
+	"UpdateStmt":                   {{"fromClause", kindList}, {"relation", kindStruct}, {"returningList", kindList}, {"targetList", kindList}, {"whereClause", kindNode}, {"withClause", kindStruct}},
+	"VacuumRelation":               {{"oid", kindInt}, {"relation", kindStruct}, {"va_cols", kindList}},
+	"VacuumStmt":                   {{"is_vacuumcmd", kindBool}, {"options", kindList}, {"rels", kindList}},
+	"Var":                          {{"varattno", kindInt}, {"varcollid", kindInt}, {"varlevelsup", kindInt}, {"varno", kindInt}, {"vartype", kindInt}, {"vartypmod", kindInt}},
+	"VariableSetStmt":              {{"args", kindList}, {"is_local", kindBool}, {"kind", kindEnum}, {"name", kindString}},
+	"VariableShowStmt":             {{"name", kindString}},
+	"ViewStmt":                     {{"aliases", kindList}, {"options", kindList}, {"query", kindNode}, {"replace", kindBool}, {"view", kindStruct}, {"withCheckOption", kindEnum}},
+	"WindowClause":                 {{"copiedOrder", kindBool}, {"endInRangeFunc", kindInt}, {"endOffset", kindNode}, {"frameOptions", kindInt}, {"inRangeAsc", kindBool}, {"inRangeColl", kindInt}, {"inRangeNullsFirst", kindBool}, {"name", kindString}, {"orderClause", kindList}, {"partitionClause", kindList}, {"refname", kindString}, {"startInRangeFunc", kindInt}, {"startOffset", kindNode}, {"winref", kindInt}},
+	"WindowDef":                    {{"endOffset", kindNode}, {"frameOptions", kindInt}, {"name", kindString}, {"orderClause", kindList}, {"partitionClause", kindList}, {"refname", kindString}, {"startOffset", kindNode}},
+	"WindowFunc":                   {{"aggfilter", kindNode}, {"args", kindList}, {"inputcollid", kindInt}, {"runCondition", kindList}, {"winagg", kindBool}, {"wincollid", kindInt}, {"winfnoid", kindInt}, {"winref", kindInt}, {"winstar", kindBool}, {"wintype", kindInt}},
+	"WindowFuncRunCondition":       {{"arg", kindNode}, {"inputcollid", kindInt}, {"opno", kindInt}, {"wfunc_left", kindBool}},
+	"WithCheckOption":              {{"cascaded", kindBool}, {"kind", kindEnum}, {"polname", kindString}, {"qual", kindNode}, {"relname", kindString}},
+	"WithClause":                   {{"ctes", kindList}, {"recursive", kindBool}},
+	"XmlExpr":                      {{"arg_names", kindList}, {"args", kindList}, {"indent", kindBool}, {"name", kindString}, {"named_args", kindList}, {"op", kindEnum}, {"type", kindInt}, {"typmod", kindInt}, {"xmloption", kindEnum}},
+	"XmlSerialize":                 {{"expr", kindNode}, {"indent", kindBool}, {"typeName", kindStruct}, {"xmloption", kindEnum}},
+}
+
+// namedTypes are the node types whose name is fingerprinted before their
+// fields when they are referenced as nodes.
+var namedTypes = map[string]bool{
+	"A_ArrayExpr":                  true,
+	"A_Expr":                       true,
+	"A_Indices":                    true,
+	"A_Indirection":                true,
+	"A_Star":                       true,
+	"AccessPriv":                   true,
+	"Aggref":                       true,
+	"AlterCollationStmt":           true,
+	"AlterDatabaseRefreshCollStmt": true,
+	"AlterDatabaseSetStmt":         true,
+	"AlterDatabaseStmt":            true,
+	"AlterDefaultPrivilegesStmt":   true,
+	"AlterDomainStmt":              true,
+	"AlterEnumStmt":                true,
+	"AlterEventTrigStmt":           true,
+	"AlterExtensionContentsStmt":   true,
+	"AlterExtensionStmt":           true,
+	"AlterFdwStmt":                 true,
+	"AlterForeignServerStmt":       true,
+	"AlterFunctionStmt":            true,
+	"AlterObjectDependsStmt":       true,
+	"AlterObjectSchemaStmt":        true,
+	"AlterOpFamilyStmt":            true,
+	"AlterOperatorStmt":            true,
+	"AlterOwnerStmt":               true,
+	"AlterPolicyStmt":              true,
+	"AlterPublicationStmt":         true,
+	"AlterRoleSetStmt":             true,
+	"AlterRoleStmt":                true,
+	"AlterSeqStmt":                 true,
+	"AlterStatsStmt":               true,
+	"AlterSubscriptionStmt":        true,
+	"AlterSystemStmt":              true,
+	"AlterTSConfigurationStmt":     true,
+	"AlterTSDictionaryStmt":        true,
+	"AlterTableCmd":                true,
+	"AlterTableMoveAllStmt":        true,
+	"AlterTableSpaceOptionsStmt":   true,
+	"AlterTableStmt":               true,
+	"AlterTypeStmt":                true,
+	"AlterUserMappingStmt":         true,
+	"AlternativeSubPlan":           true,
+	"ArrayCoerceExpr":              true,
+	"ArrayExpr":                    true,
+	"BoolExpr":                     true,
+	"BooleanTest":                  true,
+	"CTECycleClause":               true,
+	"CTESearchClause":              true,
+	"CallContext":                  true,
+	"CallStmt":                     true,
+	"CaseExpr":                     true,
+	"CaseTestExpr":                 true,
+	"CaseWhen":                     true,
+	"CheckPointStmt":               true,
+	"ClosePortalStmt":              true,
+	"ClusterStmt":                  true,
+	"CoalesceExpr":                 true,
+	"CoerceToDomain":               true,
+	"CoerceToDomainValue":          true,
+	"CoerceViaIO":                  true,
+	"CollateClause":                true,
+	"CollateExpr":                  true,
+	"ColumnDef":                    true,
+	"ColumnRef":                    true,
+	"CommentStmt":                  true,
+	"CommonTableExpr":              true,
+	"CompositeTypeStmt":            true,
+	"Const":                        true,
+	"Constraint":                   true,
+	"ConstraintsSetStmt":           true,
+	"ConvertRowtypeExpr":           true,
+	"CopyStmt":                     true,
+	"CreateAmStmt":                 true,
+	"CreateCastStmt":               true,
+	"CreateConversionStmt":         true,
+	"CreateDomainStmt":             true,
+	"CreateEnumStmt":               true,
+	"CreateEventTrigStmt":          true,
+	"CreateExtensionStmt":          true,
+	"CreateFdwStmt":                true,
+	"CreateForeignServerStmt":      true,
+	"CreateForeignTableStmt":       true,
+	"CreateFunctionStmt":           true,
+	"CreateOpClassItem":            true,
+	"CreateOpClassStmt":            true,
+	"CreateOpFamilyStmt":           true,
+	"CreatePLangStmt":              true,
+	"CreatePolicyStmt":             true,
+	"CreatePublicationStmt":        true,
+	"CreateRangeStmt":              true,
+	"CreateRoleStmt":               true,
+	"CreateSchemaStmt":             true,
+	"CreateSeqStmt":                true,
+	"CreateStatsStmt":              true,
+	"CreateStmt":                   true,
+	"CreateSubscriptionStmt":       true,
+	"CreateTableAsStmt":            true,
+	"CreateTableSpaceStmt":         true,
+	"CreateTransformStmt":          true,
+	"CreateTrigStmt":               true,
+	"CreateUserMappingStmt":        true,
+	"CreatedbStmt":                 true,
+	"CurrentOfExpr":                true,
+	"DeallocateStmt":               true,
+	"DeclareCursorStmt":            true,
+	"DefElem":                      true,
+	"DefineStmt":                   true,
+	"DeleteStmt":                   true,
+	"DiscardStmt":                  true,
+	"DoStmt":                       true,
+	"DropOwnedStmt":                true,
+	"DropRoleStmt":                 true,
+	"DropStmt":                     true,
+	"DropSubscriptionStmt":         true,
+	"DropTableSpaceStmt":           true,
+	"DropUserMappingStmt":          true,
+	"DropdbStmt":                   true,
+	"ExecuteStmt":                  true,
+	"ExplainStmt":                  true,
+	"FetchStmt":                    true,
+	"FieldSelect":                  true,
+	"FieldStore":                   true,
+	"FromExpr":                     true,
+	"FuncCall":                     true,
+	"FuncExpr":                     true,
+	"FunctionParameter":            true,
+	"GrantRoleStmt":                true,
+	"GrantStmt":                    true,
+	"GroupingFunc":                 true,
+	"GroupingSet":                  true,
+	"ImportForeignSchemaStmt":      true,
+	"IndexElem":                    true,
+	"IndexStmt":                    true,
+	"InferClause":                  true,
+	"InferenceElem":                true,
+	"InlineCodeBlock":              true,
+	"InsertStmt":                   true,
+	"IntoClause":                   true,
+	"JoinExpr":                     true,
+	"JsonAggConstructor":           true,
+	"JsonArgument":                 true,
+	"JsonArrayAgg":                 true,
+	"JsonArrayConstructor":         true,
+	"JsonArrayQueryConstructor":    true,
+	"JsonBehavior":                 true,
+	"JsonConstructorExpr":          true,
+	"JsonExpr":                     true,
+	"JsonFormat":                   true,
+	"JsonFuncExpr":                 true,
+	"JsonIsPredicate":              true,
+	"JsonKeyValue":                 true,
+	"JsonObjectAgg":                true,
+	"JsonObjectConstructor":        true,
+	"JsonOutput":                   true,
+	"JsonParseExpr":                true,
+	"JsonReturning":                true,
+	"JsonScalarExpr":               true,
+	"JsonSerializeExpr":            true,
+	"JsonTable":                    true,
+	"JsonTableColumn":              true,
+	"JsonTablePath":                true,
+	"JsonTablePathScan":            true,
+	"JsonTablePathSpec":            true,
+	"JsonTableSiblingJoin":         true,
+	"JsonValueExpr":                true,
+	"ListenStmt":                   true,
+	"LoadStmt":                     true,
+	"LockStmt":                     true,
+	"LockingClause":                true,
+	"MergeAction":                  true,
+	"MergeStmt":                    true,
+	"MergeSupportFunc":             true,
+	"MergeWhenClause":              true,
+	"MinMaxExpr":                   true,
+	"MultiAssignRef":               true,
+	"NamedArgExpr":                 true,
+	"NextValueExpr":                true,
+	"NotifyStmt":                   true,
+	"NullTest":                     true,
+	"ObjectWithArgs":               true,
+	"OnConflictClause":             true,
+	"OnConflictExpr":               true,
+	"OpExpr":                       true,
+	"PLAssignStmt":                 true,
+	"Param":                        true,
+	"PartitionBoundSpec":           true,
+	"PartitionCmd":                 true,
+	"PartitionElem":                true,
+	"PartitionRangeDatum":          true,
+	"PartitionSpec":                true,
+	"PrepareStmt":                  true,
+	"PublicationObjSpec":           true,
+	"PublicationTable":             true,
+	"Query":                        true,
+	"RTEPermissionInfo":            true,
+	"RangeFunction":                true,
+	"RangeSubselect":               true,
+	"RangeTableFunc":               true,
+	"RangeTableFuncCol":            true,
+	"RangeTableSample":             true,
+	"RangeTblEntry":                true,
+	"RangeTblFunction":             true,
+	"RangeTblRef":                  true,
+	"RangeVar":                     true,
+	"RawStmt":                      true,
+	"ReassignOwnedStmt":            true,
+	"RefreshMatViewStmt":           true,
+	"ReindexStmt":                  true,
+	"RelabelType":                  true,
+	"RenameStmt":                   true,
+	"ReplicaIdentityStmt":          true,
+	"ResTarget":                    true,
+	"ReturnStmt":                   true,
+	"RoleSpec":                     true,
+	"RowCompareExpr":               true,
+	"RowExpr":                      true,
+	"RowMarkClause":                true,
+	"RuleStmt":                     true,
+	"SQLValueFunction":             true,
+	"ScalarArrayOpExpr":            true,
+	"SecLabelStmt":                 true,
+	"SelectStmt":                   true,
+	"SetOperationStmt":             true,
+	"SinglePartitionSpec":          true,
+	"SortBy":                       true,
+	"SortGroupClause":              true,
+	"StatsElem":                    true,
+	"SubLink":                      true,
+	"SubPlan":                      true,
+	"SubscriptingRef":              true,
+	"TableFunc":                    true,
+	"TableLikeClause":              true,
+	"TableSampleClause":            true,
+	"TargetEntry":                  true,
+	"TransactionStmt":              true,
+	"TriggerTransition":            true,
+	"TruncateStmt":                 true,
+	"TypeCast":                     true,
+	"TypeName":                     true,
+	"UnlistenStmt":                 true,
+	"UpdateStmt":                   true,
+	"VacuumRelation":               true,
+	"VacuumStmt":                   true,
+	"Var":                          true,
+	"VariableSetStmt":              true,
+	"VariableShowStmt":             true,
+	"ViewStmt":                     true,
+	"WindowClause":                 true,
+	"WindowDef":                    true,
+	"WindowFunc":                   true,
+	"WindowFuncRunCondition":       true,
+	"WithCheckOption":              true,
+	"WithClause":                   true,
+	"XmlExpr":                      true,
+	"XmlSerialize":                 true,
+}
@@ -0,0 +1,360 @@
+// Package fingerprint reimplements the fingerprinting of libpg_query to record
+// the tokens that are hashed, which libpg_query only prints for debugging.
+package fingerprint
+
+//go:generate go run gen.go
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/wasilibs/go-pgquery/internal/walk"
+	"github.com/wasilibs/go-pgquery/parser"
+)
+
+// version is the fingerprint version of libpg_query, used as hash seed.
+const version = 3
+
+// maxDepth is the depth of nodes beyond which libpg_query does not
+// fingerprint.
+const maxDepth = 100
+
+type kind uint8
+
+const (
+	kindBool kind = iota
+	kindString
+	// kindStringNode is a String node fingerprinted as a string.
+	kindStringNode
+	// kindChar is a single character, represented as string in parse trees.
+	kindChar
+	kindInt
+	kindEnum
+	kindNode
+	kindList
+	// kindStruct is a field of a specific node type, whose type name is not
+	// fingerprinted.
+	kindStruct
+	// kindInline is an embedded struct, fingerprinted at the same depth
+	// without checking whether it adds tokens.
+	kindInline
+)
+
+type field struct {
+	name string
+	kind kind
+}
+
+// resolvedField is a field of nodeFields with its protobuf field.
+type resolvedField struct {
+	field
+	fd protoreflect.FieldDescriptor
+}
+
+// sortedLists are the fields whose list elements are sorted and deduplicated
+// before fingerprinting, so their order and repetition do not matter.
+var sortedLists = map[string]bool{
+	"fromClause":  true,
+	"targetList":  true,
+	"cols":        true,
+	"rexpr":       true,
+	"valuesLists": true,
+	"args":        true,
+}
+
+// Tokens returns the tokens hashed to fingerprint the statements of tree, in
+// order.
+func Tokens(tree *pganalyze.ParseResult) []string {
+	var f fingerprinter
+	for _, raw := range tree.GetStmts() {
+		// The statements are the elements of a list at depth 0.
+		f.node(raw.ProtoReflect(), tree.ProtoReflect(), "", 1)
+	}
+	return f.tokens
+}
+
+// Hash returns the fingerprint of tokens.
+func Hash(tokens []string) uint64 {
+	return parser.HashXXH3_64([]byte(strings.Join(tokens, "")), version)
+}
+
+type fingerprinter struct {
+	tokens []string
+}
+
+func (f *fingerprinter) emit(tokens ...string) {
+	f.tokens = append(f.tokens, tokens...)
+}
+
+// node fingerprints m, which may be a *pganalyze.Node wrapper, referenced by
+// fieldName of parent.
+func (f *fingerprinter) node(m protoreflect.Message, parent protoreflect.Message, fieldName string, depth int) {
+	if depth >= maxDepth || !m.IsValid() {
+		return
+	}
+	if n, ok := m.Interface().(*pganalyze.Node); ok {
+		msg := walk.Unwrap(n)
+		if msg == nil {
+			return
+		}
+		m = msg.ProtoReflect()
+	}
+
+	switch msg := m.Interface().(type) {
+	case *pganalyze.List:
+		f.list(msg.GetItems(), parent, fieldName, depth)
+	case *pganalyze.Integer:
+		if msg.GetIval() != 0 {
+			f.emit("Integer", "ival", strconv.Itoa(int(msg.GetIval())))
+		}
+	case *pganalyze.Float:
+		// libpg_query keeps the field names of Postgres 14 and below for
+		// stable fingerprints.
+		if msg.GetFval() != "" {
+			f.emit("Float", "str", msg.GetFval())
+		}
+	case *pganalyze.Boolean:
+		f.emit("Boolean", "boolval", strconv.FormatBool(msg.GetBoolval()))
+	case *pganalyze.String:
+		f.emit("String", "str", msg.GetSval())
+	case *pganalyze.BitString:
+		if msg.GetBsval() != "" {
+			f.emit("BitString", "str", msg.GetBsval())
+		}
+	case *pganalyze.TypeCast:
+		// Casts of constants and parameters are ignored like the constants.
+		if msg.GetArg().GetAConst() == nil && msg.GetArg().GetParamRef() == nil {
+			f.emit("TypeCast")
+			f.fields(m, parent, fieldName, depth)
+		}
+	default:
+		name := string(m.Descriptor().Name())
+		if namedTypes[name] {
+			f.emit(name)
+			f.fields(m, parent, fieldName, depth)
+		}
+	}
+}
+
+func (f *fingerprinter) list(items []*pganalyze.Node, parent protoreflect.Message, fieldName string, depth int) {
+	if depth >= maxDepth {
+		return
+	}
+	if !sortedLists[fieldName] {
+		for _, n := range items {
+			f.node(n.ProtoReflect(), parent, fieldName, depth+1)
+		}
+		return
+	}
+
+	type item struct {
+		hash   uint64
+		tokens []string
+	}
+	sorted := make([]item, len(items))
+	for i, n := range items {
+		var sub fingerprinter
+		sub.node(n.ProtoReflect(), parent, fieldName, depth+1)
+		sorted[i] = item{hash: Hash(sub.tokens), tokens: sub.tokens}
+	}
+	slices.SortStableFunc(sorted, func(a, b item) int {
+		switch {
+		case a.hash < b.hash:
+			return -1
+		case a.hash > b.hash:
+			return 1
+		}
+		return 0
+	})
+	for i, it := range sorted {
+		if i > 0 && sorted[i-1].hash == it.hash {
+			continue
+		}
+		f.emit(it.tokens...)
+	}
+}
+
+// fields fingerprints the fields of m, without its type name.
+func (f *fingerprinter) fields(m protoreflect.Message, parent protoreflect.Message, fieldName string, depth int) {
+	skip := ""
+	switch msg := m.Interface().(type) {
+	case *pganalyze.ResTarget:
+		// Column aliases of SELECT do not change the query.
+		if _, ok := parent.Interface().(*pganalyze.SelectStmt); ok && fieldName == "targetList" {
+			skip = "name"
+		}
+	case *pganalyze.A_Expr:
+		// = ANY and IN are fingerprinted like =. The kind is the first field.
+		if k := msg.GetKind(); k == pganalyze.A_Expr_Kind_AEXPR_OP_ANY || k == pganalyze.A_Expr_Kind_AEXPR_IN {
+			f.emit("kind", "AEXPR_OP")
+			skip = "kind"
+		}
+	}
+	for _, rf := range resolved[m.Descriptor().FullName()] {
+		if rf.name != skip {
+			f.field(m, rf, depth)
+		}
+	}
+}
+
+func (f *fingerprinter) field(m protoreflect.Message, rf resolvedField, depth int) {
+	v := m.Get(rf.fd)
+	switch rf.kind {
+	case kindBool:
+		if v.Bool() {
+			f.emit(rf.name, "true")
+		}
+	case kindString, kindChar:
+		s := v.String()
+		if rv, ok := m.Interface().(*pganalyze.RangeVar); ok && rf.name == "relname" {
+			s = relname(rv)
+		}
+		if s != "" {
+			f.emit(rf.name, s)
+		}
+	case kindStringNode:
+		if s := v.Message().Interface().(*pganalyze.String).GetSval(); s != "" { //nolint:forcetypeassert // Resolved from String fields.
+			f.emit(rf.name, s)
+		}
+	case kindInt:
+		var s string
+		switch rf.fd.Kind() { //nolint:exhaustive // Only integer kinds are resolved as kindInt.
+		case protoreflect.Uint32Kind, protoreflect.Uint64Kind:
+			if v.Uint() != 0 {
+				s = strconv.FormatUint(v.Uint(), 10)
+			}
+		default:
+			if v.Int() != 0 {
+				s = strconv.FormatInt(v.Int(), 10)
+			}
+		}
+		if s != "" {
+			f.emit(rf.name, s)
+		}
+	case kindEnum:
+		if ev := rf.fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			f.emit(rf.name, string(ev.Name()))
+		}
+	case kindNode:
+		if m.Has(rf.fd) {
+			f.rollback(rf.name, func() { f.node(v.Message(), m, rf.name, depth+1) }, false)
+		}
+	case kindList:
+		l := v.List()
+		if l.Len() == 0 {
+			return
+		}
+		items := make([]*pganalyze.Node, l.Len())
+		for i := range l.Len() {
+			items[i] = l.Get(i).Message().Interface().(*pganalyze.Node) //nolint:forcetypeassert // Lists are resolved from Node fields.
+		}
+		// A list holding an empty list is kept even without tokens, as for
+		// SELECT DISTINCT.
+		keep := len(items) == 1 && isNil(items[0])
+		f.rollback(rf.name, func() { f.list(items, m, rf.name, depth+1) }, keep)
+	case kindStruct:
+		if m.Has(rf.fd) {
+			child := v.Message()
+			if n, ok := child.Interface().(*pganalyze.Node); ok {
+				msg := walk.Unwrap(n)
+				if msg == nil {
+					return
+				}
+				child = msg.ProtoReflect()
+			}
+			f.rollback(rf.name, func() { f.fields(child, m, rf.name, depth+1) }, false)
+		}
+	case kindInline:
+		f.emit(rf.name)
+		f.fields(v.Message(), m, rf.name, depth)
+	}
+}
+
+// rollback emits name followed by the tokens of fn, removing name again if fn
+// emits no tokens unless keep is set.
+func (f *fingerprinter) rollback(name string, fn func(), keep bool) {
+	f.emit(name)
+	n := len(f.tokens)
+	fn()
+	if len(f.tokens) == n && !keep {
+		f.tokens = f.tokens[:n-1]
+	}
+}
+
+// relname returns the fingerprinted name of the table of rv. Temporary tables
+// are ignored, and digits in names of other tables are ignored if they are
+// part of a number, as for partitions or shards.
+func relname(rv *pganalyze.RangeVar) string {
+	if rv.GetRelpersistence() == "t" {
+		return ""
+	}
+	return stripNumbers(rv.GetRelname())
+}
+
+// stripNumbers removes digits adjacent to other digits from s.
+func stripNumbers(s string) string {
+	isDigit := func(i int) bool { return i >= 0 && i < len(s) && s[i] >= '0' && s[i] <= '9' }
+	var b strings.Builder
+	for i := range len(s) {
+		if isDigit(i) && (isDigit(i+1) || isDigit(i-1)) {
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// isNil returns whether n is NIL, an empty list in Postgres.
+func isNil(n *pganalyze.Node) bool {
+	if n.GetNode() == nil {
+		return true
+	}
+	l := n.GetList()
+	return l != nil && len(l.GetItems()) == 0
+}
+
+// resolved are the fields of nodeFields by protobuf message, for the node
+// types present in parse trees.
+var resolved = resolveFields()
+
+func resolveFields() map[protoreflect.FullName][]resolvedField {
+	res := make(map[protoreflect.FullName][]resolvedField, len(nodeFields))
+	for typ, fields := range nodeFields {
+		d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName("pg_query." + typ))
+		if err != nil {
+			// Node types only occurring in analyzed trees.
+			continue
+		}
+		md, ok := d.(protoreflect.MessageDescriptor)
+		if !ok {
+			continue
+		}
+		byName := map[string]protoreflect.FieldDescriptor{}
+		for i := range md.Fields().Len() {
+			fd := md.Fields().Get(i)
+			byName[normalizeName(string(fd.Name()))] = fd
+		}
+		for _, fd := range fields {
+			pf, ok := byName[normalizeName(fd.name)]
+			if !ok {
+				// Special fields of the protobuf representation.
+				pf, ok = byName[normalizeName(fd.name+"_stmt")]
+			}
+			if !ok {
+				panic(fmt.Sprintf("fingerprint: no field for %s.%s", typ, fd.name))
+			}
+			res[md.FullName()] = append(res[md.FullName()], resolvedField{field: fd, fd: pf})
+		}
+	}
+	return res
+}
+
+func normalizeName(s string) string {
+	return strings.ToLower(strings.ReplaceAll(s, "_", ""))
+}
@@ -0,0 +1,180 @@
+//go:build ignore
+
+// gen generates fields.go from the fingerprint definitions of libpg_query.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	defsPath  = "../cparser/include/pg_query_fingerprint_defs.c"
+	condsPath = "../cparser/include/pg_query_fingerprint_conds.c"
+)
+
+var (
+	funcRe      = regexp.MustCompile(`^_fingerprint(\w+)\(FingerprintContext \*ctx, const \w+ \*node`)
+	boolRe      = regexp.MustCompile(`^  if \(node->(\w+)\) \{$`)
+	listRe      = regexp.MustCompile(`^  if \(node->(\w+) != NULL && node->\w+->length > 0\) \{$`)
+	ptrRe       = regexp.MustCompile(`^  if \(node->(\w+) != NULL`)
+	intRe       = regexp.MustCompile(`^  if \(node->(\w+) != 0\) \{$`)
+	enumRe      = regexp.MustCompile(`^    _fingerprintString\(ctx, "(\w+)"\);$`)
+	nodeCallRe  = regexp.MustCompile(`^    _fingerprintNode\(ctx, `)
+	extnameRe   = regexp.MustCompile(`^  if \(strlen\(node->(\w+)->sval\) > 0\) \{$`)
+	inlineRe    = regexp.MustCompile(`^  _fingerprintString\(ctx, "(\w+)"\);$`)
+	condCaseRe  = regexp.MustCompile(`^case T_(\w+):$`)
+	condEmitRe  = regexp.MustCompile(`^  _fingerprintString\(ctx, "(\w+)"\);$`)
+	condGuardRe = regexp.MustCompile(`^  if \(`)
+)
+
+type field struct {
+	name string
+	kind string
+}
+
+func main() {
+	emitted := emittedTypes()
+
+	f, err := os.Open(defsPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	var lines []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		lines = append(lines, s.Text())
+	}
+	if err := s.Err(); err != nil {
+		log.Fatal(err)
+	}
+
+	fields := map[string][]field{}
+	var typ string
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if m := funcRe.FindStringSubmatch(line); m != nil {
+			typ = m[1]
+			fields[typ] = []field{}
+			continue
+		}
+		if typ == "" {
+			continue
+		}
+		next := ""
+		if i+1 < len(lines) {
+			next = lines[i+1]
+		}
+		switch {
+		case line == "}":
+			typ = ""
+		case line == "  if (true) {" && (strings.Contains(next, "int x = -1;") || strings.Contains(next, "XXH3_createState")):
+			// Bitmapsets and embedded plan nodes only occur in analyzed trees,
+			// which are not fingerprinted.
+		case line == "  if (true) {":
+			m := enumRe.FindStringSubmatch(next)
+			if m == nil {
+				log.Fatalf("%s: unexpected enum %q", typ, next)
+			}
+			fields[typ] = append(fields[typ], field{m[1], "kindEnum"})
+		case listRe.MatchString(line):
+			fields[typ] = append(fields[typ], field{listRe.FindStringSubmatch(line)[1], "kindList"})
+		case boolRe.MatchString(line):
+			fields[typ] = append(fields[typ], field{boolRe.FindStringSubmatch(line)[1], "kindBool"})
+		case intRe.MatchString(line):
+			kind := "kindInt"
+			if strings.Contains(next, "char buffer[2]") {
+				kind = "kindChar"
+			}
+			fields[typ] = append(fields[typ], field{intRe.FindStringSubmatch(line)[1], kind})
+		case extnameRe.MatchString(line):
+			fields[typ] = append(fields[typ], field{extnameRe.FindStringSubmatch(line)[1], "kindStringNode"})
+		case ptrRe.MatchString(line):
+			name := ptrRe.FindStringSubmatch(line)[1]
+			kind := "kindString"
+			if strings.Contains(next, "XXH3_createState") {
+				// The call follows the state setup and the field name.
+				kind = "kindStruct"
+				for _, l := range lines[i+1 : i+10] {
+					if nodeCallRe.MatchString(l) {
+						kind = "kindNode"
+						break
+					}
+				}
+			}
+			fields[typ] = append(fields[typ], field{name, kind})
+		case inlineRe.MatchString(line):
+			fields[typ] = append(fields[typ], field{inlineRe.FindStringSubmatch(line)[1], "kindInline"})
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen.go from libpg_query's pg_query_fingerprint_defs.c. DO NOT EDIT.\n\n")
+	buf.WriteString("package fingerprint\n\n")
+	buf.WriteString("// nodeFields are the fingerprinted fields of node types by their names in\n")
+	buf.WriteString("// Postgres, in the order they are fingerprinted.\n")
+	buf.WriteString("var nodeFields = map[string][]field{\n")
+	types := make([]string, 0, len(fields))
+	for t := range fields {
+		types = append(types, t)
+	}
+	slices.Sort(types)
+	for _, t := range types {
+		fmt.Fprintf(&buf, "%q: {", t)
+		for _, fd := range fields[t] {
+			fmt.Fprintf(&buf, "{%q, %s},", fd.name, fd.kind)
+		}
+		buf.WriteString("},\n")
+	}
+	buf.WriteString("}\n\n")
+	buf.WriteString("// namedTypes are the node types whose name is fingerprinted before their\n")
+	buf.WriteString("// fields when they are referenced as nodes.\n")
+	buf.WriteString("var namedTypes = map[string]bool{\n")
+	for _, t := range emitted {
+		fmt.Fprintf(&buf, "%q: true,\n", t)
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("fields.go", src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// emittedTypes returns the node types whose name is fingerprinted.
+func emittedTypes() []string {
+	src, err := os.ReadFile(condsPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var types []string
+	lines := strings.Split(string(src), "\n")
+	for i, line := range lines {
+		m := condCaseRe.FindStringSubmatch(line)
+		if m == nil || i+1 >= len(lines) {
+			continue
+		}
+		next := lines[i+1]
+		if condGuardRe.MatchString(next) && i+3 < len(lines) {
+			// TypeCast is guarded by the type of its argument.
+			next = lines[i+3]
+		}
+		if e := condEmitRe.FindStringSubmatch(next); e != nil && e[1] == m[1] {
+			types = append(types, m[1])
+		}
+	}
+	slices.Sort(types)
+	return types
+}
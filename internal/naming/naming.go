@@ -0,0 +1,120 @@
+// Package naming implements the rules Postgres uses to name the output
+// columns of queries and the sources of FROM clauses.
+package naming
+
+import (
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+
+	"github.com/wasilibs/go-pgquery/internal/walk"
+)
+
+// Unnamed is the name of an output column whose name cannot be derived from
+// its expression.
+const Unnamed = "?column?"
+
+// OutputName returns the name of the column produced by a target list entry,
+// its alias or else the name derived from its expression.
+func OutputName(rt *pganalyze.ResTarget) string {
+	if rt.GetName() != "" {
+		return rt.GetName()
+	}
+	return ExprName(rt.GetVal())
+}
+
+// ExprName returns the name of the column produced by an unaliased expression,
+// following FigureColname of Postgres. A star reference is named "*".
+func ExprName(n *pganalyze.Node) string {
+	name, _ := figure(n)
+	return name
+}
+
+// figure returns the name of the column produced by n and the strength of the
+// name. Names derived from type names or keywords have strength 1 and are
+// overridden by stronger names, such as those of the ELSE branch of CASE.
+func figure(n *pganalyze.Node) (string, int) {
+	switch v := walk.Unwrap(n).(type) {
+	case *pganalyze.ColumnRef:
+		fields := v.GetFields()
+		if len(fields) == 0 {
+			break
+		}
+		last := fields[len(fields)-1]
+		if last.GetAStar() != nil {
+			return "*", 2
+		}
+		return last.GetString_().GetSval(), 2
+	case *pganalyze.A_Indirection:
+		ind := v.GetIndirection()
+		if len(ind) > 0 {
+			if s := ind[len(ind)-1].GetString_(); s != nil {
+				return s.GetSval(), 2
+			}
+		}
+		return figure(v.GetArg())
+	case *pganalyze.FuncCall:
+		names := v.GetFuncname()
+		return names[len(names)-1].GetString_().GetSval(), 2
+	case *pganalyze.A_Expr:
+		if v.GetKind() == pganalyze.A_Expr_Kind_AEXPR_NULLIF {
+			return "nullif", 2
+		}
+	case *pganalyze.TypeCast:
+		if name, strength := figure(v.GetArg()); strength > 1 {
+			return name, strength
+		}
+		names := v.GetTypeName().GetNames()
+		if len(names) > 0 {
+			return names[len(names)-1].GetString_().GetSval(), 1
+		}
+	case *pganalyze.CollateClause:
+		return figure(v.GetArg())
+	case *pganalyze.GroupingFunc:
+		return "grouping", 2
+	case *pganalyze.SubLink:
+		switch v.GetSubLinkType() { //nolint:exhaustive // Other sublinks are unnamed.
+		case pganalyze.SubLinkType_EXISTS_SUBLINK:
+			return "exists", 2
+		case pganalyze.SubLinkType_ARRAY_SUBLINK:
+			return "array", 2
+		case pganalyze.SubLinkType_EXPR_SUBLINK:
+			targets := v.GetSubselect().GetSelectStmt().GetTargetList()
+			if len(targets) > 0 {
+				if rt := targets[0].GetResTarget(); rt.GetName() != "" {
+					return rt.GetName(), 2
+				}
+				return figure(targets[0].GetResTarget().GetVal())
+			}
+		}
+	case *pganalyze.CaseExpr:
+		if name, strength := figure(v.GetDefresult()); strength > 1 {
+			return name, strength
+		}
+		return "case", 1
+	case *pganalyze.A_ArrayExpr:
+		return "array", 2
+	case *pganalyze.RowExpr:
+		return "row", 2
+	case *pganalyze.CoalesceExpr:
+		return "coalesce", 2
+	case *pganalyze.MinMaxExpr:
+		if v.GetOp() == pganalyze.MinMaxOp_IS_GREATEST {
+			return "greatest", 2
+		}
+		return "least", 2
+	}
+	return Unnamed, 0
+}
+
+// FunctionName returns the name of the source produced by a function in a FROM
+// clause without an alias, the name of its first function.
+func FunctionName(f *pganalyze.RangeFunction) string {
+	for _, n := range f.GetFunctions() {
+		for _, item := range n.GetList().GetItems() {
+			if fc := item.GetFuncCall(); fc != nil {
+				names := fc.GetFuncname()
+				return names[len(names)-1].GetString_().GetSval()
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,91 @@
+// Package walk implements generic traversal of pg_query parse trees.
+package walk
+
+import (
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Walk traverses msg in depth-first order, calling fn for msg and every message
+// reachable from it. Both *pganalyze.Node wrappers and the node messages they
+// contain are visited, wrapper first. If fn returns false, the children of the
+// visited message are skipped.
+//
+// Fields are visited in declaration order, which for the pg_query protobuf
+// definitions matches the field order of the corresponding Postgres structs.
+func Walk(msg proto.Message, fn func(msg proto.Message) bool) {
+	if msg == nil {
+		return
+	}
+	walk(msg.ProtoReflect(), fn)
+}
+
+// Nodes calls Walk for every element of nodes.
+func Nodes(nodes []*pganalyze.Node, fn func(msg proto.Message) bool) {
+	for _, n := range nodes {
+		if n != nil {
+			Walk(n, fn)
+		}
+	}
+}
+
+func walk(m protoreflect.Message, fn func(msg proto.Message) bool) {
+	if !m.IsValid() {
+		return
+	}
+	if !fn(m.Interface()) {
+		return
+	}
+
+	fields := m.Descriptor().Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		if fd.Kind() != protoreflect.MessageKind || !m.Has(fd) {
+			continue
+		}
+		v := m.Get(fd)
+		switch {
+		case fd.IsList():
+			l := v.List()
+			for j := range l.Len() {
+				walk(l.Get(j).Message(), fn)
+			}
+		case fd.IsMap():
+			// pg_query protobuf definitions do not contain maps.
+		default:
+			walk(v.Message(), fn)
+		}
+	}
+}
+
+// Unwrap returns the node message held by n, or nil if n is empty.
+func Unwrap(n *pganalyze.Node) proto.Message {
+	if n == nil || n.GetNode() == nil {
+		return nil
+	}
+	m := n.ProtoReflect()
+	od := m.Descriptor().Oneofs().Get(0)
+	fd := m.WhichOneof(od)
+	if fd == nil {
+		return nil
+	}
+	return m.Get(fd).Message().Interface()
+}
+
+// Location returns the value of the location field of msg, unwrapping a
+// *pganalyze.Node, or -1 if the message has no location.
+func Location(msg proto.Message) int32 {
+	if n, ok := msg.(*pganalyze.Node); ok {
+		msg = Unwrap(n)
+	}
+	if msg == nil {
+		return -1
+	}
+	m := msg.ProtoReflect()
+	fd := m.Descriptor().Fields().ByName("location")
+	if !m.IsValid() || fd == nil || fd.Kind() != protoreflect.Int32Kind {
+		return -1
+	}
+	return int32(m.Get(fd).Int()) //nolint:gosec // int32 field
+}
@@ -0,0 +1,90 @@
+package pg_query //nolint:revive // Keep package name aligned with existing public API.
+
+//go:generate go run gen_keywords.go
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+)
+
+// Keyword is a keyword of the Postgres grammar.
+type Keyword struct {
+	// Name is the keyword in lowercase.
+	Name string
+	// Token is the token of the keyword in Scan results.
+	Token pganalyze.Token
+	// Kind is the category of the keyword, which determines where it can be
+	// used as an identifier.
+	Kind pganalyze.KeywordKind
+	// BareLabel is whether the keyword can be used as a column label without
+	// AS, as in SELECT 1 abort.
+	BareLabel bool
+}
+
+// Keywords returns the keywords of Postgres in ASCII order.
+func Keywords() []Keyword {
+	return slices.Clone(keywords)
+}
+
+// LookupKeyword returns the keyword name, matching ASCII letters
+// case-insensitively like the scanner.
+func LookupKeyword(name string) (Keyword, bool) {
+	name = asciiLower(name)
+	i := sort.Search(len(keywords), func(i int) bool { return keywords[i].Name >= name })
+	if i == len(keywords) || keywords[i].Name != name {
+		return Keyword{}, false
+	}
+	return keywords[i], true
+}
+
+// NeedsQuoting returns whether ident must be quoted to be used as an
+// identifier, as by the deparser: unless it consists of lowercase ASCII
+// letters, digits and underscores, does not start with a digit, and is not a
+// keyword other than an unreserved one.
+func NeedsQuoting(ident string) bool {
+	if ident == "" || ident[0] >= '0' && ident[0] <= '9' {
+		return true
+	}
+	for i := range len(ident) {
+		c := ident[i]
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '_' {
+			return true
+		}
+	}
+	kw, ok := LookupKeyword(ident)
+	return ok && kw.Kind != pganalyze.KeywordKind_UNRESERVED_KEYWORD
+}
+
+// QuoteIdentifier returns ident as an identifier, in double quotes if it
+// needs quoting.
+func QuoteIdentifier(ident string) string {
+	if !NeedsQuoting(ident) {
+		return ident
+	}
+	return `"` + strings.ReplaceAll(ident, `"`, `""`) + `"`
+}
+
+// QuoteLiteral returns s as a string constant. Like in the deparser, strings
+// containing backslashes are escape strings, so that they are read the same
+// regardless of standard_conforming_strings.
+func QuoteLiteral(s string) string {
+	if !strings.Contains(s, `\`) {
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	}
+	return "E'" + strings.NewReplacer("'", "''", `\`, `\\`).Replace(s) + "'"
+}
+
+// asciiLower returns s with ASCII letters in lowercase, as the scanner
+// downcases keywords.
+func asciiLower(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c >= 'A' && c <= 'Z' {
+			b[i] = c + 'a' - 'A'
+		}
+	}
+	return string(b)
+}
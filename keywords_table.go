@@ -0,0 +1,500 @@
+// Code generated by gen_keywords.go from Postgres's kwlist.h. DO NOT EDIT.
+
+package pg_query //nolint:revive // Keep package name aligned with existing public API.
+
+import pganalyze "github.com/pganalyze/pg_query_go/v6"
+
+// keywords are the keywords of Postgres in ASCII order.
+var keywords = []Keyword{
+	{"abort", pganalyze.Token_ABORT_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"absent", pganalyze.Token_ABSENT, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"absolute", pganalyze.Token_ABSOLUTE_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"access", pganalyze.Token_ACCESS, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"action", pganalyze.Token_ACTION, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"add", pganalyze.Token_ADD_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"admin", pganalyze.Token_ADMIN, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"after", pganalyze.Token_AFTER, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"aggregate", pganalyze.Token_AGGREGATE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"all", pganalyze.Token_ALL, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"also", pganalyze.Token_ALSO, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"alter", pganalyze.Token_ALTER, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"always", pganalyze.Token_ALWAYS, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"analyse", pganalyze.Token_ANALYSE, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"analyze", pganalyze.Token_ANALYZE, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"and", pganalyze.Token_AND, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"any", pganalyze.Token_ANY, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"array", pganalyze.Token_ARRAY, pganalyze.KeywordKind_RESERVED_KEYWORD, false},
+	{"as", pganalyze.Token_AS, pganalyze.KeywordKind_RESERVED_KEYWORD, false},
+	{"asc", pganalyze.Token_ASC, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"asensitive", pganalyze.Token_ASENSITIVE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"assertion", pganalyze.Token_ASSERTION, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"assignment", pganalyze.Token_ASSIGNMENT, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"asymmetric", pganalyze.Token_ASYMMETRIC, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"at", pganalyze.Token_AT, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"atomic", pganalyze.Token_ATOMIC, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"attach", pganalyze.Token_ATTACH, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"attribute", pganalyze.Token_ATTRIBUTE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"authorization", pganalyze.Token_AUTHORIZATION, pganalyze.KeywordKind_TYPE_FUNC_NAME_KEYWORD, true},
+	{"backward", pganalyze.Token_BACKWARD, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"before", pganalyze.Token_BEFORE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"begin", pganalyze.Token_BEGIN_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"between", pganalyze.Token_BETWEEN, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"bigint", pganalyze.Token_BIGINT, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"binary", pganalyze.Token_BINARY, pganalyze.KeywordKind_TYPE_FUNC_NAME_KEYWORD, true},
+	{"bit", pganalyze.Token_BIT, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"boolean", pganalyze.Token_BOOLEAN_P, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"both", pganalyze.Token_BOTH, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"breadth", pganalyze.Token_BREADTH, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"by", pganalyze.Token_BY, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"cache", pganalyze.Token_CACHE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"call", pganalyze.Token_CALL, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"called", pganalyze.Token_CALLED, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"cascade", pganalyze.Token_CASCADE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"cascaded", pganalyze.Token_CASCADED, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"case", pganalyze.Token_CASE, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"cast", pganalyze.Token_CAST, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"catalog", pganalyze.Token_CATALOG_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"chain", pganalyze.Token_CHAIN, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"char", pganalyze.Token_CHAR_P, pganalyze.KeywordKind_COL_NAME_KEYWORD, false},
+	{"character", pganalyze.Token_CHARACTER, pganalyze.KeywordKind_COL_NAME_KEYWORD, false},
+	{"characteristics", pganalyze.Token_CHARACTERISTICS, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"check", pganalyze.Token_CHECK, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"checkpoint", pganalyze.Token_CHECKPOINT, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"class", pganalyze.Token_CLASS, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"close", pganalyze.Token_CLOSE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"cluster", pganalyze.Token_CLUSTER, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"coalesce", pganalyze.Token_COALESCE, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"collate", pganalyze.Token_COLLATE, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"collation", pganalyze.Token_COLLATION, pganalyze.KeywordKind_TYPE_FUNC_NAME_KEYWORD, true},
+	{"column", pganalyze.Token_COLUMN, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"columns", pganalyze.Token_COLUMNS, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"comment", pganalyze.Token_COMMENT, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"comments", pganalyze.Token_COMMENTS, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"commit", pganalyze.Token_COMMIT, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"committed", pganalyze.Token_COMMITTED, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"compression", pganalyze.Token_COMPRESSION, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"concurrently", pganalyze.Token_CONCURRENTLY, pganalyze.KeywordKind_TYPE_FUNC_NAME_KEYWORD, true},
+	{"conditional", pganalyze.Token_CONDITIONAL, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"configuration", pganalyze.Token_CONFIGURATION, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"conflict", pganalyze.Token_CONFLICT, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"connection", pganalyze.Token_CONNECTION, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"constraint", pganalyze.Token_CONSTRAINT, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"constraints", pganalyze.Token_CONSTRAINTS, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"content", pganalyze.Token_CONTENT_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"continue", pganalyze.Token_CONTINUE_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"conversion", pganalyze.Token_CONVERSION_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"copy", pganalyze.Token_COPY, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"cost", pganalyze.Token_COST, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"create", pganalyze.Token_CREATE, pganalyze.KeywordKind_RESERVED_KEYWORD, false},
+	{"cross", pganalyze.Token_CROSS, pganalyze.KeywordKind_TYPE_FUNC_NAME_KEYWORD, true},
+	{"csv", pganalyze.Token_CSV, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"cube", pganalyze.Token_CUBE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"current", pganalyze.Token_CURRENT_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"current_catalog", pganalyze.Token_CURRENT_CATALOG, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"current_date", pganalyze.Token_CURRENT_DATE, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"current_role", pganalyze.Token_CURRENT_ROLE, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"current_schema", pganalyze.Token_CURRENT_SCHEMA, pganalyze.KeywordKind_TYPE_FUNC_NAME_KEYWORD, true},
+	{"current_time", pganalyze.Token_CURRENT_TIME, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"current_timestamp", pganalyze.Token_CURRENT_TIMESTAMP, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"current_user", pganalyze.Token_CURRENT_USER, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"cursor", pganalyze.Token_CURSOR, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"cycle", pganalyze.Token_CYCLE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"data", pganalyze.Token_DATA_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"database", pganalyze.Token_DATABASE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"day", pganalyze.Token_DAY_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, false},
+	{"deallocate", pganalyze.Token_DEALLOCATE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"dec", pganalyze.Token_DEC, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"decimal", pganalyze.Token_DECIMAL_P, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"declare", pganalyze.Token_DECLARE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"default", pganalyze.Token_DEFAULT, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"defaults", pganalyze.Token_DEFAULTS, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"deferrable", pganalyze.Token_DEFERRABLE, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"deferred", pganalyze.Token_DEFERRED, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"definer", pganalyze.Token_DEFINER, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"delete", pganalyze.Token_DELETE_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"delimiter", pganalyze.Token_DELIMITER, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"delimiters", pganalyze.Token_DELIMITERS, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"depends", pganalyze.Token_DEPENDS, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"depth", pganalyze.Token_DEPTH, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"desc", pganalyze.Token_DESC, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"detach", pganalyze.Token_DETACH, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"dictionary", pganalyze.Token_DICTIONARY, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"disable", pganalyze.Token_DISABLE_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"discard", pganalyze.Token_DISCARD, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"distinct", pganalyze.Token_DISTINCT, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"do", pganalyze.Token_DO, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"document", pganalyze.Token_DOCUMENT_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"domain", pganalyze.Token_DOMAIN_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"double", pganalyze.Token_DOUBLE_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"drop", pganalyze.Token_DROP, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"each", pganalyze.Token_EACH, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"else", pganalyze.Token_ELSE, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"empty", pganalyze.Token_EMPTY_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"enable", pganalyze.Token_ENABLE_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"encoding", pganalyze.Token_ENCODING, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"encrypted", pganalyze.Token_ENCRYPTED, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"end", pganalyze.Token_END_P, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"enum", pganalyze.Token_ENUM_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"error", pganalyze.Token_ERROR_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"escape", pganalyze.Token_ESCAPE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"event", pganalyze.Token_EVENT, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"except", pganalyze.Token_EXCEPT, pganalyze.KeywordKind_RESERVED_KEYWORD, false},
+	{"exclude", pganalyze.Token_EXCLUDE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"excluding", pganalyze.Token_EXCLUDING, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"exclusive", pganalyze.Token_EXCLUSIVE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"execute", pganalyze.Token_EXECUTE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"exists", pganalyze.Token_EXISTS, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"explain", pganalyze.Token_EXPLAIN, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"expression", pganalyze.Token_EXPRESSION, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"extension", pganalyze.Token_EXTENSION, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"external", pganalyze.Token_EXTERNAL, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"extract", pganalyze.Token_EXTRACT, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"false", pganalyze.Token_FALSE_P, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"family", pganalyze.Token_FAMILY, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"fetch", pganalyze.Token_FETCH, pganalyze.KeywordKind_RESERVED_KEYWORD, false},
+	{"filter", pganalyze.Token_FILTER, pganalyze.KeywordKind_UNRESERVED_KEYWORD, false},
+	{"finalize", pganalyze.Token_FINALIZE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"first", pganalyze.Token_FIRST_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"float", pganalyze.Token_FLOAT_P, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"following", pganalyze.Token_FOLLOWING, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"for", pganalyze.Token_FOR, pganalyze.KeywordKind_RESERVED_KEYWORD, false},
+	{"force", pganalyze.Token_FORCE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"foreign", pganalyze.Token_FOREIGN, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"format", pganalyze.Token_FORMAT, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"forward", pganalyze.Token_FORWARD, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"freeze", pganalyze.Token_FREEZE, pganalyze.KeywordKind_TYPE_FUNC_NAME_KEYWORD, true},
+	{"from", pganalyze.Token_FROM, pganalyze.KeywordKind_RESERVED_KEYWORD, false},
+	{"full", pganalyze.Token_FULL, pganalyze.KeywordKind_TYPE_FUNC_NAME_KEYWORD, true},
+	{"function", pganalyze.Token_FUNCTION, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"functions", pganalyze.Token_FUNCTIONS, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"generated", pganalyze.Token_GENERATED, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"global", pganalyze.Token_GLOBAL, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"grant", pganalyze.Token_GRANT, pganalyze.KeywordKind_RESERVED_KEYWORD, false},
+	{"granted", pganalyze.Token_GRANTED, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"greatest", pganalyze.Token_GREATEST, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"group", pganalyze.Token_GROUP_P, pganalyze.KeywordKind_RESERVED_KEYWORD, false},
+	{"grouping", pganalyze.Token_GROUPING, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"groups", pganalyze.Token_GROUPS, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"handler", pganalyze.Token_HANDLER, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"having", pganalyze.Token_HAVING, pganalyze.KeywordKind_RESERVED_KEYWORD, false},
+	{"header", pganalyze.Token_HEADER_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"hold", pganalyze.Token_HOLD, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"hour", pganalyze.Token_HOUR_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, false},
+	{"identity", pganalyze.Token_IDENTITY_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"if", pganalyze.Token_IF_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"ilike", pganalyze.Token_ILIKE, pganalyze.KeywordKind_TYPE_FUNC_NAME_KEYWORD, true},
+	{"immediate", pganalyze.Token_IMMEDIATE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"immutable", pganalyze.Token_IMMUTABLE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"implicit", pganalyze.Token_IMPLICIT_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"import", pganalyze.Token_IMPORT_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"in", pganalyze.Token_IN_P, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"include", pganalyze.Token_INCLUDE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"including", pganalyze.Token_INCLUDING, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"increment", pganalyze.Token_INCREMENT, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"indent", pganalyze.Token_INDENT, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"index", pganalyze.Token_INDEX, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"indexes", pganalyze.Token_INDEXES, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"inherit", pganalyze.Token_INHERIT, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"inherits", pganalyze.Token_INHERITS, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"initially", pganalyze.Token_INITIALLY, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"inline", pganalyze.Token_INLINE_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"inner", pganalyze.Token_INNER_P, pganalyze.KeywordKind_TYPE_FUNC_NAME_KEYWORD, true},
+	{"inout", pganalyze.Token_INOUT, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"input", pganalyze.Token_INPUT_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"insensitive", pganalyze.Token_INSENSITIVE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"insert", pganalyze.Token_INSERT, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"instead", pganalyze.Token_INSTEAD, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"int", pganalyze.Token_INT_P, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"integer", pganalyze.Token_INTEGER, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"intersect", pganalyze.Token_INTERSECT, pganalyze.KeywordKind_RESERVED_KEYWORD, false},
+	{"interval", pganalyze.Token_INTERVAL, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"into", pganalyze.Token_INTO, pganalyze.KeywordKind_RESERVED_KEYWORD, false},
+	{"invoker", pganalyze.Token_INVOKER, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"is", pganalyze.Token_IS, pganalyze.KeywordKind_TYPE_FUNC_NAME_KEYWORD, true},
+	{"isnull", pganalyze.Token_ISNULL, pganalyze.KeywordKind_TYPE_FUNC_NAME_KEYWORD, false},
+	{"isolation", pganalyze.Token_ISOLATION, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"join", pganalyze.Token_JOIN, pganalyze.KeywordKind_TYPE_FUNC_NAME_KEYWORD, true},
+	{"json", pganalyze.Token_JSON, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"json_array", pganalyze.Token_JSON_ARRAY, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"json_arrayagg", pganalyze.Token_JSON_ARRAYAGG, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"json_exists", pganalyze.Token_JSON_EXISTS, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"json_object", pganalyze.Token_JSON_OBJECT, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"json_objectagg", pganalyze.Token_JSON_OBJECTAGG, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"json_query", pganalyze.Token_JSON_QUERY, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"json_scalar", pganalyze.Token_JSON_SCALAR, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"json_serialize", pganalyze.Token_JSON_SERIALIZE, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"json_table", pganalyze.Token_JSON_TABLE, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"json_value", pganalyze.Token_JSON_VALUE, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"keep", pganalyze.Token_KEEP, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"key", pganalyze.Token_KEY, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"keys", pganalyze.Token_KEYS, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"label", pganalyze.Token_LABEL, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"language", pganalyze.Token_LANGUAGE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"large", pganalyze.Token_LARGE_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"last", pganalyze.Token_LAST_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"lateral", pganalyze.Token_LATERAL_P, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"leading", pganalyze.Token_LEADING, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"leakproof", pganalyze.Token_LEAKPROOF, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"least", pganalyze.Token_LEAST, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"left", pganalyze.Token_LEFT, pganalyze.KeywordKind_TYPE_FUNC_NAME_KEYWORD, true},
+	{"level", pganalyze.Token_LEVEL, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"like", pganalyze.Token_LIKE, pganalyze.KeywordKind_TYPE_FUNC_NAME_KEYWORD, true},
+	{"limit", pganalyze.Token_LIMIT, pganalyze.KeywordKind_RESERVED_KEYWORD, false},
+	{"listen", pganalyze.Token_LISTEN, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"load", pganalyze.Token_LOAD, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"local", pganalyze.Token_LOCAL, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"localtime", pganalyze.Token_LOCALTIME, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"localtimestamp", pganalyze.Token_LOCALTIMESTAMP, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"location", pganalyze.Token_LOCATION, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"lock", pganalyze.Token_LOCK_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"locked", pganalyze.Token_LOCKED, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"logged", pganalyze.Token_LOGGED, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"mapping", pganalyze.Token_MAPPING, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"match", pganalyze.Token_MATCH, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"matched", pganalyze.Token_MATCHED, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"materialized", pganalyze.Token_MATERIALIZED, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"maxvalue", pganalyze.Token_MAXVALUE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"merge", pganalyze.Token_MERGE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"merge_action", pganalyze.Token_MERGE_ACTION, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"method", pganalyze.Token_METHOD, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"minute", pganalyze.Token_MINUTE_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, false},
+	{"minvalue", pganalyze.Token_MINVALUE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"mode", pganalyze.Token_MODE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"month", pganalyze.Token_MONTH_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, false},
+	{"move", pganalyze.Token_MOVE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"name", pganalyze.Token_NAME_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"names", pganalyze.Token_NAMES, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"national", pganalyze.Token_NATIONAL, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"natural", pganalyze.Token_NATURAL, pganalyze.KeywordKind_TYPE_FUNC_NAME_KEYWORD, true},
+	{"nchar", pganalyze.Token_NCHAR, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"nested", pganalyze.Token_NESTED, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"new", pganalyze.Token_NEW, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"next", pganalyze.Token_NEXT, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"nfc", pganalyze.Token_NFC, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"nfd", pganalyze.Token_NFD, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"nfkc", pganalyze.Token_NFKC, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"nfkd", pganalyze.Token_NFKD, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"no", pganalyze.Token_NO, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"none", pganalyze.Token_NONE, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"normalize", pganalyze.Token_NORMALIZE, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"normalized", pganalyze.Token_NORMALIZED, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"not", pganalyze.Token_NOT, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"nothing", pganalyze.Token_NOTHING, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"notify", pganalyze.Token_NOTIFY, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"notnull", pganalyze.Token_NOTNULL, pganalyze.KeywordKind_TYPE_FUNC_NAME_KEYWORD, false},
+	{"nowait", pganalyze.Token_NOWAIT, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"null", pganalyze.Token_NULL_P, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"nullif", pganalyze.Token_NULLIF, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"nulls", pganalyze.Token_NULLS_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"numeric", pganalyze.Token_NUMERIC, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"object", pganalyze.Token_OBJECT_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"of", pganalyze.Token_OF, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"off", pganalyze.Token_OFF, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"offset", pganalyze.Token_OFFSET, pganalyze.KeywordKind_RESERVED_KEYWORD, false},
+	{"oids", pganalyze.Token_OIDS, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"old", pganalyze.Token_OLD, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"omit", pganalyze.Token_OMIT, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"on", pganalyze.Token_ON, pganalyze.KeywordKind_RESERVED_KEYWORD, false},
+	{"only", pganalyze.Token_ONLY, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"operator", pganalyze.Token_OPERATOR, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"option", pganalyze.Token_OPTION, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"options", pganalyze.Token_OPTIONS, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"or", pganalyze.Token_OR, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"order", pganalyze.Token_ORDER, pganalyze.KeywordKind_RESERVED_KEYWORD, false},
+	{"ordinality", pganalyze.Token_ORDINALITY, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"others", pganalyze.Token_OTHERS, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"out", pganalyze.Token_OUT_P, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"outer", pganalyze.Token_OUTER_P, pganalyze.KeywordKind_TYPE_FUNC_NAME_KEYWORD, true},
+	{"over", pganalyze.Token_OVER, pganalyze.KeywordKind_UNRESERVED_KEYWORD, false},
+	{"overlaps", pganalyze.Token_OVERLAPS, pganalyze.KeywordKind_TYPE_FUNC_NAME_KEYWORD, false},
+	{"overlay", pganalyze.Token_OVERLAY, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"overriding", pganalyze.Token_OVERRIDING, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"owned", pganalyze.Token_OWNED, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"owner", pganalyze.Token_OWNER, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"parallel", pganalyze.Token_PARALLEL, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"parameter", pganalyze.Token_PARAMETER, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"parser", pganalyze.Token_PARSER, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"partial", pganalyze.Token_PARTIAL, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"partition", pganalyze.Token_PARTITION, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"passing", pganalyze.Token_PASSING, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"password", pganalyze.Token_PASSWORD, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"path", pganalyze.Token_PATH, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"placing", pganalyze.Token_PLACING, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"plan", pganalyze.Token_PLAN, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"plans", pganalyze.Token_PLANS, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"policy", pganalyze.Token_POLICY, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"position", pganalyze.Token_POSITION, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"preceding", pganalyze.Token_PRECEDING, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"precision", pganalyze.Token_PRECISION, pganalyze.KeywordKind_COL_NAME_KEYWORD, false},
+	{"prepare", pganalyze.Token_PREPARE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"prepared", pganalyze.Token_PREPARED, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"preserve", pganalyze.Token_PRESERVE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"primary", pganalyze.Token_PRIMARY, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"prior", pganalyze.Token_PRIOR, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"privileges", pganalyze.Token_PRIVILEGES, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"procedural", pganalyze.Token_PROCEDURAL, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"procedure", pganalyze.Token_PROCEDURE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"procedures", pganalyze.Token_PROCEDURES, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"program", pganalyze.Token_PROGRAM, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"publication", pganalyze.Token_PUBLICATION, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"quote", pganalyze.Token_QUOTE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"quotes", pganalyze.Token_QUOTES, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"range", pganalyze.Token_RANGE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"read", pganalyze.Token_READ, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"real", pganalyze.Token_REAL, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"reassign", pganalyze.Token_REASSIGN, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"recheck", pganalyze.Token_RECHECK, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"recursive", pganalyze.Token_RECURSIVE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"ref", pganalyze.Token_REF_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"references", pganalyze.Token_REFERENCES, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"referencing", pganalyze.Token_REFERENCING, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"refresh", pganalyze.Token_REFRESH, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"reindex", pganalyze.Token_REINDEX, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"relative", pganalyze.Token_RELATIVE_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"release", pganalyze.Token_RELEASE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"rename", pganalyze.Token_RENAME, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"repeatable", pganalyze.Token_REPEATABLE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"replace", pganalyze.Token_REPLACE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"replica", pganalyze.Token_REPLICA, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"reset", pganalyze.Token_RESET, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"restart", pganalyze.Token_RESTART, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"restrict", pganalyze.Token_RESTRICT, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"return", pganalyze.Token_RETURN, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"returning", pganalyze.Token_RETURNING, pganalyze.KeywordKind_RESERVED_KEYWORD, false},
+	{"returns", pganalyze.Token_RETURNS, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"revoke", pganalyze.Token_REVOKE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"right", pganalyze.Token_RIGHT, pganalyze.KeywordKind_TYPE_FUNC_NAME_KEYWORD, true},
+	{"role", pganalyze.Token_ROLE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"rollback", pganalyze.Token_ROLLBACK, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"rollup", pganalyze.Token_ROLLUP, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"routine", pganalyze.Token_ROUTINE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"routines", pganalyze.Token_ROUTINES, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"row", pganalyze.Token_ROW, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"rows", pganalyze.Token_ROWS, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"rule", pganalyze.Token_RULE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"savepoint", pganalyze.Token_SAVEPOINT, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"scalar", pganalyze.Token_SCALAR, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"schema", pganalyze.Token_SCHEMA, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"schemas", pganalyze.Token_SCHEMAS, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"scroll", pganalyze.Token_SCROLL, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"search", pganalyze.Token_SEARCH, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"second", pganalyze.Token_SECOND_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, false},
+	{"security", pganalyze.Token_SECURITY, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"select", pganalyze.Token_SELECT, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"sequence", pganalyze.Token_SEQUENCE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"sequences", pganalyze.Token_SEQUENCES, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"serializable", pganalyze.Token_SERIALIZABLE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"server", pganalyze.Token_SERVER, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"session", pganalyze.Token_SESSION, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"session_user", pganalyze.Token_SESSION_USER, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"set", pganalyze.Token_SET, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"setof", pganalyze.Token_SETOF, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"sets", pganalyze.Token_SETS, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"share", pganalyze.Token_SHARE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"show", pganalyze.Token_SHOW, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"similar", pganalyze.Token_SIMILAR, pganalyze.KeywordKind_TYPE_FUNC_NAME_KEYWORD, true},
+	{"simple", pganalyze.Token_SIMPLE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"skip", pganalyze.Token_SKIP, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"smallint", pganalyze.Token_SMALLINT, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"snapshot", pganalyze.Token_SNAPSHOT, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"some", pganalyze.Token_SOME, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"source", pganalyze.Token_SOURCE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"sql", pganalyze.Token_SQL_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"stable", pganalyze.Token_STABLE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"standalone", pganalyze.Token_STANDALONE_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"start", pganalyze.Token_START, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"statement", pganalyze.Token_STATEMENT, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"statistics", pganalyze.Token_STATISTICS, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"stdin", pganalyze.Token_STDIN, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"stdout", pganalyze.Token_STDOUT, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"storage", pganalyze.Token_STORAGE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"stored", pganalyze.Token_STORED, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"strict", pganalyze.Token_STRICT_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"string", pganalyze.Token_STRING_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"strip", pganalyze.Token_STRIP_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"subscription", pganalyze.Token_SUBSCRIPTION, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"substring", pganalyze.Token_SUBSTRING, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"support", pganalyze.Token_SUPPORT, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"symmetric", pganalyze.Token_SYMMETRIC, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"sysid", pganalyze.Token_SYSID, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"system", pganalyze.Token_SYSTEM_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"system_user", pganalyze.Token_SYSTEM_USER, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"table", pganalyze.Token_TABLE, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"tables", pganalyze.Token_TABLES, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"tablesample", pganalyze.Token_TABLESAMPLE, pganalyze.KeywordKind_TYPE_FUNC_NAME_KEYWORD, true},
+	{"tablespace", pganalyze.Token_TABLESPACE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"target", pganalyze.Token_TARGET, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"temp", pganalyze.Token_TEMP, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"template", pganalyze.Token_TEMPLATE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"temporary", pganalyze.Token_TEMPORARY, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"text", pganalyze.Token_TEXT_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"then", pganalyze.Token_THEN, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"ties", pganalyze.Token_TIES, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"time", pganalyze.Token_TIME, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"timestamp", pganalyze.Token_TIMESTAMP, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"to", pganalyze.Token_TO, pganalyze.KeywordKind_RESERVED_KEYWORD, false},
+	{"trailing", pganalyze.Token_TRAILING, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"transaction", pganalyze.Token_TRANSACTION, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"transform", pganalyze.Token_TRANSFORM, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"treat", pganalyze.Token_TREAT, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"trigger", pganalyze.Token_TRIGGER, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"trim", pganalyze.Token_TRIM, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"true", pganalyze.Token_TRUE_P, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"truncate", pganalyze.Token_TRUNCATE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"trusted", pganalyze.Token_TRUSTED, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"type", pganalyze.Token_TYPE_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"types", pganalyze.Token_TYPES_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"uescape", pganalyze.Token_UESCAPE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"unbounded", pganalyze.Token_UNBOUNDED, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"uncommitted", pganalyze.Token_UNCOMMITTED, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"unconditional", pganalyze.Token_UNCONDITIONAL, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"unencrypted", pganalyze.Token_UNENCRYPTED, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"union", pganalyze.Token_UNION, pganalyze.KeywordKind_RESERVED_KEYWORD, false},
+	{"unique", pganalyze.Token_UNIQUE, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"unknown", pganalyze.Token_UNKNOWN, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"unlisten", pganalyze.Token_UNLISTEN, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"unlogged", pganalyze.Token_UNLOGGED, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"until", pganalyze.Token_UNTIL, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"update", pganalyze.Token_UPDATE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"user", pganalyze.Token_USER, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"using", pganalyze.Token_USING, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"vacuum", pganalyze.Token_VACUUM, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"valid", pganalyze.Token_VALID, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"validate", pganalyze.Token_VALIDATE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"validator", pganalyze.Token_VALIDATOR, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"value", pganalyze.Token_VALUE_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"values", pganalyze.Token_VALUES, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"varchar", pganalyze.Token_VARCHAR, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"variadic", pganalyze.Token_VARIADIC, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"varying", pganalyze.Token_VARYING, pganalyze.KeywordKind_UNRESERVED_KEYWORD, false},
+	{"verbose", pganalyze.Token_VERBOSE, pganalyze.KeywordKind_TYPE_FUNC_NAME_KEYWORD, true},
+	{"version", pganalyze.Token_VERSION_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"view", pganalyze.Token_VIEW, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"views", pganalyze.Token_VIEWS, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"volatile", pganalyze.Token_VOLATILE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"when", pganalyze.Token_WHEN, pganalyze.KeywordKind_RESERVED_KEYWORD, true},
+	{"where", pganalyze.Token_WHERE, pganalyze.KeywordKind_RESERVED_KEYWORD, false},
+	{"whitespace", pganalyze.Token_WHITESPACE_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"window", pganalyze.Token_WINDOW, pganalyze.KeywordKind_RESERVED_KEYWORD, false},
+	{"with", pganalyze.Token_WITH, pganalyze.KeywordKind_RESERVED_KEYWORD, false},
+	{"within", pganalyze.Token_WITHIN, pganalyze.KeywordKind_UNRESERVED_KEYWORD, false},
+	{"without", pganalyze.Token_WITHOUT, pganalyze.KeywordKind_UNRESERVED_KEYWORD, false},
+	{"work", pganalyze.Token_WORK, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"wrapper", pganalyze.Token_WRAPPER, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"write", pganalyze.Token_WRITE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"xml", pganalyze.Token_XML_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"xmlattributes", pganalyze.Token_XMLATTRIBUTES, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"xmlconcat", pganalyze.Token_XMLCONCAT, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"xmlelement", pganalyze.Token_XMLELEMENT, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"xmlexists", pganalyze.Token_XMLEXISTS, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"xmlforest", pganalyze.Token_XMLFOREST, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"xmlnamespaces", pganalyze.Token_XMLNAMESPACES, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"xmlparse", pganalyze.Token_XMLPARSE, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"xmlpi", pganalyze.Token_XMLPI, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"xmlroot", pganalyze.Token_XMLROOT, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"xmlserialize", pganalyze.Token_XMLSERIALIZE, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"xmltable", pganalyze.Token_XMLTABLE, pganalyze.KeywordKind_COL_NAME_KEYWORD, true},
+	{"year", pganalyze.Token_YEAR_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, false},
+	{"yes", pganalyze.Token_YES_P, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+	{"zone", pganalyze.Token_ZONE, pganalyze.KeywordKind_UNRESERVED_KEYWORD, true},
+}
@@ -0,0 +1,155 @@
+package pg_query_test
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+)
+
+// kwlist returns the entries of the keyword list of Postgres as name, token,
+// category and label status.
+func kwlist(t *testing.T) [][]string {
+	t.Helper()
+	src, err := os.ReadFile("internal/cparser/include/postgres/parser/kwlist.h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries [][]string
+	for _, m := range regexp.MustCompile(`(?m)^PG_KEYWORD\("(\w+)", (\w+), (\w+), (\w+)\)`).FindAllStringSubmatch(string(src), -1) {
+		entries = append(entries, m[1:])
+	}
+	if len(entries) < 400 {
+		t.Fatalf("only %d keywords in kwlist.h", len(entries))
+	}
+	return entries
+}
+
+func TestKeywords(t *testing.T) {
+	var got [][]string
+	for _, kw := range pg_query.Keywords() {
+		label := "AS_LABEL"
+		if kw.BareLabel {
+			label = "BARE_LABEL"
+		}
+		got = append(got, []string{kw.Name, strings.TrimPrefix(kw.Token.String(), "Token_"), kw.Kind.String(), label})
+	}
+	if diff := cmp.Diff(kwlist(t), got); diff != "" {
+		t.Errorf("keywords mismatch (-kwlist.h +got):\n%s", diff)
+	}
+}
+
+func TestKeywordsScan(t *testing.T) {
+	for _, kw := range pg_query.Keywords() {
+		for _, name := range []string{kw.Name, strings.ToUpper(kw.Name)} {
+			res, err := pg_query.Scan(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tokens := res.GetTokens()
+			if len(tokens) != 1 || tokens[0].GetToken() != kw.Token || tokens[0].GetKeywordKind() != kw.Kind {
+				t.Errorf("Scan(%s) = %v, want %v %v", name, tokens, kw.Token, kw.Kind)
+			}
+		}
+	}
+}
+
+func TestLookupKeyword(t *testing.T) {
+	tests := []struct {
+		name string
+		want pganalyze.Token
+		ok   bool
+	}{
+		{"select", pganalyze.Token_SELECT, true},
+		{"SeLeCt", pganalyze.Token_SELECT, true},
+		{"abort", pganalyze.Token_ABORT_P, true},
+		{"zone", pganalyze.Token_ZONE, true},
+		{"selects", 0, false},
+		{"selec", 0, false},
+		{"", 0, false},
+		{"users", 0, false},
+	}
+	for _, tc := range tests {
+		kw, ok := pg_query.LookupKeyword(tc.name)
+		if ok != tc.ok || kw.Token != tc.want {
+			t.Errorf("LookupKeyword(%q) = %v, %t, want %v, %t", tc.name, kw.Token, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+// deparseColumn returns the deparsed form of a column named name.
+func deparseColumn(t *testing.T, name string) string {
+	t.Helper()
+	tree, err := pg_query.Parse("SELECT x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree.GetStmts()[0].GetStmt().GetSelectStmt().GetTargetList()[0].GetResTarget().GetVal().GetColumnRef().GetFields()[0].GetString_().Sval = name
+	out, err := pg_query.Deparse(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimPrefix(out, "SELECT ")
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	names := []string{
+		"users", "_x1", "a$b", "Users", "1a", "a b", `a"b`, `"`, "ünïcode", "select_", "",
+	}
+	for _, kw := range pg_query.Keywords() {
+		names = append(names, kw.Name)
+	}
+	for _, name := range names {
+		got := pg_query.QuoteIdentifier(name)
+		if name != "" {
+			if want := deparseColumn(t, name); got != want {
+				t.Errorf("QuoteIdentifier(%q) = %s, deparsed %s", name, got, want)
+			}
+		}
+		if needs := pg_query.NeedsQuoting(name); needs != (got != name) {
+			t.Errorf("NeedsQuoting(%q) = %t, quoted %s", name, needs, got)
+		}
+	}
+
+	for _, kw := range pg_query.Keywords() {
+		if want := kw.Kind != pganalyze.KeywordKind_UNRESERVED_KEYWORD; pg_query.NeedsQuoting(kw.Name) != want {
+			t.Errorf("NeedsQuoting(%q) = %t for %v", kw.Name, !want, kw.Kind)
+		}
+	}
+}
+
+func TestQuoteLiteral(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", "''"},
+		{"abc", "'abc'"},
+		{"it's", "'it''s'"},
+		{`a\b`, `E'a\\b'`},
+		{`'\'`, `E'''\\'''`},
+		{"line\nbreak", "'line\nbreak'"},
+	}
+	for _, tc := range tests {
+		got := pg_query.QuoteLiteral(tc.input)
+		if got != tc.want {
+			t.Errorf("QuoteLiteral(%q) = %s, want %s", tc.input, got, tc.want)
+		}
+
+		tree, err := pg_query.Parse("SELECT " + got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sval := tree.GetStmts()[0].GetStmt().GetSelectStmt().GetTargetList()[0].GetResTarget().GetVal().GetAConst().GetSval().GetSval(); sval != tc.input {
+			t.Errorf("QuoteLiteral(%q) parses as %q", tc.input, sval)
+		}
+		if deparsed, err := pg_query.Deparse(tree); err != nil || deparsed != "SELECT "+got {
+			t.Errorf("QuoteLiteral(%q) = %s, deparsed %s", tc.input, got, deparsed)
+		}
+	}
+}
@@ -0,0 +1,688 @@
+// Package lineage computes column-level data lineage for statements that write
+// the result of a query, such as INSERT ... SELECT, CREATE TABLE AS and views.
+//
+// Lineage is direct: a target column is attributed the input columns whose
+// values flow into it through expressions, CTEs, subqueries and set
+// operations. Columns only used for filtering, joining, grouping or ordering
+// are not included.
+package lineage
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/wasilibs/go-pgquery/analysis"
+	"github.com/wasilibs/go-pgquery/internal/naming"
+	"github.com/wasilibs/go-pgquery/internal/walk"
+)
+
+// Kind is the kind of statement lineage was computed for.
+type Kind int
+
+const (
+	// KindInsert is an INSERT statement.
+	KindInsert Kind = iota + 1
+	// KindUpdate is an UPDATE statement.
+	KindUpdate
+	// KindCreateTableAs is a CREATE TABLE AS or SELECT INTO statement.
+	KindCreateTableAs
+	// KindCreateMaterializedView is a CREATE MATERIALIZED VIEW statement.
+	KindCreateMaterializedView
+	// KindCreateView is a CREATE VIEW statement.
+	KindCreateView
+)
+
+// String returns the SQL command name of the kind.
+func (k Kind) String() string {
+	switch k {
+	case KindInsert:
+		return "INSERT"
+	case KindUpdate:
+		return "UPDATE"
+	case KindCreateTableAs:
+		return "CREATE TABLE AS"
+	case KindCreateMaterializedView:
+		return "CREATE MATERIALIZED VIEW"
+	case KindCreateView:
+		return "CREATE VIEW"
+	}
+	return "UNKNOWN"
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (k Kind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Column is a column of a relation.
+type Column struct {
+	Schema string
+	Table  string
+	// Name is the name of the column, or "*" for all columns of a relation
+	// whose columns are not known.
+	Name string
+}
+
+// String returns the column in schema.table.column form, omitting the schema
+// if empty.
+func (c Column) String() string {
+	return analysis.RelationName{Schema: c.Schema, Name: c.Table}.String() + "." + c.Name
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (c Column) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// Mapping is the lineage of a target column.
+type Mapping struct {
+	// Position is the 1-based position of the column among the targets of the
+	// statement.
+	Position int `json:"position"`
+	// Name is the name of the target column. It is empty for an INSERT without a
+	// column list into a relation whose columns are not known.
+	Name string `json:"name,omitempty"`
+	// Sources are the input columns the target column is computed from, sorted
+	// and without duplicates.
+	Sources []Column `json:"sources"`
+}
+
+// Statement is the lineage of a statement.
+type Statement struct {
+	// Stmt is the index of the statement in ParseResult.Stmts.
+	Stmt int `json:"stmt"`
+	// Kind is the kind of the statement.
+	Kind Kind `json:"kind"`
+	// Target is the relation the statement writes to.
+	Target analysis.RelationName `json:"target"`
+	// Columns are the lineage of each target column in order.
+	Columns []Mapping `json:"columns"`
+}
+
+// Analyze returns the lineage of the statements in tree that write the result of
+// a query. Other statements are ignored.
+func Analyze(tree *pganalyze.ParseResult) []Statement {
+	return AnalyzeWithSchema(tree, nil)
+}
+
+// AnalyzeWithSchema is like Analyze but uses schema, which may be nil, to expand
+// star references and name the target columns of an INSERT without a column
+// list. Without a schema, a star reference to a relation is reported as a
+// single column named "*".
+func AnalyzeWithSchema(tree *pganalyze.ParseResult, schema analysis.Schema) []Statement {
+	a := &analyzer{schema: schema}
+	var res []Statement
+	for i, raw := range tree.GetStmts() {
+		if st, ok := a.statement(raw.GetStmt()); ok {
+			st.Stmt = i
+			res = append(res, st)
+		}
+	}
+	return res
+}
+
+type analyzer struct {
+	schema analysis.Schema
+}
+
+// set is a set of input columns.
+type set map[Column]struct{}
+
+func (s set) add(o set) {
+	for c := range o {
+		s[c] = struct{}{}
+	}
+}
+
+func (s set) sorted() []Column {
+	res := make([]Column, 0, len(s))
+	for c := range s {
+		res = append(res, c)
+	}
+	slices.SortFunc(res, func(a, b Column) int {
+		return cmp.Or(cmp.Compare(a.Schema, b.Schema), cmp.Compare(a.Table, b.Table), cmp.Compare(a.Name, b.Name))
+	})
+	return res
+}
+
+// output is an output column of a query.
+type output struct {
+	name    string
+	sources set
+}
+
+type scope struct {
+	parent  *scope
+	ctes    map[string][]output
+	sources []*source
+}
+
+// source is an item of a FROM clause.
+type source struct {
+	refname string
+	// table is set for a relation, whose columns are inputs.
+	table *analysis.RelationName
+	// columns are the columns of the source, nil if not known.
+	columns []output
+	// joined are the sources of a join, set for an aliased join.
+	joined []*source
+}
+
+func (s *source) column(name string) (set, bool) {
+	for _, c := range s.columns {
+		if c.name == name {
+			return c.sources, true
+		}
+	}
+	if s.columns == nil && s.table != nil {
+		return set{{Schema: s.table.Schema, Table: s.table.Name, Name: name}: {}}, true
+	}
+	if s.columns == nil && s.joined != nil {
+		res, found := set{}, false
+		for _, src := range s.joined {
+			if cols, ok := src.column(name); ok {
+				res.add(cols)
+				found = true
+			}
+		}
+		return res, found
+	}
+	return nil, s.columns == nil
+}
+
+func (sc *scope) cte(name string) ([]output, bool) {
+	for ; sc != nil; sc = sc.parent {
+		if cols, ok := sc.ctes[name]; ok {
+			return cols, true
+		}
+	}
+	return nil, false
+}
+
+func (a *analyzer) statement(n *pganalyze.Node) (Statement, bool) {
+	switch s := walk.Unwrap(n).(type) {
+	case *pganalyze.InsertStmt:
+		return a.insertStmt(s), true
+	case *pganalyze.UpdateStmt:
+		return a.updateStmt(s), true
+	case *pganalyze.CreateTableAsStmt:
+		kind := KindCreateTableAs
+		switch s.GetObjtype() {
+		case pganalyze.ObjectType_OBJECT_TABLE:
+		case pganalyze.ObjectType_OBJECT_MATVIEW:
+			kind = KindCreateMaterializedView
+		default:
+			return Statement{}, false
+		}
+		into := s.GetInto()
+		return a.derived(kind, into.GetRel(), s.GetQuery(), stringList(into.GetColNames())), true
+	case *pganalyze.ViewStmt:
+		return a.derived(KindCreateView, s.GetView(), s.GetQuery(), stringList(s.GetAliases())), true
+	}
+	return Statement{}, false
+}
+
+func (a *analyzer) derived(kind Kind, rv *pganalyze.RangeVar, query *pganalyze.Node, aliases []string) Statement {
+	outs := a.query(query, nil)
+	for i, name := range aliases {
+		if i < len(outs) {
+			outs[i].name = name
+		}
+	}
+	st := Statement{Kind: kind, Target: relationName(rv)}
+	for i, o := range outs {
+		st.Columns = append(st.Columns, Mapping{Position: i + 1, Name: o.name, Sources: o.sources.sorted()})
+	}
+	return st
+}
+
+func (a *analyzer) insertStmt(s *pganalyze.InsertStmt) Statement {
+	st := Statement{Kind: KindInsert, Target: relationName(s.GetRelation())}
+
+	var names []string
+	for _, n := range s.GetCols() {
+		names = append(names, n.GetResTarget().GetName())
+	}
+	if names == nil && a.schema != nil {
+		names, _ = a.schema.Columns(st.Target)
+	}
+
+	sc := &scope{}
+	a.withClause(s.GetWithClause(), sc)
+	var outs []output
+	if s.GetSelectStmt() != nil {
+		outs = a.query(s.GetSelectStmt(), sc)
+	}
+	for i, o := range outs {
+		m := Mapping{Position: i + 1, Sources: o.sources.sorted()}
+		if i < len(names) {
+			m.Name = names[i]
+		}
+		st.Columns = append(st.Columns, m)
+	}
+	return st
+}
+
+func (a *analyzer) updateStmt(s *pganalyze.UpdateStmt) Statement {
+	st := Statement{Kind: KindUpdate, Target: relationName(s.GetRelation())}
+
+	sc := &scope{}
+	a.withClause(s.GetWithClause(), sc)
+	sc.sources = append(sc.sources, a.rangeVar(s.GetRelation(), sc))
+	for _, n := range s.GetFromClause() {
+		a.fromItem(n, sc)
+	}
+
+	for i, n := range s.GetTargetList() {
+		rt := n.GetResTarget()
+		var sources set
+		if mar := rt.GetVal().GetMultiAssignRef(); mar != nil {
+			sources = a.multiAssign(mar, sc)
+		} else {
+			sources = a.expr(rt.GetVal(), sc)
+		}
+		st.Columns = append(st.Columns, Mapping{Position: i + 1, Name: rt.GetName(), Sources: sources.sorted()})
+	}
+	return st
+}
+
+// multiAssign returns the sources of one column of SET (a, b) = source.
+func (a *analyzer) multiAssign(mar *pganalyze.MultiAssignRef, sc *scope) set {
+	idx := int(mar.GetColno()) - 1
+	switch src := walk.Unwrap(mar.GetSource()).(type) {
+	case *pganalyze.RowExpr:
+		if idx < len(src.GetArgs()) {
+			return a.expr(src.GetArgs()[idx], sc)
+		}
+	case *pganalyze.SubLink:
+		outs := a.query(src.GetSubselect(), sc)
+		if idx < len(outs) {
+			return outs[idx].sources
+		}
+	}
+	return a.expr(mar.GetSource(), sc)
+}
+
+func (a *analyzer) withClause(w *pganalyze.WithClause, sc *scope) {
+	if w == nil {
+		return
+	}
+	if sc.ctes == nil {
+		sc.ctes = map[string][]output{}
+	}
+	for _, n := range w.GetCtes() {
+		cte := n.GetCommonTableExpr()
+		name := cte.GetCtename()
+		aliases := stringList(cte.GetAliascolnames())
+		compute := func() []output {
+			outs := a.query(cte.GetCtequery(), sc)
+			for i, alias := range aliases {
+				if i < len(outs) {
+					outs[i].name = alias
+				}
+			}
+			return outs
+		}
+
+		if !w.GetRecursive() {
+			sc.ctes[name] = compute()
+			continue
+		}
+		// The recursive term refers to the CTE itself, so iterate until the
+		// sources no longer grow, starting from the non-recursive term.
+		if sel := cte.GetCtequery().GetSelectStmt(); sel != nil && sel.GetOp() != pganalyze.SetOperation_SETOP_NONE {
+			sc.ctes[name] = a.selectStmt(sel.GetLarg(), sc)
+		}
+		for {
+			prev := size(sc.ctes[name])
+			sc.ctes[name] = compute()
+			if size(sc.ctes[name]) == prev {
+				break
+			}
+		}
+	}
+}
+
+func size(outs []output) int {
+	n := len(outs)
+	for _, o := range outs {
+		n += len(o.sources)
+	}
+	return n
+}
+
+// query returns the output columns of a query.
+func (a *analyzer) query(n *pganalyze.Node, parent *scope) []output {
+	if s := n.GetSelectStmt(); s != nil {
+		return a.selectStmt(s, parent)
+	}
+	return nil
+}
+
+func (a *analyzer) selectStmt(s *pganalyze.SelectStmt, parent *scope) []output {
+	sc := &scope{parent: parent}
+	a.withClause(s.GetWithClause(), sc)
+
+	if s.GetOp() != pganalyze.SetOperation_SETOP_NONE {
+		left := a.selectStmt(s.GetLarg(), sc)
+		right := a.selectStmt(s.GetRarg(), sc)
+		for i := range left {
+			if i < len(right) {
+				left[i].sources.add(right[i].sources)
+			}
+		}
+		return left
+	}
+
+	if rows := s.GetValuesLists(); len(rows) > 0 {
+		var outs []output
+		for _, row := range rows {
+			for i, v := range row.GetList().GetItems() {
+				if i == len(outs) {
+					outs = append(outs, output{name: "column" + strconv.Itoa(i+1), sources: set{}})
+				}
+				outs[i].sources.add(a.expr(v, sc))
+			}
+		}
+		return outs
+	}
+
+	for _, n := range s.GetFromClause() {
+		a.fromItem(n, sc)
+	}
+
+	var outs []output
+	for _, n := range s.GetTargetList() {
+		rt := n.GetResTarget()
+		if cr := rt.GetVal().GetColumnRef(); cr != nil && isStar(cr) {
+			outs = append(outs, a.expandStar(cr, sc)...)
+			continue
+		}
+		outs = append(outs, output{name: naming.OutputName(rt), sources: a.expr(rt.GetVal(), sc)})
+	}
+	return outs
+}
+
+// fromItem adds the sources of a FROM item to sc, returning the source the item
+// produces. The source of an unaliased join is not added, as its columns are
+// referred to through the joined sources.
+func (a *analyzer) fromItem(n *pganalyze.Node, sc *scope) *source {
+	var src *source
+	switch f := walk.Unwrap(n).(type) {
+	case *pganalyze.RangeVar:
+		src = a.rangeVar(f, sc)
+	case *pganalyze.RangeSubselect:
+		// Without LATERAL, only the CTEs of the query are visible, not its
+		// other FROM items.
+		parent := &scope{parent: sc.parent, ctes: sc.ctes}
+		if f.GetLateral() {
+			parent = sc
+		}
+		src = &source{refname: f.GetAlias().GetAliasname(), columns: a.query(f.GetSubquery(), parent)}
+		if src.columns == nil {
+			src.columns = []output{}
+		}
+		renameColumns(src, f.GetAlias())
+	case *pganalyze.RangeFunction:
+		// Function results are not traced to their arguments, so the columns
+		// of the source are unknown.
+		src = &source{refname: f.GetAlias().GetAliasname()}
+		if src.refname == "" {
+			src.refname = naming.FunctionName(f)
+		}
+	case *pganalyze.JoinExpr:
+		left := a.fromItem(f.GetLarg(), sc)
+		right := a.fromItem(f.GetRarg(), sc)
+		join := &source{joined: []*source{left, right}, columns: joinColumns(f, left, right)}
+		alias := f.GetAlias()
+		if alias == nil {
+			return join
+		}
+		join.refname = alias.GetAliasname()
+		renameColumns(join, alias)
+		src = join
+	case *pganalyze.RangeTableSample:
+		return a.fromItem(f.GetRelation(), sc)
+	default:
+		return &source{}
+	}
+	sc.sources = append(sc.sources, src)
+	return src
+}
+
+// joinColumns returns the columns of a join of left and right, nil if the
+// columns of either are not known. Columns merged by USING or NATURAL come
+// first and are computed from the columns of both sides.
+func joinColumns(j *pganalyze.JoinExpr, left, right *source) []output {
+	if left.columns == nil || right.columns == nil {
+		return nil
+	}
+	using := stringList(j.GetUsingClause())
+	if j.GetIsNatural() {
+		for _, c := range left.columns {
+			if _, ok := right.column(c.name); ok {
+				using = append(using, c.name)
+			}
+		}
+	}
+	cols := []output{}
+	for _, name := range using {
+		sources := set{}
+		for _, src := range []*source{left, right} {
+			if cs, ok := src.column(name); ok {
+				sources.add(cs)
+			}
+		}
+		cols = append(cols, output{name: name, sources: sources})
+	}
+	for _, src := range []*source{left, right} {
+		for _, c := range src.columns {
+			if !slices.Contains(using, c.name) {
+				cols = append(cols, output{name: c.name, sources: copySet(c.sources)})
+			}
+		}
+	}
+	return cols
+}
+
+func (a *analyzer) rangeVar(rv *pganalyze.RangeVar, sc *scope) *source {
+	src := &source{refname: rv.GetRelname()}
+	if cols, ok := sc.cte(rv.GetRelname()); ok && rv.GetSchemaname() == "" {
+		src.columns = make([]output, len(cols))
+		copy(src.columns, cols)
+	} else {
+		name := relationName(rv)
+		src.table = &name
+		if a.schema != nil {
+			if cols, ok := a.schema.Columns(name); ok {
+				for _, c := range cols {
+					src.columns = append(src.columns, output{
+						name:    c,
+						sources: set{{Schema: name.Schema, Table: name.Name, Name: c}: {}},
+					})
+				}
+				if src.columns == nil {
+					src.columns = []output{}
+				}
+			}
+		}
+	}
+	if alias := rv.GetAlias(); alias != nil {
+		src.refname = alias.GetAliasname()
+		renameColumns(src, alias)
+	}
+	return src
+}
+
+// renameColumns applies the column aliases of a FROM item to the leading
+// columns of src.
+func renameColumns(src *source, alias *pganalyze.Alias) {
+	for i, name := range stringList(alias.GetColnames()) {
+		if i < len(src.columns) {
+			src.columns[i].name = name
+		}
+	}
+}
+
+// expandStar returns the output columns of a star reference in a target list.
+func (a *analyzer) expandStar(cr *pganalyze.ColumnRef, sc *scope) []output {
+	if fields := cr.GetFields(); len(fields) > 1 {
+		if src := resolveQualified(stringList(fields[:len(fields)-1]), sc); src != nil {
+			return src.star()
+		}
+		return nil
+	}
+
+	var outs []output
+	for _, src := range sc.sources {
+		// The columns of an aliased join are those of the joined sources.
+		if src.joined == nil {
+			outs = append(outs, src.star()...)
+		}
+	}
+	return outs
+}
+
+// star returns the columns of the source, with a column named "*" standing
+// for unknown columns.
+func (s *source) star() []output {
+	var outs []output
+	switch {
+	case s.columns != nil:
+		for _, c := range s.columns {
+			outs = append(outs, output{name: c.name, sources: copySet(c.sources)})
+		}
+	case s.table != nil:
+		outs = append(outs, output{name: "*", sources: set{{Schema: s.table.Schema, Table: s.table.Name, Name: "*"}: {}}})
+	case s.joined != nil:
+		for _, src := range s.joined {
+			outs = append(outs, src.star()...)
+		}
+	default:
+		outs = append(outs, output{name: "*", sources: set{}})
+	}
+	return outs
+}
+
+// expr returns the input columns an expression is computed from.
+func (a *analyzer) expr(n *pganalyze.Node, sc *scope) set {
+	res := set{}
+	if n == nil {
+		return res
+	}
+	walk.Walk(n, func(msg proto.Message) bool {
+		switch m := msg.(type) {
+		case *pganalyze.ColumnRef:
+			res.add(a.columnRef(m, sc))
+			return false
+		case *pganalyze.SubLink:
+			res.add(a.expr(m.GetTestexpr(), sc))
+			if m.GetSubLinkType() == pganalyze.SubLinkType_EXISTS_SUBLINK {
+				return false
+			}
+			for _, o := range a.query(m.GetSubselect(), sc) {
+				res.add(o.sources)
+			}
+			return false
+		}
+		return true
+	})
+	return res
+}
+
+func (a *analyzer) columnRef(cr *pganalyze.ColumnRef, sc *scope) set {
+	fields := cr.GetFields()
+	if isStar(cr) {
+		res := set{}
+		for _, o := range a.expandStar(cr, sc) {
+			res.add(o.sources)
+		}
+		return res
+	}
+	parts := stringList(fields)
+	name := parts[len(parts)-1]
+
+	if len(parts) > 1 {
+		qualifier := parts[:len(parts)-1]
+		if src := resolveQualified(qualifier, sc); src != nil {
+			cols, _ := src.column(name)
+			return copySet(cols)
+		}
+		// A column of a composite value, such as (t.col).field, is attributed
+		// to the column itself.
+		if len(parts) == 2 {
+			return a.columnRef(&pganalyze.ColumnRef{Fields: fields[:1]}, sc)
+		}
+		return set{}
+	}
+
+	// Search from the innermost scope outwards, attributing the column to all
+	// sources that may have it if it cannot be resolved to a single one.
+	for ; sc != nil; sc = sc.parent {
+		res, found := set{}, false
+		for _, src := range sc.sources {
+			if cols, ok := src.column(name); ok {
+				res.add(cols)
+				found = true
+			}
+		}
+		if found {
+			return res
+		}
+	}
+	return set{}
+}
+
+// resolveQualified finds the source a qualifier refers to, searching from the
+// innermost scope outwards.
+func resolveQualified(qualifier []string, sc *scope) *source {
+	name := qualifier[len(qualifier)-1]
+	schema := ""
+	if len(qualifier) > 1 {
+		schema = qualifier[len(qualifier)-2]
+	}
+	for ; sc != nil; sc = sc.parent {
+		for i := len(sc.sources) - 1; i >= 0; i-- {
+			src := sc.sources[i]
+			if schema != "" {
+				if src.table != nil && src.table.Schema == schema && src.table.Name == name {
+					return src
+				}
+				continue
+			}
+			if src.refname == name {
+				return src
+			}
+		}
+	}
+	return nil
+}
+
+func isStar(cr *pganalyze.ColumnRef) bool {
+	fields := cr.GetFields()
+	return len(fields) > 0 && fields[len(fields)-1].GetAStar() != nil
+}
+
+func copySet(s set) set {
+	res := set{}
+	res.add(s)
+	return res
+}
+
+func relationName(rv *pganalyze.RangeVar) analysis.RelationName {
+	return analysis.RelationName{Schema: rv.GetSchemaname(), Name: rv.GetRelname()}
+}
+
+func stringList(nodes []*pganalyze.Node) []string {
+	var res []string
+	for _, n := range nodes {
+		if s := n.GetString_(); s != nil {
+			res = append(res, s.GetSval())
+		}
+	}
+	return res
+}
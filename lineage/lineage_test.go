@@ -0,0 +1,158 @@
+package lineage_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+	"github.com/wasilibs/go-pgquery/analysis"
+	"github.com/wasilibs/go-pgquery/lineage"
+)
+
+type schema map[string][]string
+
+func (s schema) Columns(name analysis.RelationName) ([]string, bool) {
+	cols, ok := s[name.String()]
+	return cols, ok
+}
+
+func col(table, name string) lineage.Column {
+	return lineage.Column{Table: table, Name: name}
+}
+
+var lineageTests = []struct {
+	name   string
+	input  string
+	schema analysis.Schema
+	want   []lineage.Statement
+}{
+	{
+		name:  "insert select",
+		input: "INSERT INTO dw.sales (day, total) SELECT o.created_at::date, sum(o.amount * (1 - d.rate)) FROM orders o JOIN discounts d ON d.id = o.discount_id GROUP BY 1",
+		want: []lineage.Statement{{
+			Kind: lineage.KindInsert, Target: analysis.RelationName{Schema: "dw", Name: "sales"},
+			Columns: []lineage.Mapping{
+				{Position: 1, Name: "day", Sources: []lineage.Column{col("orders", "created_at")}},
+				{Position: 2, Name: "total", Sources: []lineage.Column{col("discounts", "rate"), col("orders", "amount")}},
+			},
+		}},
+	},
+	{
+		name:  "cte union and subquery",
+		input: "CREATE TABLE people AS WITH a AS (SELECT name, 'emp' AS kind FROM employees UNION ALL SELECT full_name, 'con' FROM contractors) SELECT x.name AS person, kind, (SELECT max(level) FROM grades g WHERE g.name = x.name) AS grade FROM (SELECT * FROM a) x",
+		want: []lineage.Statement{{
+			Kind: lineage.KindCreateTableAs, Target: analysis.RelationName{Name: "people"},
+			Columns: []lineage.Mapping{
+				{Position: 1, Name: "person", Sources: []lineage.Column{col("contractors", "full_name"), col("employees", "name")}},
+				{Position: 2, Name: "kind", Sources: []lineage.Column{}},
+				{Position: 3, Name: "grade", Sources: []lineage.Column{col("grades", "level")}},
+			},
+		}},
+	},
+	{
+		name:   "view with star and schema",
+		input:  "CREATE VIEW v (a) AS SELECT * FROM t; INSERT INTO t SELECT * FROM u",
+		schema: schema{"t": {"id", "val"}},
+		want: []lineage.Statement{
+			{
+				Kind: lineage.KindCreateView, Target: analysis.RelationName{Name: "v"},
+				Columns: []lineage.Mapping{
+					{Position: 1, Name: "a", Sources: []lineage.Column{col("t", "id")}},
+					{Position: 2, Name: "val", Sources: []lineage.Column{col("t", "val")}},
+				},
+			},
+			{
+				Stmt: 1, Kind: lineage.KindInsert, Target: analysis.RelationName{Name: "t"},
+				Columns: []lineage.Mapping{
+					{Position: 1, Name: "id", Sources: []lineage.Column{col("u", "*")}},
+				},
+			},
+		},
+	},
+	{
+		name:   "join alias",
+		input:  "CREATE VIEW v AS SELECT j.name, j.k, CASE WHEN true THEN 0 ELSE total END FROM (users JOIN orders USING (id)) AS j (k); CREATE VIEW w AS SELECT j.x FROM (a JOIN b ON true) j",
+		schema: schema{"users": {"id", "name"}, "orders": {"id", "total"}},
+		want: []lineage.Statement{
+			{
+				Kind: lineage.KindCreateView, Target: analysis.RelationName{Name: "v"},
+				Columns: []lineage.Mapping{
+					{Position: 1, Name: "name", Sources: []lineage.Column{col("users", "name")}},
+					{Position: 2, Name: "k", Sources: []lineage.Column{col("orders", "id"), col("users", "id")}},
+					{Position: 3, Name: "total", Sources: []lineage.Column{col("orders", "total")}},
+				},
+			},
+			{
+				Stmt: 1, Kind: lineage.KindCreateView, Target: analysis.RelationName{Name: "w"},
+				Columns: []lineage.Mapping{
+					{Position: 1, Name: "x", Sources: []lineage.Column{col("a", "x"), col("b", "x")}},
+				},
+			},
+		},
+	},
+	{
+		name:  "update from",
+		input: "UPDATE accounts a SET balance = a.balance + p.amount, (tier, note) = (s.tier, 'x') FROM payments p, scores s WHERE p.account_id = a.id",
+		want: []lineage.Statement{{
+			Kind: lineage.KindUpdate, Target: analysis.RelationName{Name: "accounts"},
+			Columns: []lineage.Mapping{
+				{Position: 1, Name: "balance", Sources: []lineage.Column{col("accounts", "balance"), col("payments", "amount")}},
+				{Position: 2, Name: "tier", Sources: []lineage.Column{col("scores", "tier")}},
+				{Position: 3, Name: "note", Sources: []lineage.Column{}},
+			},
+		}},
+	},
+	{
+		name:  "recursive cte",
+		input: "CREATE MATERIALIZED VIEW tree AS WITH RECURSIVE r (id, path) AS (SELECT id, name FROM nodes WHERE parent IS NULL UNION ALL SELECT n.id, r.path || n.label FROM nodes n JOIN r ON n.parent = r.id) SELECT path FROM r",
+		want: []lineage.Statement{{
+			Kind: lineage.KindCreateMaterializedView, Target: analysis.RelationName{Name: "tree"},
+			Columns: []lineage.Mapping{
+				{Position: 1, Name: "path", Sources: []lineage.Column{col("nodes", "label"), col("nodes", "name")}},
+			},
+		}},
+	},
+	{
+		name:  "ignored statements",
+		input: "SELECT 1; INSERT INTO t VALUES (1)",
+		want: []lineage.Statement{{
+			Stmt: 1, Kind: lineage.KindInsert, Target: analysis.RelationName{Name: "t"},
+			Columns: []lineage.Mapping{
+				{Position: 1, Sources: []lineage.Column{}},
+			},
+		}},
+	},
+}
+
+func TestAnalyze(t *testing.T) {
+	for _, tc := range lineageTests {
+		t.Run(tc.name, func(t *testing.T) {
+			tree, err := pg_query.Parse(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := lineage.AnalyzeWithSchema(tree, tc.schema)
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("lineage mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	tree, err := pg_query.Parse("INSERT INTO s.t (a) SELECT x FROM s.u")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(lineage.Analyze(tree))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"stmt":0,"kind":"INSERT","target":"s.t","columns":[{"position":1,"name":"a","sources":["s.u.x"]}]}]`
+	if string(got) != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,355 @@
+// Package lint checks SQL for risky or error-prone constructs using rules that
+// operate on the parse tree returned by pg_query.Parse.
+//
+// Findings can be suppressed with comments. A comment of the form
+//
+//	-- pglint:ignore M001, drop-column
+//
+// suppresses the listed rules, given by ID or name, for the statement the
+// comment precedes or is inside of, and
+//
+//	-- pglint:ignore-file M002
+//
+// suppresses them for the whole input. Without a list of rules, all rules are
+// suppressed.
+//
+// Rule IDs are stable and never reused. They consist of a letter for the rule
+// set, M for MigrationRules and Q for QueryRules, and a sequence number.
+package lint
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+	"github.com/wasilibs/go-pgquery/analysis"
+)
+
+// ErrUnknownRule is returned when configuring a rule that is not registered
+// with a Linter.
+var ErrUnknownRule = errors.New("lint: unknown rule")
+
+// errUnknownSeverity is returned by ParseSeverity for an invalid name.
+var errUnknownSeverity = errors.New("lint: unknown severity")
+
+// Severity is the severity of a finding.
+type Severity int
+
+const (
+	// SeverityOff disables a rule.
+	SeverityOff Severity = iota
+	// SeverityInfo is a finding that is informational only.
+	SeverityInfo
+	// SeverityWarning is a finding that should be reviewed.
+	SeverityWarning
+	// SeverityError is a finding that should block the change.
+	SeverityError
+)
+
+// String returns the lowercase name of the severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityOff:
+		return "off"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return "unknown"
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ParseSeverity returns the severity with the given name, as returned by
+// Severity.String.
+func ParseSeverity(name string) (Severity, error) {
+	for s := SeverityOff; s <= SeverityError; s++ {
+		if strings.EqualFold(s.String(), name) {
+			return s, nil
+		}
+	}
+	return SeverityOff, fmt.Errorf("%w: %q", errUnknownSeverity, name)
+}
+
+// Rule is a check run on each statement of the input.
+type Rule struct {
+	// ID is the stable identifier of the rule, such as "M001".
+	ID string
+	// Name is a short descriptive name of the rule, such as "drop-column".
+	Name string
+	// Description explains what the rule reports and why.
+	Description string
+	// Severity is the default severity of findings of the rule.
+	Severity Severity
+	// Check reports the findings of the rule for the statement of p.
+	Check func(p *Pass)
+}
+
+// Pass provides a rule with the statement being checked and collects its
+// findings.
+type Pass struct {
+	// SQL is the full input.
+	SQL string
+	// Tree is the parse tree of the full input.
+	Tree *pganalyze.ParseResult
+	// Stmt is the index of the statement being checked in Tree.Stmts.
+	Stmt int
+	// Node is the statement being checked.
+	Node *pganalyze.Node
+
+	rule     *Rule
+	severity Severity
+	state    *state
+}
+
+// Report records a finding at the byte offset location of the input, usually
+// the Location field of a node. A negative location reports the finding for the
+// whole statement.
+func (p *Pass) Report(location int32, msg string) {
+	start, end := p.state.span(p.Stmt, location)
+	p.state.findings = append(p.state.findings, Finding{
+		Rule:     p.rule.ID,
+		Name:     p.rule.Name,
+		Severity: p.severity,
+		Message:  msg,
+		Stmt:     p.Stmt,
+		Start:    start,
+		End:      end,
+	})
+}
+
+// Reportf is like Report with a formatted message.
+func (p *Pass) Reportf(location int32, format string, args ...any) {
+	p.Report(location, fmt.Sprintf(format, args...))
+}
+
+// CreatedInInput returns whether the relation was created by an earlier
+// statement of the input. Operations on such relations are usually safe as
+// they cannot contain data yet.
+func (p *Pass) CreatedInInput(rv *pganalyze.RangeVar) bool {
+	return p.state.created[analysis.RelationName{Schema: rv.GetSchemaname(), Name: rv.GetRelname()}]
+}
+
+// Finding is a problem reported by a rule.
+type Finding struct {
+	// Rule is the ID of the rule.
+	Rule string `json:"rule"`
+	// Name is the name of the rule.
+	Name     string   `json:"name"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	// Stmt is the index of the statement in ParseResult.Stmts.
+	Stmt int `json:"stmt"`
+	// Start is the byte offset of the start of the finding in the input.
+	Start int `json:"start"`
+	// End is the byte offset of the end of the finding in the input.
+	End int `json:"end"`
+}
+
+// String formats the finding as offset, severity, rule and message.
+func (f Finding) String() string {
+	return fmt.Sprintf("%d: %s: %s (%s %s)", f.Start, f.Severity, f.Message, f.Rule, f.Name)
+}
+
+// Linter runs a set of rules on SQL input.
+type Linter struct {
+	rules    []*Rule
+	severity map[string]Severity
+}
+
+// New returns a Linter running the given rules with their default severity.
+func New(rules ...*Rule) *Linter {
+	return &Linter{rules: slices.Clone(rules), severity: map[string]Severity{}}
+}
+
+// Rules returns the rules of the linter.
+func (l *Linter) Rules() []*Rule {
+	return slices.Clone(l.rules)
+}
+
+// SetSeverity overrides the severity of the rule with the given ID or name.
+// SeverityOff disables the rule.
+func (l *Linter) SetSeverity(rule string, severity Severity) error {
+	for _, r := range l.rules {
+		if r.ID == rule || r.Name == rule {
+			l.severity[r.ID] = severity
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q", ErrUnknownRule, rule)
+}
+
+// Lint parses sql and returns the findings of the rules, ordered by position.
+func (l *Linter) Lint(sql string) ([]Finding, error) {
+	tree, err := pg_query.Parse(sql)
+	if err != nil {
+		return nil, fmt.Errorf("lint: parsing input: %w", err)
+	}
+	scan, err := pg_query.Scan(sql)
+	if err != nil {
+		return nil, fmt.Errorf("lint: scanning input: %w", err)
+	}
+
+	st := &state{sql: sql, tree: tree, tokens: scan.GetTokens(), created: map[analysis.RelationName]bool{}}
+	for i, raw := range tree.GetStmts() {
+		for _, r := range l.rules {
+			severity, ok := l.severity[r.ID]
+			if !ok {
+				severity = r.Severity
+			}
+			if severity == SeverityOff {
+				continue
+			}
+			r.Check(&Pass{SQL: sql, Tree: tree, Stmt: i, Node: raw.GetStmt(), rule: r, severity: severity, state: st})
+		}
+		if rv := raw.GetStmt().GetCreateStmt().GetRelation(); rv != nil {
+			st.created[analysis.RelationName{Schema: rv.GetSchemaname(), Name: rv.GetRelname()}] = true
+		}
+	}
+
+	findings := st.suppress()
+	slices.SortStableFunc(findings, func(a, b Finding) int {
+		return cmp.Compare(a.Start, b.Start)
+	})
+	return findings, nil
+}
+
+// state is shared by the passes of a single Lint call.
+type state struct {
+	sql      string
+	tree     *pganalyze.ParseResult
+	tokens   []*pganalyze.ScanToken
+	created  map[analysis.RelationName]bool
+	findings []Finding
+}
+
+// stmtRange returns the byte range of a statement, including the comments and
+// whitespace preceding it.
+func (s *state) stmtRange(stmt int) (int, int) {
+	raw := s.tree.GetStmts()[stmt]
+	start := int(raw.GetStmtLocation())
+	end := len(s.sql)
+	if raw.GetStmtLen() > 0 {
+		end = start + int(raw.GetStmtLen())
+	}
+	return start, end
+}
+
+// span returns the range of a finding at location, the token starting there or
+// the statement without its leading comments if location is negative.
+func (s *state) span(stmt int, location int32) (int, int) {
+	start, end := s.stmtRange(stmt)
+	if location < 0 {
+		for _, t := range s.tokens {
+			if int(t.GetStart()) >= start && !isComment(t) {
+				return int(t.GetStart()), end
+			}
+		}
+		return start, end
+	}
+	i := sort.Search(len(s.tokens), func(i int) bool { return s.tokens[i].GetStart() >= location })
+	if i < len(s.tokens) && s.tokens[i].GetStart() == location {
+		return int(location), int(s.tokens[i].GetEnd())
+	}
+	return int(location), int(location)
+}
+
+// identifier returns the location of the first identifier token of statement
+// stmt at or after from that names name, or -1 if there is none.
+func (s *state) identifier(stmt int, from int32, name string) int32 {
+	_, end := s.stmtRange(stmt)
+	for _, t := range s.tokens {
+		if t.GetStart() < from || int(t.GetStart()) >= end || t.GetKeywordKind() == pganalyze.KeywordKind_RESERVED_KEYWORD {
+			continue
+		}
+		text := s.sql[t.GetStart():t.GetEnd()]
+		if unquoted, ok := strings.CutPrefix(text, `"`); ok {
+			text = strings.ReplaceAll(strings.TrimSuffix(unquoted, `"`), `""`, `"`)
+		} else {
+			text = strings.ToLower(text)
+		}
+		if text == name {
+			return t.GetStart()
+		}
+	}
+	return -1
+}
+
+// suppress returns the findings not suppressed by comments.
+func (s *state) suppress() []Finding {
+	fileRules := suppression{}
+	stmtRules := map[int]suppression{}
+	for _, t := range s.tokens {
+		if !isComment(t) {
+			continue
+		}
+		text := s.sql[t.GetStart():t.GetEnd()]
+		if t.GetToken() == pganalyze.Token_SQL_COMMENT {
+			text = strings.TrimPrefix(text, "--")
+		} else {
+			text = strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/")
+		}
+		text = strings.TrimSpace(text)
+
+		if rest, ok := strings.CutPrefix(text, "pglint:ignore-file"); ok {
+			fileRules.add(rest)
+			continue
+		}
+		rest, ok := strings.CutPrefix(text, "pglint:ignore")
+		if !ok {
+			continue
+		}
+		for i := range s.tree.GetStmts() {
+			if start, end := s.stmtRange(i); int(t.GetStart()) >= start && int(t.GetStart()) < end {
+				if stmtRules[i] == nil {
+					stmtRules[i] = suppression{}
+				}
+				stmtRules[i].add(rest)
+				break
+			}
+		}
+	}
+
+	var res []Finding
+	for _, f := range s.findings {
+		if fileRules.matches(f) || stmtRules[f.Stmt].matches(f) {
+			continue
+		}
+		res = append(res, f)
+	}
+	return res
+}
+
+// suppression is a set of suppressed rule IDs and names, where "*" suppresses
+// all rules.
+type suppression map[string]bool
+
+func (s suppression) add(list string) {
+	fields := strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+	if len(fields) == 0 {
+		s["*"] = true
+	}
+	for _, f := range fields {
+		s[f] = true
+	}
+}
+
+func (s suppression) matches(f Finding) bool {
+	return s["*"] || s[f.Rule] || s[f.Name]
+}
+
+func isComment(t *pganalyze.ScanToken) bool {
+	return t.GetToken() == pganalyze.Token_SQL_COMMENT || t.GetToken() == pganalyze.Token_C_COMMENT
+}
@@ -0,0 +1,172 @@
+package lint_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/wasilibs/go-pgquery/lint"
+)
+
+type finding struct {
+	rule string
+	text string
+}
+
+func lintText(t *testing.T, l *lint.Linter, sql string) []finding {
+	t.Helper()
+	findings, err := l.Lint(sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res []finding
+	for _, f := range findings {
+		res = append(res, finding{rule: f.Rule, text: sql[f.Start:f.End]})
+	}
+	return res
+}
+
+var migrationTests = []struct {
+	name  string
+	input string
+	want  []finding
+}{
+	{
+		name:  "volatile default",
+		input: "ALTER TABLE users ADD COLUMN token uuid DEFAULT gen_random_uuid(), ADD COLUMN created timestamptz DEFAULT now()",
+		want:  []finding{{"M001", "gen_random_uuid"}},
+	},
+	{
+		name:  "index",
+		input: "CREATE INDEX idx ON users (email);\nCREATE INDEX CONCURRENTLY idx2 ON users (name);\nDROP INDEX idx",
+		want:  []finding{{"M002", "CREATE INDEX idx ON users (email)"}, {"M002", "DROP INDEX idx"}},
+	},
+	{
+		name:  "alter column",
+		input: "ALTER TABLE users ALTER COLUMN age TYPE bigint, ALTER COLUMN name SET NOT NULL, DROP COLUMN email",
+		want:  []finding{{"M003", "age"}, {"M004", "name"}, {"M005", "email"}},
+	},
+	{
+		name:  "rename",
+		input: "ALTER TABLE users RENAME TO accounts; ALTER TABLE accounts RENAME COLUMN name TO full_name",
+		want:  []finding{{"M006", "users"}, {"M006", "accounts"}},
+	},
+	{
+		name:  "constraint",
+		input: "ALTER TABLE orders ADD CONSTRAINT fk FOREIGN KEY (user_id) REFERENCES users (id); ALTER TABLE orders ADD CONSTRAINT c CHECK (total > 0) NOT VALID",
+		want:  []finding{{"M007", "CONSTRAINT"}},
+	},
+	{
+		name:  "created in input",
+		input: "CREATE TABLE t (id int); CREATE INDEX ON t (id); ALTER TABLE t ADD COLUMN r float DEFAULT random(), DROP COLUMN id",
+	},
+	{
+		name:  "suppressed",
+		input: "-- pglint:ignore M005\nALTER TABLE a DROP COLUMN x;\n/* pglint:ignore drop-column, M003 */ ALTER TABLE b DROP COLUMN y, ALTER COLUMN z TYPE text;\nALTER TABLE c DROP COLUMN w",
+		want:  []finding{{"M005", "w"}},
+	},
+	{
+		name:  "suppressed file",
+		input: "DROP INDEX a;\nDROP INDEX b;\n-- pglint:ignore-file index-without-concurrently",
+	},
+}
+
+func TestMigrationRules(t *testing.T) {
+	l := lint.New(lint.MigrationRules()...)
+	for _, tc := range migrationTests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := lintText(t, l, tc.input)
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(finding{}), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("findings mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	l := lint.New(lint.MigrationRules()...)
+	if err := l.SetSeverity("drop-column", lint.SeverityOff); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.SetSeverity("M003", lint.SeverityInfo); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.SetSeverity("nope", lint.SeverityInfo); !errors.Is(err, lint.ErrUnknownRule) {
+		t.Errorf("expected ErrUnknownRule, got %v", err)
+	}
+
+	findings, err := l.Lint("ALTER TABLE t DROP COLUMN a, ALTER COLUMN b TYPE text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(findings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"rule":"M003","name":"changing-column-type","severity":"info","message":"changing the type of column \"b\" may rewrite the table","stmt":0,"start":42,"end":43}]`
+	if string(got) != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+var queryTests = []struct {
+	name  string
+	input string
+	want  []finding
+}{
+	{
+		name:  "select star",
+		input: "SELECT t.*, count(*) FROM t WHERE EXISTS (SELECT * FROM u)",
+		want:  []finding{{"Q001", "t"}},
+	},
+	{
+		name:  "not in subquery",
+		input: "SELECT a FROM t WHERE a NOT IN (SELECT b FROM u) AND a NOT IN (1, 2)",
+		want:  []finding{{"Q002", "NOT"}},
+	},
+	{
+		name:  "missing where",
+		input: "UPDATE t SET a = 1; DELETE FROM u; DELETE FROM v WHERE id = 1",
+		want:  []finding{{"Q003", "UPDATE t SET a = 1"}, {"Q003", "DELETE FROM u"}},
+	},
+	{
+		name:  "offset",
+		input: "SELECT a FROM t ORDER BY a LIMIT 10 OFFSET 20",
+		want:  []finding{{"Q004", "20"}},
+	},
+	{
+		name:  "implicit join",
+		input: "SELECT 1 FROM a, b JOIN c ON true, LATERAL (SELECT 1) s, generate_series(1, 2) g CROSS JOIN d",
+		want:  []finding{{"Q005", "b"}},
+	},
+	{
+		name:  "leading wildcard",
+		input: "SELECT 1 FROM t WHERE a LIKE '%x' OR b ILIKE 'x%' OR c NOT LIKE '_y'",
+		want:  []finding{{"Q006", "'%x'"}, {"Q006", "'_y'"}},
+	},
+	{
+		name:  "order by random",
+		input: "SELECT a FROM t ORDER BY random() LIMIT 1",
+		want:  []finding{{"Q007", "random"}},
+	},
+	{
+		name:  "null comparison",
+		input: "SELECT 1 FROM t WHERE a = NULL OR NULL <> b OR c IS NULL",
+		want:  []finding{{"Q008", "="}, {"Q008", "<>"}},
+	},
+}
+
+func TestQueryRules(t *testing.T) {
+	l := lint.New(lint.QueryRules()...)
+	for _, tc := range queryTests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := lintText(t, l, tc.input)
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(finding{}), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("findings mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package lint
+
+import (
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/wasilibs/go-pgquery/internal/walk"
+)
+
+// MigrationRules returns the built-in rules for DDL that takes locks blocking
+// reads or writes for a long time, or that breaks running application code.
+// Operations on relations created earlier in the same input are not reported.
+func MigrationRules() []*Rule {
+	return []*Rule{
+		{
+			ID:          "M001",
+			Name:        "adding-volatile-default",
+			Description: "Adding a column with a volatile default rewrites the table while holding an ACCESS EXCLUSIVE lock.",
+			Severity:    SeverityError,
+			Check:       alterTableCheck(checkVolatileDefault),
+		},
+		{
+			ID:          "M002",
+			Name:        "index-without-concurrently",
+			Description: "Creating or dropping an index without CONCURRENTLY blocks writes to the table until it completes.",
+			Severity:    SeverityError,
+			Check:       checkIndexConcurrently,
+		},
+		{
+			ID:          "M003",
+			Name:        "changing-column-type",
+			Description: "Changing the type of a column usually rewrites the table and its indexes while holding an ACCESS EXCLUSIVE lock.",
+			Severity:    SeverityError,
+			Check:       alterTableCheck(checkColumnType),
+		},
+		{
+			ID:          "M004",
+			Name:        "setting-not-null",
+			Description: "Setting NOT NULL scans the table while holding an ACCESS EXCLUSIVE lock, unless a validated CHECK constraint proves it.",
+			Severity:    SeverityWarning,
+			Check:       alterTableCheck(checkSetNotNull),
+		},
+		{
+			ID:          "M005",
+			Name:        "drop-column",
+			Description: "Dropping a column breaks application code still referencing it.",
+			Severity:    SeverityWarning,
+			Check:       alterTableCheck(checkDropColumn),
+		},
+		{
+			ID:          "M006",
+			Name:        "rename",
+			Description: "Renaming a table or column breaks application code still referencing the old name.",
+			Severity:    SeverityWarning,
+			Check:       checkRename,
+		},
+		{
+			ID:          "M007",
+			Name:        "constraint-without-not-valid",
+			Description: "Adding a foreign key or check constraint without NOT VALID scans the table while blocking writes; add it NOT VALID and VALIDATE it separately.",
+			Severity:    SeverityWarning,
+			Check:       alterTableCheck(checkConstraintNotValid),
+		},
+	}
+}
+
+// volatileFunctions are common built-in volatile functions, which cannot be
+// used as a column default without rewriting the table.
+var volatileFunctions = map[string]bool{
+	"random":             true,
+	"gen_random_uuid":    true,
+	"uuid_generate_v1":   true,
+	"uuid_generate_v4":   true,
+	"clock_timestamp":    true,
+	"timeofday":          true,
+	"nextval":            true,
+	"txid_current":       true,
+	"setseed":            true,
+	"random_normal":      true,
+	"uuid_generate_v1mc": true,
+}
+
+// alterTableCheck returns a check calling fn for each subcommand of an
+// ALTER TABLE statement on a relation not created in the input.
+func alterTableCheck(fn func(p *Pass, s *pganalyze.AlterTableStmt, cmd *pganalyze.AlterTableCmd)) func(p *Pass) {
+	return func(p *Pass) {
+		s := p.Node.GetAlterTableStmt()
+		if s == nil || p.CreatedInInput(s.GetRelation()) {
+			return
+		}
+		for _, n := range s.GetCmds() {
+			if cmd := n.GetAlterTableCmd(); cmd != nil {
+				fn(p, s, cmd)
+			}
+		}
+	}
+}
+
+func checkVolatileDefault(p *Pass, _ *pganalyze.AlterTableStmt, cmd *pganalyze.AlterTableCmd) {
+	if cmd.GetSubtype() != pganalyze.AlterTableType_AT_AddColumn {
+		return
+	}
+	cd := cmd.GetDef().GetColumnDef()
+	exprs := []*pganalyze.Node{cd.GetRawDefault()}
+	for _, c := range cd.GetConstraints() {
+		if con := c.GetConstraint(); con.GetContype() == pganalyze.ConstrType_CONSTR_DEFAULT {
+			exprs = append(exprs, con.GetRawExpr())
+		}
+	}
+	for _, e := range exprs {
+		if e == nil {
+			continue
+		}
+		walk.Walk(e, func(msg proto.Message) bool {
+			fc, ok := msg.(*pganalyze.FuncCall)
+			if !ok {
+				return true
+			}
+			names := fc.GetFuncname()
+			name := names[len(names)-1].GetString_().GetSval()
+			if volatileFunctions[name] {
+				p.Reportf(fc.GetLocation(), "column %q is added with volatile default %s(), which rewrites the table", cd.GetColname(), name)
+			}
+			return true
+		})
+	}
+}
+
+func checkIndexConcurrently(p *Pass) {
+	switch s := walk.Unwrap(p.Node).(type) {
+	case *pganalyze.IndexStmt:
+		if !s.GetConcurrent() && !p.CreatedInInput(s.GetRelation()) {
+			p.Reportf(-1, "index on %q is created without CONCURRENTLY, which blocks writes", s.GetRelation().GetRelname())
+		}
+	case *pganalyze.DropStmt:
+		if s.GetRemoveType() == pganalyze.ObjectType_OBJECT_INDEX && !s.GetConcurrent() {
+			p.Report(-1, "index is dropped without CONCURRENTLY, which blocks reads and writes")
+		}
+	}
+}
+
+func checkColumnType(p *Pass, s *pganalyze.AlterTableStmt, cmd *pganalyze.AlterTableCmd) {
+	if cmd.GetSubtype() == pganalyze.AlterTableType_AT_AlterColumnType {
+		p.Reportf(cmdLocation(p, s, cmd), "changing the type of column %q may rewrite the table", cmd.GetName())
+	}
+}
+
+func checkSetNotNull(p *Pass, s *pganalyze.AlterTableStmt, cmd *pganalyze.AlterTableCmd) {
+	if cmd.GetSubtype() == pganalyze.AlterTableType_AT_SetNotNull {
+		p.Reportf(cmdLocation(p, s, cmd), "setting NOT NULL on column %q scans the table", cmd.GetName())
+	}
+}
+
+func checkDropColumn(p *Pass, s *pganalyze.AlterTableStmt, cmd *pganalyze.AlterTableCmd) {
+	if cmd.GetSubtype() == pganalyze.AlterTableType_AT_DropColumn {
+		p.Reportf(cmdLocation(p, s, cmd), "dropping column %q breaks code still using it", cmd.GetName())
+	}
+}
+
+func checkRename(p *Pass) {
+	s := p.Node.GetRenameStmt()
+	if s == nil || p.CreatedInInput(s.GetRelation()) {
+		return
+	}
+	switch s.GetRenameType() {
+	case pganalyze.ObjectType_OBJECT_TABLE, pganalyze.ObjectType_OBJECT_VIEW, pganalyze.ObjectType_OBJECT_MATVIEW:
+		p.Reportf(s.GetRelation().GetLocation(), "renaming %q to %q breaks code still using the old name", s.GetRelation().GetRelname(), s.GetNewname())
+	case pganalyze.ObjectType_OBJECT_COLUMN:
+		p.Reportf(s.GetRelation().GetLocation(), "renaming column %q to %q breaks code still using the old name", s.GetSubname(), s.GetNewname())
+	default:
+	}
+}
+
+func checkConstraintNotValid(p *Pass, _ *pganalyze.AlterTableStmt, cmd *pganalyze.AlterTableCmd) {
+	if cmd.GetSubtype() != pganalyze.AlterTableType_AT_AddConstraint {
+		return
+	}
+	con := cmd.GetDef().GetConstraint()
+	switch con.GetContype() {
+	case pganalyze.ConstrType_CONSTR_FOREIGN, pganalyze.ConstrType_CONSTR_CHECK:
+		if !con.GetSkipValidation() {
+			p.Report(con.GetLocation(), "constraint is added without NOT VALID, which scans the table while blocking writes")
+		}
+	default:
+	}
+}
+
+// cmdLocation returns the location to report an ALTER TABLE subcommand at. The
+// parse tree does not record the location of subcommands, so the column they
+// act on is searched for in the tokens following the relation.
+func cmdLocation(p *Pass, s *pganalyze.AlterTableStmt, cmd *pganalyze.AlterTableCmd) int32 {
+	if cd := cmd.GetDef().GetColumnDef(); cd != nil && cd.GetLocation() >= 0 {
+		return cd.GetLocation()
+	}
+	if loc := p.state.identifier(p.Stmt, s.GetRelation().GetLocation()+1, cmd.GetName()); loc >= 0 {
+		return loc
+	}
+	return s.GetRelation().GetLocation()
+}
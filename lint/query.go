@@ -0,0 +1,207 @@
+package lint
+
+import (
+	"strings"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/wasilibs/go-pgquery/internal/walk"
+)
+
+// QueryRules returns the built-in rules for application queries that are
+// likely to be slow or to not behave as intended.
+func QueryRules() []*Rule {
+	return []*Rule{
+		{
+			ID:          "Q001",
+			Name:        "select-star",
+			Description: "SELECT * returns columns the application may not need and changes its result when the table changes.",
+			Severity:    SeverityWarning,
+			Check:       checkSelectStar,
+		},
+		{
+			ID:          "Q002",
+			Name:        "not-in-subquery",
+			Description: "NOT IN (subquery) returns no rows if the subquery returns a NULL and is not planned as an anti-join; use NOT EXISTS.",
+			Severity:    SeverityWarning,
+			Check:       checkNotInSubquery,
+		},
+		{
+			ID:          "Q003",
+			Name:        "missing-where",
+			Description: "UPDATE or DELETE without a WHERE clause affects every row of the table.",
+			Severity:    SeverityError,
+			Check:       checkMissingWhere,
+		},
+		{
+			ID:          "Q004",
+			Name:        "offset-pagination",
+			Description: "OFFSET reads and discards all skipped rows, which gets slower for later pages; use keyset pagination.",
+			Severity:    SeverityInfo,
+			Check:       checkOffset,
+		},
+		{
+			ID:          "Q005",
+			Name:        "implicit-cross-join",
+			Description: "Listing several relations in FROM joins them implicitly, and forgetting the join condition produces a cross join; use explicit JOIN.",
+			Severity:    SeverityWarning,
+			Check:       checkImplicitJoin,
+		},
+		{
+			ID:          "Q006",
+			Name:        "leading-wildcard-like",
+			Description: "LIKE patterns starting with a wildcard cannot use a B-tree index.",
+			Severity:    SeverityWarning,
+			Check:       checkLeadingWildcard,
+		},
+		{
+			ID:          "Q007",
+			Name:        "order-by-random",
+			Description: "ORDER BY random() sorts the whole result to pick rows; use TABLESAMPLE or a keyed lookup.",
+			Severity:    SeverityWarning,
+			Check:       checkOrderByRandom,
+		},
+		{
+			ID:          "Q008",
+			Name:        "null-comparison",
+			Description: "Comparing with = NULL or <> NULL is never true; use IS NULL or IS NOT NULL.",
+			Severity:    SeverityError,
+			Check:       checkNullComparison,
+		},
+	}
+}
+
+func checkSelectStar(p *Pass) {
+	walk.Walk(p.Node, func(msg proto.Message) bool {
+		switch m := msg.(type) {
+		case *pganalyze.SubLink:
+			// The target list of an EXISTS subquery is not evaluated.
+			return m.GetSubLinkType() != pganalyze.SubLinkType_EXISTS_SUBLINK
+		case *pganalyze.SelectStmt:
+			for _, n := range m.GetTargetList() {
+				cr := n.GetResTarget().GetVal().GetColumnRef()
+				fields := cr.GetFields()
+				if len(fields) > 0 && fields[len(fields)-1].GetAStar() != nil {
+					p.Report(cr.GetLocation(), "query selects all columns with *")
+				}
+			}
+		}
+		return true
+	})
+}
+
+func checkNotInSubquery(p *Pass) {
+	walk.Walk(p.Node, func(msg proto.Message) bool {
+		be, ok := msg.(*pganalyze.BoolExpr)
+		if !ok || be.GetBoolop() != pganalyze.BoolExprType_NOT_EXPR || len(be.GetArgs()) != 1 {
+			return true
+		}
+		if sl := be.GetArgs()[0].GetSubLink(); sl != nil && sl.GetSubLinkType() == pganalyze.SubLinkType_ANY_SUBLINK {
+			p.Report(be.GetLocation(), "NOT IN with a subquery does not match any row if the subquery returns NULL")
+		}
+		return true
+	})
+}
+
+func checkMissingWhere(p *Pass) {
+	switch s := walk.Unwrap(p.Node).(type) {
+	case *pganalyze.UpdateStmt:
+		if s.GetWhereClause() == nil {
+			p.Reportf(-1, "UPDATE of %q without WHERE affects every row", s.GetRelation().GetRelname())
+		}
+	case *pganalyze.DeleteStmt:
+		if s.GetWhereClause() == nil {
+			p.Reportf(-1, "DELETE from %q without WHERE affects every row", s.GetRelation().GetRelname())
+		}
+	}
+}
+
+func checkOffset(p *Pass) {
+	walk.Walk(p.Node, func(msg proto.Message) bool {
+		if s, ok := msg.(*pganalyze.SelectStmt); ok && s.GetLimitOffset() != nil {
+			p.Report(walk.Location(s.GetLimitOffset()), "OFFSET pagination reads all skipped rows")
+		}
+		return true
+	})
+}
+
+func checkImplicitJoin(p *Pass) {
+	walk.Walk(p.Node, func(msg proto.Message) bool {
+		s, ok := msg.(*pganalyze.SelectStmt)
+		if !ok {
+			return true
+		}
+		from := s.GetFromClause()
+		for _, n := range from[min(1, len(from)):] {
+			// LATERAL items, which functions implicitly are, refer to preceding
+			// items and are commonly listed with a comma.
+			n = fromItemRelation(n)
+			if n.GetRangeSubselect().GetLateral() || n.GetRangeFunction() != nil {
+				continue
+			}
+			p.Report(walk.Location(n), "relation is joined implicitly by listing it in FROM")
+		}
+		return true
+	})
+}
+
+// fromItemRelation returns the node to report a FROM item at, the leftmost
+// relation of a join.
+func fromItemRelation(n *pganalyze.Node) *pganalyze.Node {
+	for {
+		switch m := walk.Unwrap(n).(type) {
+		case *pganalyze.JoinExpr:
+			n = m.GetLarg()
+		case *pganalyze.RangeTableSample:
+			n = m.GetRelation()
+		default:
+			return n
+		}
+	}
+}
+
+func checkLeadingWildcard(p *Pass) {
+	walk.Walk(p.Node, func(msg proto.Message) bool {
+		e, ok := msg.(*pganalyze.A_Expr)
+		if !ok || (e.GetKind() != pganalyze.A_Expr_Kind_AEXPR_LIKE && e.GetKind() != pganalyze.A_Expr_Kind_AEXPR_ILIKE) {
+			return true
+		}
+		c := e.GetRexpr().GetAConst()
+		if pattern := c.GetSval().GetSval(); strings.HasPrefix(pattern, "%") || strings.HasPrefix(pattern, "_") {
+			p.Reportf(c.GetLocation(), "pattern %q starts with a wildcard and cannot use an index", pattern)
+		}
+		return true
+	})
+}
+
+func checkOrderByRandom(p *Pass) {
+	walk.Walk(p.Node, func(msg proto.Message) bool {
+		sb, ok := msg.(*pganalyze.SortBy)
+		if !ok {
+			return true
+		}
+		fc := sb.GetNode().GetFuncCall()
+		names := fc.GetFuncname()
+		if len(names) > 0 && names[len(names)-1].GetString_().GetSval() == "random" {
+			p.Report(fc.GetLocation(), "ORDER BY random() sorts the whole result")
+		}
+		return true
+	})
+}
+
+func checkNullComparison(p *Pass) {
+	walk.Walk(p.Node, func(msg proto.Message) bool {
+		e, ok := msg.(*pganalyze.A_Expr)
+		if !ok || e.GetKind() != pganalyze.A_Expr_Kind_AEXPR_OP || len(e.GetName()) != 1 {
+			return true
+		}
+		switch op := e.GetName()[0].GetString_().GetSval(); op {
+		case "=", "<>", "!=":
+			if e.GetLexpr().GetAConst().GetIsnull() || e.GetRexpr().GetAConst().GetIsnull() {
+				p.Reportf(e.GetLocation(), "comparison %s NULL is never true", op)
+			}
+		}
+		return true
+	})
+}
@@ -0,0 +1,168 @@
+package pg_query //nolint:revive // Keep package name aligned with existing public API.
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+)
+
+var errNormalizeMismatch = errors.New("pg_query: normalized query does not match input")
+
+// LiteralKind is the kind of a constant replaced by normalization.
+type LiteralKind int
+
+const (
+	// LiteralString is a string, including escape, Unicode escape and
+	// dollar-quoted strings and names given as values of SET.
+	LiteralString LiteralKind = iota
+	// LiteralInteger is an integer.
+	LiteralInteger
+	// LiteralNumeric is a number with a fraction or exponent, or an integer
+	// too large for an integer token.
+	LiteralNumeric
+	// LiteralBitString is a binary or hexadecimal bit string.
+	LiteralBitString
+	// LiteralBoolean is TRUE or FALSE.
+	LiteralBoolean
+	// LiteralNull is NULL, or ALL of LIMIT ALL.
+	LiteralNull
+)
+
+// String returns the name of k.
+func (k LiteralKind) String() string {
+	switch k {
+	case LiteralInteger:
+		return "integer"
+	case LiteralNumeric:
+		return "numeric"
+	case LiteralBitString:
+		return "bit string"
+	case LiteralBoolean:
+		return "boolean"
+	case LiteralNull:
+		return "NULL"
+	case LiteralString:
+	}
+	return "string"
+}
+
+// NormalizedParam is a constant of a query replaced by a parameter reference in
+// its normalized form.
+type NormalizedParam struct {
+	// Index is the number n of the parameter reference $n. Equal constants
+	// that must stay equal, as in GROUP BY clauses repeating target list
+	// expressions, share the same index.
+	Index int
+	// Text is the constant as written in the query, including quotes and a
+	// leading minus sign.
+	Text string
+	// Start and End are the byte offsets of Text in the query.
+	Start, End int
+	// Kind is the kind of literal of Text.
+	Kind LiteralKind
+}
+
+// NormalizeWithParams normalizes input like Normalize, also returning the
+// constants that were replaced in the order they appear in input.
+func NormalizeWithParams(input string) (normalized string, params []NormalizedParam, err error) {
+	normalized, err = Normalize(input)
+	if err != nil {
+		return "", nil, err
+	}
+	scan, err := Scan(input)
+	if err != nil {
+		return "", nil, err
+	}
+	tokens := scan.GetTokens()
+
+	// The normalized query is input with constants replaced by $n, so walking
+	// both finds the constants where a reference is not copied from input.
+	i, j := 0, 0
+	for i < len(input) || j < len(normalized) {
+		n := paramRefLen(normalized[j:])
+		if n == 0 || !isConstStart(tokens, i) {
+			if i == len(input) || j == len(normalized) || input[i] != normalized[j] {
+				return "", nil, errNormalizeMismatch
+			}
+			i++
+			j++
+			continue
+		}
+		p, ok := constParam(input, tokens, i)
+		if !ok {
+			return "", nil, errNormalizeMismatch
+		}
+		// Only digits were matched, which cannot fail to convert.
+		p.Index, _ = strconv.Atoi(normalized[j+1 : j+n])
+		params = append(params, p)
+		i = p.End
+		j += n
+	}
+	return normalized, params, nil
+}
+
+// paramRefLen returns the length of the parameter reference $n s starts with,
+// or 0.
+func paramRefLen(s string) int {
+	n := 1
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	if n == 1 || s[0] != '$' {
+		return 0
+	}
+	return n
+}
+
+// isConstStart returns whether a token that may be a constant starts at
+// offset i, as opposed to a parameter reference.
+func isConstStart(tokens []*pganalyze.ScanToken, i int) bool {
+	k := tokenAt(tokens, i)
+	return k < len(tokens) && int(tokens[k].GetStart()) == i && tokens[k].GetToken() != pganalyze.Token_PARAM
+}
+
+// tokenAt returns the index of the first token starting at or after offset i.
+func tokenAt(tokens []*pganalyze.ScanToken, i int) int {
+	return sort.Search(len(tokens), func(k int) bool { return int(tokens[k].GetStart()) >= i })
+}
+
+// constParam returns the constant starting at offset i of input, which is the
+// next token or, for negative numbers, the minus sign and the next token.
+func constParam(input string, tokens []*pganalyze.ScanToken, i int) (NormalizedParam, bool) {
+	k := tokenAt(tokens, i)
+	if k < len(tokens) && input[i] == '-' {
+		k++
+	}
+	if k == len(tokens) {
+		return NormalizedParam{}, false
+	}
+	t := tokens[k]
+	// Unicode escape strings are scanned with following whitespace, which
+	// is not part of any constant.
+	end := len(strings.TrimRight(input[:t.GetEnd()], " \t\n\r\f\v"))
+	return NormalizedParam{
+		Text:  input[i:end],
+		Start: i,
+		End:   end,
+		Kind:  literalKind(t.GetToken()),
+	}, true
+}
+
+func literalKind(t pganalyze.Token) LiteralKind {
+	switch t { //nolint:exhaustive // Other tokens are names used as strings.
+	case pganalyze.Token_ICONST:
+		return LiteralInteger
+	case pganalyze.Token_FCONST:
+		return LiteralNumeric
+	case pganalyze.Token_BCONST, pganalyze.Token_XCONST:
+		return LiteralBitString
+	case pganalyze.Token_TRUE_P, pganalyze.Token_FALSE_P:
+		return LiteralBoolean
+	case pganalyze.Token_NULL_P, pganalyze.Token_ALL:
+		return LiteralNull
+	}
+	return LiteralString
+}
@@ -5,6 +5,9 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
 	pg_query "github.com/wasilibs/go-pgquery"
 	"github.com/wasilibs/go-pgquery/parser"
 )
@@ -74,3 +77,105 @@ func TestNormalizeError(t *testing.T) {
 		}
 	}
 }
+
+func TestNormalizeWithParams(t *testing.T) {
+	tests := []struct {
+		input      string
+		normalized string
+		params     []pg_query.NormalizedParam
+	}{
+		{
+			input:      "SELECT * FROM t WHERE a = 'x' AND b = -1.5 AND c = $1",
+			normalized: "SELECT * FROM t WHERE a = $2 AND b = $3 AND c = $1",
+			params: []pg_query.NormalizedParam{
+				{Index: 2, Text: "'x'", Start: 26, End: 29, Kind: pg_query.LiteralString},
+				{Index: 3, Text: "-1.5", Start: 38, End: 42, Kind: pg_query.LiteralNumeric},
+			},
+		},
+		{
+			input:      "SELECT 42, 99999999999999999999, B'101', X'ff', true, NULL",
+			normalized: "SELECT $1, $2, $3, $4, $5, $6",
+			params: []pg_query.NormalizedParam{
+				{Index: 1, Text: "42", Start: 7, End: 9, Kind: pg_query.LiteralInteger},
+				{Index: 2, Text: "99999999999999999999", Start: 11, End: 31, Kind: pg_query.LiteralNumeric},
+				{Index: 3, Text: "B'101'", Start: 33, End: 39, Kind: pg_query.LiteralBitString},
+				{Index: 4, Text: "X'ff'", Start: 41, End: 46, Kind: pg_query.LiteralBitString},
+				{Index: 5, Text: "true", Start: 48, End: 52, Kind: pg_query.LiteralBoolean},
+				{Index: 6, Text: "NULL", Start: 54, End: 58, Kind: pg_query.LiteralNull},
+			},
+		},
+		{
+			input:      "SELECT U&'d!0061t' UESCAPE '!', $$x$$, E'y'",
+			normalized: "SELECT $1 UESCAPE '!', $2, $3",
+			params: []pg_query.NormalizedParam{
+				{Index: 1, Text: "U&'d!0061t'", Start: 7, End: 18, Kind: pg_query.LiteralString},
+				{Index: 2, Text: "$$x$$", Start: 32, End: 37, Kind: pg_query.LiteralString},
+				{Index: 3, Text: "E'y'", Start: 39, End: 43, Kind: pg_query.LiteralString},
+			},
+		},
+		{
+			input:      "SELECT lower('A') FROM t GROUP BY lower('A')",
+			normalized: "SELECT lower($1) FROM t GROUP BY lower($1)",
+			params: []pg_query.NormalizedParam{
+				{Index: 1, Text: "'A'", Start: 13, End: 16, Kind: pg_query.LiteralString},
+				{Index: 1, Text: "'A'", Start: 40, End: 43, Kind: pg_query.LiteralString},
+			},
+		},
+		{
+			input:      "SELECT 1 FROM t LIMIT ALL",
+			normalized: "SELECT $1 FROM t LIMIT $2",
+			params: []pg_query.NormalizedParam{
+				{Index: 1, Text: "1", Start: 7, End: 8, Kind: pg_query.LiteralInteger},
+				{Index: 2, Text: "ALL", Start: 22, End: 25, Kind: pg_query.LiteralNull},
+			},
+		},
+		{
+			input:      "SET search_path TO app, public",
+			normalized: "SET search_path TO $1, $2",
+			params: []pg_query.NormalizedParam{
+				{Index: 1, Text: "app", Start: 19, End: 22, Kind: pg_query.LiteralString},
+				{Index: 2, Text: "public", Start: 24, End: 30, Kind: pg_query.LiteralString},
+			},
+		},
+		{
+			input:      "SELECT a FROM t",
+			normalized: "SELECT a FROM t",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			normalized, params, err := pg_query.NormalizeWithParams(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if normalized != tc.normalized {
+				t.Errorf("expected %s, actual %s", tc.normalized, normalized)
+			}
+			if diff := cmp.Diff(tc.params, params, cmpopts.EquateEmpty()); diff != "" {
+				t.Error(diff)
+			}
+			for _, p := range params {
+				if tc.input[p.Start:p.End] != p.Text {
+					t.Errorf("%+v does not match input %q", p, tc.input[p.Start:p.End])
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeWithParamsMatchesNormalize(t *testing.T) {
+	inputs := append(append(append([]string{}, libpgqueryNormalizeTests...), libpgqueryDeparseTests...), libpgqueryParseTests...)
+	for _, input := range inputs {
+		expected, err := pg_query.Normalize(input)
+		if err != nil {
+			continue
+		}
+		normalized, _, err := pg_query.NormalizeWithParams(input)
+		if err != nil {
+			t.Errorf("NormalizeWithParams(%s)\nerror %s", input, err)
+		} else if normalized != expected {
+			t.Errorf("NormalizeWithParams(%s)\nexpected %s\nactual %s", input, expected, normalized)
+		}
+	}
+}
@@ -0,0 +1,400 @@
+// Package param converts between SQL with constants and parameterized SQL
+// with the constant values extracted, working on parse trees.
+//
+// Parameterize replaces the constants of a parse tree with parameter
+// references, returning the extracted values, and Bind substitutes values for
+// parameter references and deparses the result. Together they allow building
+// prepared statements from ad-hoc SQL:
+//
+//	tree, _ := pg_query.Parse("SELECT * FROM users WHERE id = 42")
+//	tree, values := param.Parameterize(tree)
+//	sql, _ := pg_query.Deparse(tree) // SELECT * FROM users WHERE id = $1
+//	rows, _ := db.Query(sql, param.Args(values)...)
+package param
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+	"google.golang.org/protobuf/proto"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+	"github.com/wasilibs/go-pgquery/internal/walk"
+)
+
+var (
+	// ErrMissingValue is returned by Bind for parameter references without a
+	// value.
+	ErrMissingValue = errors.New("param: missing value for parameter")
+	// ErrInvalidValue is returned by Bind for values that cannot be
+	// represented as constants.
+	ErrInvalidValue = errors.New("param: invalid value")
+)
+
+// Kind is the kind of a constant.
+type Kind int
+
+const (
+	// KindInt is an integer constant.
+	KindInt Kind = iota + 1
+	// KindFloat is a numeric constant with a fractional part or exponent, or
+	// an integer too large for a 32-bit integer.
+	KindFloat
+	// KindString is a string constant.
+	KindString
+	// KindBitString is a bit string constant such as B'0101' or X'1F'.
+	KindBitString
+	// KindBool is a boolean constant.
+	KindBool
+	// KindNull is the NULL constant.
+	KindNull
+)
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	switch k {
+	case KindInt:
+		return "int"
+	case KindFloat:
+		return "float"
+	case KindString:
+		return "string"
+	case KindBitString:
+		return "bitstring"
+	case KindBool:
+		return "bool"
+	case KindNull:
+		return "null"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Value is the value of a constant.
+type Value struct {
+	// Number is the number of the parameter the value is bound to.
+	Number int32
+	Kind   Kind
+	// Int is the value of KindInt.
+	Int int64
+	// Bool is the value of KindBool.
+	Bool bool
+	// Text is the value of KindString, the decimal representation of
+	// KindFloat, or the bit string of KindBitString prefixed by b for binary
+	// or x for hexadecimal digits.
+	Text string
+	// Type is the name of the type the constant was cast to, such as "date"
+	// for DATE '2024-01-01', or empty if it was not cast. Types of the
+	// pg_catalog schema are not qualified.
+	Type string
+}
+
+// Any returns the value as a Go value suitable as a query argument: int64,
+// bool, string or nil. Floats and bit strings are returned in their text
+// representation, which Postgres accepts as input for numeric and bit types.
+func (v Value) Any() any {
+	switch v.Kind {
+	case KindInt:
+		return v.Int
+	case KindBool:
+		return v.Bool
+	case KindFloat, KindString, KindBitString:
+		return v.Text
+	default:
+		return nil
+	}
+}
+
+// Args returns the values as query arguments, ordered by parameter number.
+// The values must be numbered consecutively as returned by Parameterize.
+func Args(values []Value) []any {
+	args := make([]any, len(values))
+	for i, v := range values {
+		args[i] = v.Any()
+	}
+	return args
+}
+
+// Parameterize returns a copy of tree with constants replaced by parameter
+// references, and the replaced values ordered by parameter number. Parameters
+// are numbered after the highest parameter already referenced in tree.
+//
+// Only constants of SELECT, INSERT, UPDATE, DELETE and MERGE statements, which
+// can be prepared, are replaced. Constants that cannot be parameters without
+// changing the meaning of the statement are kept: NULL, type modifiers such as
+// varchar(10), and column positions in ORDER BY and GROUP BY.
+func Parameterize(tree *pganalyze.ParseResult) (*pganalyze.ParseResult, []Value) {
+	tree = proto.CloneOf(tree)
+	next := maxParam(tree) + 1
+
+	var values []Value
+	for _, raw := range tree.GetStmts() {
+		if !preparable(raw.GetStmt()) {
+			continue
+		}
+		// Constants kept as is, and the types constants are cast to.
+		keep := map[*pganalyze.Node]bool{}
+		types := map[*pganalyze.Node]string{}
+		walk.Walk(raw.GetStmt(), func(msg proto.Message) bool {
+			switch msg := msg.(type) {
+			case *pganalyze.TypeName:
+				return false
+			case *pganalyze.SortBy:
+				keep[msg.GetNode()] = true
+			case *pganalyze.SelectStmt:
+				for _, n := range msg.GetGroupClause() {
+					keep[n] = true
+				}
+			case *pganalyze.TypeCast:
+				types[msg.GetArg()] = typeName(msg.GetTypeName())
+			case *pganalyze.Node:
+				c := msg.GetAConst()
+				if c == nil || c.GetIsnull() || (keep[msg] && c.GetIval() != nil) {
+					return true
+				}
+				v := constValue(c)
+				v.Number, v.Type = next, types[msg]
+				values = append(values, v)
+				msg.Node = &pganalyze.Node_ParamRef{ParamRef: &pganalyze.ParamRef{Number: next, Location: c.GetLocation()}}
+				next++
+				return false
+			}
+			return true
+		})
+	}
+	return tree, values
+}
+
+// Bind substitutes values for the parameter references of tree matching their
+// numbers and deparses the result. Values are quoted by the deparser, so
+// arbitrary strings are safe to bind. Casts of parameter references are kept,
+// so the Type of values is not used. It returns ErrMissingValue if a parameter
+// has no value, and an error if the result does not parse.
+func Bind(tree *pganalyze.ParseResult, values []Value) (string, error) {
+	byNumber := make(map[int32]Value, len(values))
+	for _, v := range values {
+		byNumber[v.Number] = v
+	}
+
+	tree = proto.CloneOf(tree)
+	// The deparser does not parenthesize constants that are the argument of an
+	// indirection, as in $1.foo, or negative numbers cast to a type, as in
+	// $1::int, so these parameter references are kept and replaced by their
+	// parenthesized values after deparsing.
+	indirected := map[*pganalyze.Node]bool{}
+	cast := map[*pganalyze.Node]bool{}
+	wrapped := map[int32]string{}
+	var err error
+	walk.Walk(tree, func(msg proto.Message) bool {
+		if err != nil {
+			return false
+		}
+		switch msg := msg.(type) {
+		case *pganalyze.A_Indirection:
+			indirected[msg.GetArg()] = true
+		case *pganalyze.TypeCast:
+			cast[msg.GetArg()] = true
+		}
+		n, ok := msg.(*pganalyze.Node)
+		if !ok {
+			return true
+		}
+		p := n.GetParamRef()
+		if p == nil {
+			return true
+		}
+		v, ok := byNumber[p.GetNumber()]
+		if !ok {
+			err = fmt.Errorf("%w $%d", ErrMissingValue, p.GetNumber())
+			return false
+		}
+		var c *pganalyze.A_Const
+		if c, err = constNode(v); err != nil {
+			return false
+		}
+		if indirected[n] || cast[n] {
+			var lit string
+			if lit, err = literal(c); err != nil {
+				return false
+			}
+			if indirected[n] || strings.HasPrefix(lit, "-") {
+				wrapped[p.GetNumber()] = "(" + lit + ")"
+				return false
+			}
+		}
+		c.Location = p.GetLocation()
+		n.Node = &pganalyze.Node_AConst{AConst: c}
+		return false
+	})
+	if err != nil {
+		return "", err
+	}
+	sql, err := pg_query.Deparse(tree)
+	if err != nil {
+		return "", fmt.Errorf("param: deparsing tree: %w", err)
+	}
+	if len(wrapped) > 0 {
+		if sql, err = replaceParams(sql, wrapped); err != nil {
+			return "", err
+		}
+	}
+	if _, err := pg_query.Parse(sql); err != nil {
+		return "", fmt.Errorf("param: parsing bound SQL: %w", err)
+	}
+	return sql, nil
+}
+
+// literal returns the SQL text of c.
+func literal(c *pganalyze.A_Const) (string, error) {
+	stmt := &pganalyze.SelectStmt{TargetList: []*pganalyze.Node{{Node: &pganalyze.Node_ResTarget{ResTarget: &pganalyze.ResTarget{
+		Val: &pganalyze.Node{Node: &pganalyze.Node_AConst{AConst: c}},
+	}}}}}
+	sql, err := pg_query.Deparse(&pganalyze.ParseResult{Stmts: []*pganalyze.RawStmt{{
+		Stmt: &pganalyze.Node{Node: &pganalyze.Node_SelectStmt{SelectStmt: stmt}},
+	}}})
+	if err != nil {
+		return "", fmt.Errorf("param: deparsing value: %w", err)
+	}
+	return strings.TrimPrefix(sql, "SELECT "), nil
+}
+
+// replaceParams replaces the parameter references of sql with the text of
+// their numbers in texts.
+func replaceParams(sql string, texts map[int32]string) (string, error) {
+	scan, err := pg_query.Scan(sql)
+	if err != nil {
+		return "", fmt.Errorf("param: scanning deparsed SQL: %w", err)
+	}
+	var sb strings.Builder
+	last := 0
+	for _, tok := range scan.GetTokens() {
+		if tok.GetToken() != pganalyze.Token_PARAM {
+			continue
+		}
+		num, err := strconv.ParseInt(sql[tok.GetStart()+1:tok.GetEnd()], 10, 32)
+		if err != nil {
+			continue
+		}
+		text, ok := texts[int32(num)]
+		if !ok {
+			continue
+		}
+		sb.WriteString(sql[last:tok.GetStart()])
+		sb.WriteString(text)
+		last = int(tok.GetEnd())
+	}
+	sb.WriteString(sql[last:])
+	return sb.String(), nil
+}
+
+// preparable returns whether stmt can be prepared.
+func preparable(stmt *pganalyze.Node) bool {
+	switch stmt.GetNode().(type) {
+	case *pganalyze.Node_SelectStmt, *pganalyze.Node_InsertStmt, *pganalyze.Node_UpdateStmt,
+		*pganalyze.Node_DeleteStmt, *pganalyze.Node_MergeStmt:
+		return true
+	}
+	return false
+}
+
+func maxParam(tree *pganalyze.ParseResult) int32 {
+	var maxNum int32
+	walk.Walk(tree, func(msg proto.Message) bool {
+		if p, ok := msg.(*pganalyze.ParamRef); ok {
+			maxNum = max(maxNum, p.GetNumber())
+		}
+		return true
+	})
+	return maxNum
+}
+
+func constValue(c *pganalyze.A_Const) Value {
+	switch val := c.GetVal().(type) {
+	case *pganalyze.A_Const_Ival:
+		return Value{Kind: KindInt, Int: int64(val.Ival.GetIval())}
+	case *pganalyze.A_Const_Fval:
+		return Value{Kind: KindFloat, Text: val.Fval.GetFval()}
+	case *pganalyze.A_Const_Boolval:
+		return Value{Kind: KindBool, Bool: val.Boolval.GetBoolval()}
+	case *pganalyze.A_Const_Sval:
+		return Value{Kind: KindString, Text: val.Sval.GetSval()}
+	case *pganalyze.A_Const_Bsval:
+		return Value{Kind: KindBitString, Text: val.Bsval.GetBsval()}
+	}
+	return Value{Kind: KindNull}
+}
+
+func constNode(v Value) (*pganalyze.A_Const, error) {
+	switch v.Kind {
+	case KindInt:
+		if v.Int < math.MinInt32 || v.Int > math.MaxInt32 {
+			// The parser represents integers that do not fit in 32 bits as
+			// floats.
+			return &pganalyze.A_Const{Val: &pganalyze.A_Const_Fval{Fval: &pganalyze.Float{Fval: strconv.FormatInt(v.Int, 10)}}}, nil
+		}
+		return &pganalyze.A_Const{Val: &pganalyze.A_Const_Ival{Ival: &pganalyze.Integer{Ival: int32(v.Int)}}}, nil
+	case KindFloat:
+		if !floatPattern.MatchString(v.Text) {
+			return nil, fmt.Errorf("%w: float %q", ErrInvalidValue, v.Text)
+		}
+		return &pganalyze.A_Const{Val: &pganalyze.A_Const_Fval{Fval: &pganalyze.Float{Fval: v.Text}}}, nil
+	case KindString:
+		return &pganalyze.A_Const{Val: &pganalyze.A_Const_Sval{Sval: &pganalyze.String{Sval: v.Text}}}, nil
+	case KindBitString:
+		if !validBitString(v.Text) {
+			return nil, fmt.Errorf("%w: bit string %q", ErrInvalidValue, v.Text)
+		}
+		return &pganalyze.A_Const{Val: &pganalyze.A_Const_Bsval{Bsval: &pganalyze.BitString{Bsval: v.Text}}}, nil
+	case KindBool:
+		return &pganalyze.A_Const{Val: &pganalyze.A_Const_Boolval{Boolval: &pganalyze.Boolean{Boolval: v.Bool}}}, nil
+	case KindNull:
+		return &pganalyze.A_Const{Isnull: true}, nil
+	}
+	return nil, fmt.Errorf("%w: %v", ErrInvalidValue, v.Kind)
+}
+
+// floatPattern matches the decimal numbers the deparser writes as is.
+var floatPattern = regexp.MustCompile(`^-?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+
+// validBitString returns whether s is a bit string as represented in parse
+// trees, with a b or x prefix followed by binary or hexadecimal digits.
+func validBitString(s string) bool {
+	if s == "" {
+		return false
+	}
+	digits := "01"
+	switch s[0] {
+	case 'b':
+	case 'x':
+		digits = "0123456789abcdefABCDEF"
+	default:
+		return false
+	}
+	for _, r := range s[1:] {
+		if !strings.ContainsRune(digits, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// typeName returns the name of t, without the pg_catalog schema.
+func typeName(t *pganalyze.TypeName) string {
+	var names []string
+	for i, n := range t.GetNames() {
+		name := n.GetString_().GetSval()
+		if i == 0 && name == "pg_catalog" && len(t.GetNames()) > 1 {
+			continue
+		}
+		names = append(names, name)
+	}
+	name := strings.Join(names, ".")
+	if len(t.GetArrayBounds()) > 0 {
+		name += "[]"
+	}
+	return name
+}
@@ -0,0 +1,223 @@
+package param_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+	"github.com/wasilibs/go-pgquery/param"
+)
+
+func TestParameterize(t *testing.T) {
+	tests := []struct {
+		name   string
+		sql    string
+		want   string
+		values []param.Value
+		// bindErr is the error binding the values, which only restore the
+		// statement if it has no other parameters.
+		bindErr error
+	}{
+		{
+			name: "select",
+			sql:  "SELECT a FROM t WHERE b = 42 AND c = 'it''s' AND d > 1.5 AND e = true",
+			want: "SELECT a FROM t WHERE b = $1 AND c = $2 AND d > $3 AND e = $4",
+			values: []param.Value{
+				{Number: 1, Kind: param.KindInt, Int: 42},
+				{Number: 2, Kind: param.KindString, Text: "it's"},
+				{Number: 3, Kind: param.KindFloat, Text: "1.5"},
+				{Number: 4, Kind: param.KindBool, Bool: true},
+			},
+		},
+		{
+			name: "existing params",
+			sql:  "UPDATE t SET a = 'x' WHERE id = $2",
+			want: "UPDATE t SET a = $3 WHERE id = $2",
+			values: []param.Value{
+				{Number: 3, Kind: param.KindString, Text: "x"},
+			},
+			bindErr: param.ErrMissingValue,
+		},
+		{
+			name: "casts and type modifiers",
+			sql:  "SELECT DATE '2024-01-01', '1 day'::interval, 'x'::varchar(10), B'0101', 9999999999",
+			want: "SELECT $1::date, $2::interval, $3::varchar(10), $4, $5",
+			values: []param.Value{
+				{Number: 1, Kind: param.KindString, Text: "2024-01-01", Type: "date"},
+				{Number: 2, Kind: param.KindString, Text: "1 day", Type: "interval"},
+				{Number: 3, Kind: param.KindString, Text: "x", Type: "varchar"},
+				{Number: 4, Kind: param.KindBitString, Text: "b0101"},
+				{Number: 5, Kind: param.KindFloat, Text: "9999999999"},
+			},
+		},
+		{
+			name: "kept constants",
+			sql:  "SELECT a, count(*) FROM t WHERE b IS NULL OR c = NULL GROUP BY 1 ORDER BY 2 DESC LIMIT 10",
+			want: "SELECT a, count(*) FROM t WHERE b IS NULL OR c = NULL GROUP BY 1 ORDER BY 2 DESC LIMIT $1",
+			values: []param.Value{
+				{Number: 1, Kind: param.KindInt, Int: 10},
+			},
+		},
+		{
+			name: "utility statements",
+			sql:  "SET statement_timeout = 100; INSERT INTO t VALUES (1, 'a'); CREATE TABLE u (a int DEFAULT 0)",
+			want: "SET statement_timeout TO 100; INSERT INTO t VALUES ($1, $2); CREATE TABLE u (a int DEFAULT 0)",
+			values: []param.Value{
+				{Number: 1, Kind: param.KindInt, Int: 1},
+				{Number: 2, Kind: param.KindString, Text: "a"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tree, err := pg_query.Parse(tc.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			before, _ := pg_query.Deparse(tree)
+
+			got, values := param.Parameterize(tree)
+			sql, err := pg_query.Deparse(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tc.want {
+				t.Errorf("unexpected SQL:\nwant %s\ngot  %s", tc.want, sql)
+			}
+			if diff := cmp.Diff(tc.values, values, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("unexpected values (-want +got):\n%s", diff)
+			}
+			if after, _ := pg_query.Deparse(tree); after != before {
+				t.Errorf("input tree was modified:\nwant %s\ngot  %s", before, after)
+			}
+
+			bound, err := param.Bind(got, values)
+			if !errors.Is(err, tc.bindErr) {
+				t.Fatalf("unexpected error binding values: %v", err)
+			}
+			if err == nil && bound != before {
+				t.Errorf("Bind does not restore the statement:\nwant %s\ngot  %s", before, bound)
+			}
+		})
+	}
+}
+
+func TestBind(t *testing.T) {
+	tree, err := pg_query.Parse("INSERT INTO t VALUES ($1, $2, $3, $4, $5)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sql, err := param.Bind(tree, []param.Value{
+		{Number: 1, Kind: param.KindString, Text: "'; DROP TABLE t; --"},
+		{Number: 2, Kind: param.KindInt, Int: -5_000_000_000},
+		{Number: 3, Kind: param.KindNull},
+		{Number: 4, Kind: param.KindBitString, Text: "x1F"},
+		{Number: 5, Kind: param.KindString, Text: `back\slash`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `INSERT INTO t VALUES ('''; DROP TABLE t; --', -5000000000, NULL, x'1F', E'back\\slash')`
+	if sql != want {
+		t.Errorf("unexpected SQL:\nwant %s\ngot  %s", want, sql)
+	}
+
+	// The bound statement parses to the same values.
+	bound, err := pg_query.Parse(sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, values := param.Parameterize(bound)
+	wantValues := []param.Value{
+		{Number: 1, Kind: param.KindString, Text: "'; DROP TABLE t; --"},
+		{Number: 2, Kind: param.KindFloat, Text: "-5000000000"},
+		{Number: 3, Kind: param.KindBitString, Text: "x1F"},
+		{Number: 4, Kind: param.KindString, Text: `back\slash`},
+	}
+	if diff := cmp.Diff(wantValues, values); diff != "" {
+		t.Errorf("unexpected values (-want +got):\n%s", diff)
+	}
+}
+
+func TestBindParenthesized(t *testing.T) {
+	tree, err := pg_query.Parse("SELECT $1.foo, ($2)[1], $3::int, $4::text, $1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sql, err := param.Bind(tree, []param.Value{
+		{Number: 1, Kind: param.KindInt, Int: 3},
+		{Number: 2, Kind: param.KindString, Text: "{a}"},
+		{Number: 3, Kind: param.KindInt, Int: -3},
+		{Number: 4, Kind: param.KindString, Text: "x"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "SELECT (3).foo, ('{a}')[1], (-3)::int, 'x'::text, 3"
+	if sql != want {
+		t.Errorf("unexpected SQL:\nwant %s\ngot  %s", want, sql)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	tree, err := pg_query.Parse("SELECT $1, $2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		values []param.Value
+		want   error
+	}{
+		{
+			name:   "missing",
+			values: []param.Value{{Number: 1, Kind: param.KindInt, Int: 1}},
+			want:   param.ErrMissingValue,
+		},
+		{
+			name:   "invalid float",
+			values: []param.Value{{Number: 1, Kind: param.KindFloat, Text: "1; DROP TABLE t"}, {Number: 2, Kind: param.KindNull}},
+			want:   param.ErrInvalidValue,
+		},
+		{
+			name:   "infinite float",
+			values: []param.Value{{Number: 1, Kind: param.KindFloat, Text: "Inf"}, {Number: 2, Kind: param.KindNull}},
+			want:   param.ErrInvalidValue,
+		},
+		{
+			name:   "invalid bit string",
+			values: []param.Value{{Number: 1, Kind: param.KindBitString, Text: "b012"}, {Number: 2, Kind: param.KindNull}},
+			want:   param.ErrInvalidValue,
+		},
+		{
+			name:   "invalid kind",
+			values: []param.Value{{Number: 1}, {Number: 2, Kind: param.KindNull}},
+			want:   param.ErrInvalidValue,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := param.Bind(tree, tc.values); !errors.Is(err, tc.want) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestArgs(t *testing.T) {
+	got := param.Args([]param.Value{
+		{Kind: param.KindInt, Int: 1},
+		{Kind: param.KindFloat, Text: "1.5"},
+		{Kind: param.KindString, Text: "a"},
+		{Kind: param.KindBool, Bool: true},
+		{Kind: param.KindNull},
+	})
+	want := []any{int64(1), "1.5", "a", true, nil}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected args (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,66 @@
+// Package lru implements a fixed-size least recently used cache safe for
+// concurrent use.
+package lru
+
+import (
+	"container/list"
+	"sync"
+)
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// Cache is a least recently used cache.
+type Cache[K comparable, V any] struct {
+	size int
+
+	mu    sync.Mutex
+	items map[K]*list.Element
+	order *list.List
+}
+
+// New returns a cache holding at most size entries, at least one.
+func New[K comparable, V any](size int) *Cache[K, V] {
+	return &Cache[K, V]{size: max(size, 1), items: map[K]*list.Element{}, order: list.New()}
+}
+
+// Get returns the value of key and whether it is cached, marking it as
+// recently used.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*entry[K, V]).value, true //nolint:forcetypeassert // only entries are stored
+}
+
+// Add caches the value of key, evicting the least recently used entry if the
+// cache is full.
+func (c *Cache[K, V]) Add(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		e.Value.(*entry[K, V]).value = value //nolint:forcetypeassert // only entries are stored
+		c.order.MoveToFront(e)
+		return
+	}
+	c.items[key] = c.order.PushFront(&entry[K, V]{key: key, value: value})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*entry[K, V]).key) //nolint:forcetypeassert // only entries are stored
+	}
+}
+
+// Len returns the number of cached entries.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,237 @@
+// Package pgxtrace implements pgx query tracing with the normalized form,
+// fingerprint and statement types of queries.
+//
+// A Tracer analyzes each query traced by pgx, caching the analysis by SQL text
+// so that repeated executions do not parse the query again. The analyzed query
+// is added to the context of the call, where it can be retrieved with
+// QueryFromContext, logged to a slog.Logger, and passed to hooks, for example
+// to set the attributes of a trace span.
+//
+//	config.Tracer = pgxtrace.NewTracer(pgxtrace.Options{Logger: slog.Default()})
+package pgxtrace
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+	"github.com/wasilibs/go-pgquery/pgxtrace/internal/lru"
+)
+
+// Query is a query traced by pgx.
+type Query struct {
+	SQL  string
+	Args []any
+	// Normalized is SQL with constants replaced by parameter references, or
+	// empty if it does not parse.
+	Normalized string
+	// Fingerprint is the fingerprint of SQL, or empty if it does not parse.
+	Fingerprint string
+	// StatementTypes are the parse tree node types of the statements in SQL,
+	// such as "SelectStmt", or nil if it does not parse.
+	StatementTypes []string
+	// ParseErr is the error parsing SQL, if any.
+	ParseErr error
+	// Batch is whether the query was sent in a batch.
+	Batch bool
+}
+
+// Attrs returns the analysis of the query as attributes for logs or traces.
+// The SQL text and arguments are not included as they may contain sensitive
+// values.
+func (q *Query) Attrs() []slog.Attr {
+	if q.ParseErr != nil {
+		return []slog.Attr{slog.String("db.query.parse_error", q.ParseErr.Error())}
+	}
+	return []slog.Attr{
+		slog.String("db.query.normalized", q.Normalized),
+		slog.String("db.query.fingerprint", q.Fingerprint),
+		slog.Any("db.query.statement_types", q.StatementTypes),
+	}
+}
+
+// Result is the result of a traced query.
+type Result struct {
+	CommandTag pgconn.CommandTag
+	Err        error
+	// Duration is the time from the start of the query to its result. For
+	// queries sent in a batch, it is the time from the start of the batch.
+	Duration time.Duration
+}
+
+// Options configures a Tracer.
+type Options struct {
+	// Logger, if set, logs a record with the attributes of each query and its
+	// result when it ends.
+	Logger *slog.Logger
+	// Level is the level of records for successful queries. Failed queries are
+	// logged at slog.LevelError.
+	Level slog.Level
+	// Start, if set, is called when a query starts and returns the context for
+	// the rest of the call, for example with a span started. For queries sent
+	// in a batch, it is called when the result of the query is traced, just
+	// before End.
+	Start func(ctx context.Context, q *Query) context.Context
+	// End, if set, is called with the context returned by Start when a query
+	// ends.
+	End func(ctx context.Context, q *Query, res Result)
+	// CacheSize is the number of distinct SQL strings whose analysis is
+	// cached, 1000 if zero.
+	CacheSize int
+}
+
+// analysis is the cached analysis of SQL.
+type analysis struct {
+	normalized     string
+	fingerprint    string
+	statementTypes []string
+	err            error
+}
+
+// Tracer is a pgx.QueryTracer and pgx.BatchTracer analyzing traced queries.
+// It is safe for concurrent use by multiple connections.
+type Tracer struct {
+	opts  Options
+	cache *lru.Cache[string, analysis]
+}
+
+var (
+	_ pgx.QueryTracer = (*Tracer)(nil)
+	_ pgx.BatchTracer = (*Tracer)(nil)
+)
+
+// NewTracer returns a tracer configured by opts.
+func NewTracer(opts Options) *Tracer {
+	if opts.CacheSize == 0 {
+		opts.CacheSize = 1000
+	}
+	return &Tracer{opts: opts, cache: lru.New[string, analysis](opts.CacheSize)}
+}
+
+type queryKey struct{}
+
+type traceKey struct{}
+
+// trace is the state of a traced query or batch kept in the context between
+// the start and end of the call.
+type trace struct {
+	query *Query
+	start time.Time
+}
+
+// QueryFromContext returns the query added to the context of a pgx call by a
+// Tracer. For batches, it is the most recently traced query of the batch.
+func QueryFromContext(ctx context.Context) (*Query, bool) {
+	q, ok := ctx.Value(queryKey{}).(*Query)
+	return q, ok
+}
+
+// TraceQueryStart implements pgx.QueryTracer.
+func (t *Tracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	q := t.query(data.SQL, data.Args)
+	ctx = context.WithValue(ctx, queryKey{}, q)
+	if t.opts.Start != nil {
+		ctx = t.opts.Start(ctx, q)
+	}
+	return context.WithValue(ctx, traceKey{}, &trace{query: q, start: time.Now()})
+}
+
+// TraceQueryEnd implements pgx.QueryTracer.
+func (t *Tracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	tr, ok := ctx.Value(traceKey{}).(*trace)
+	if !ok {
+		return
+	}
+	t.end(ctx, tr.query, Result{CommandTag: data.CommandTag, Err: data.Err, Duration: time.Since(tr.start)})
+}
+
+// TraceBatchStart implements pgx.BatchTracer.
+func (t *Tracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceBatchStartData) context.Context {
+	return context.WithValue(ctx, traceKey{}, &trace{start: time.Now()})
+}
+
+// TraceBatchQuery implements pgx.BatchTracer.
+func (t *Tracer) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchQueryData) {
+	start := time.Now()
+	if tr, ok := ctx.Value(traceKey{}).(*trace); ok {
+		start = tr.start
+	}
+	q := t.query(data.SQL, data.Args)
+	q.Batch = true
+	ctx = context.WithValue(ctx, queryKey{}, q)
+	if t.opts.Start != nil {
+		ctx = t.opts.Start(ctx, q)
+	}
+	t.end(ctx, q, Result{CommandTag: data.CommandTag, Err: data.Err, Duration: time.Since(start)})
+}
+
+// TraceBatchEnd implements pgx.BatchTracer.
+func (t *Tracer) TraceBatchEnd(context.Context, *pgx.Conn, pgx.TraceBatchEndData) {
+	// Queries are traced individually by TraceBatchQuery.
+}
+
+// query returns the analyzed query, parsing it if its analysis is not cached.
+func (t *Tracer) query(sql string, args []any) *Query {
+	a, ok := t.cache.Get(sql)
+	if !ok {
+		a = analyze(sql)
+		t.cache.Add(sql, a)
+	}
+	return &Query{
+		SQL:            sql,
+		Args:           args,
+		Normalized:     a.normalized,
+		Fingerprint:    a.fingerprint,
+		StatementTypes: slices.Clone(a.statementTypes),
+		ParseErr:       a.err,
+	}
+}
+
+func analyze(sql string) analysis {
+	tree, err := pg_query.Parse(sql)
+	if err != nil {
+		return analysis{err: err}
+	}
+	types := make([]string, 0, len(tree.GetStmts()))
+	for _, s := range tree.GetStmts() {
+		// The statement is the set field of the Node oneof.
+		m := s.GetStmt().ProtoReflect()
+		if fd := m.WhichOneof(m.Descriptor().Oneofs().Get(0)); fd != nil {
+			types = append(types, string(fd.Message().Name()))
+		}
+	}
+	fingerprint, err := pg_query.Fingerprint(sql)
+	if err != nil {
+		return analysis{err: err}
+	}
+	normalized, err := pg_query.Normalize(sql)
+	if err != nil {
+		return analysis{err: err}
+	}
+	return analysis{normalized: normalized, fingerprint: fingerprint, statementTypes: types}
+}
+
+func (t *Tracer) end(ctx context.Context, q *Query, res Result) {
+	if t.opts.End != nil {
+		t.opts.End(ctx, q, res)
+	}
+	if t.opts.Logger == nil {
+		return
+	}
+	level := t.opts.Level
+	attrs := q.Attrs()
+	attrs = append(attrs, slog.Duration("duration", res.Duration), slog.String("command_tag", res.CommandTag.String()))
+	if q.Batch {
+		attrs = append(attrs, slog.Bool("batch", true))
+	}
+	if res.Err != nil {
+		level = slog.LevelError
+		attrs = append(attrs, slog.Any("error", res.Err))
+	}
+	t.opts.Logger.LogAttrs(ctx, level, "query", attrs...)
+}
@@ -0,0 +1,284 @@
+package pgxtrace_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgproto3"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+	"github.com/wasilibs/go-pgquery/pgxtrace"
+)
+
+// serve runs a fake server on c answering simple protocol queries. Statements
+// selecting from the missing table fail, other SELECT statements return a
+// single row and the rest update a single row.
+func serve(t *testing.T, c net.Conn) {
+	t.Helper()
+	defer c.Close()
+	b := pgproto3.NewBackend(c, c)
+	if _, err := b.ReceiveStartupMessage(); err != nil {
+		t.Error(err)
+		return
+	}
+	b.Send(&pgproto3.AuthenticationOk{})
+	b.Send(&pgproto3.ParameterStatus{Name: "client_encoding", Value: "UTF8"})
+	b.Send(&pgproto3.ParameterStatus{Name: "standard_conforming_strings", Value: "on"})
+	b.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+	if err := b.Flush(); err != nil {
+		t.Error(err)
+		return
+	}
+	for {
+		msg, err := b.Receive()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		switch msg := msg.(type) {
+		case *pgproto3.Query:
+			stmts, err := pg_query.SplitWithScanner(msg.String, true)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			for _, s := range stmts {
+				s = strings.TrimSpace(s)
+				if strings.Contains(s, "missing") {
+					b.Send(&pgproto3.ErrorResponse{Severity: "ERROR", Code: "42P01", Message: `relation "missing" does not exist`})
+					break
+				}
+				if strings.HasPrefix(s, "SELECT") {
+					b.Send(&pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{{Name: []byte("x"), DataTypeOID: 25, DataTypeSize: -1, TypeModifier: -1}}})
+					b.Send(&pgproto3.DataRow{Values: [][]byte{[]byte("1")}})
+					b.Send(&pgproto3.CommandComplete{CommandTag: []byte("SELECT 1")})
+				} else {
+					b.Send(&pgproto3.CommandComplete{CommandTag: []byte("UPDATE 1")})
+				}
+			}
+			b.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+			if err := b.Flush(); err != nil {
+				t.Error(err)
+				return
+			}
+		case *pgproto3.Terminate:
+			return
+		default:
+			t.Errorf("unexpected message %T", msg)
+			return
+		}
+	}
+}
+
+func connect(t *testing.T, tracer *pgxtrace.Tracer) *pgx.Conn {
+	t.Helper()
+	config, err := pgx.ParseConfig("postgres://user@127.0.0.1/db?sslmode=disable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+	config.Tracer = tracer
+	done := make(chan struct{})
+	config.DialFunc = func(context.Context, string, string) (net.Conn, error) {
+		client, server := net.Pipe()
+		go func() {
+			defer close(done)
+			serve(t, server)
+		}()
+		return client, nil
+	}
+	conn, err := pgx.ConnectConfig(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := conn.Close(context.Background()); err != nil {
+			t.Error(err)
+		}
+		<-done
+	})
+	return conn
+}
+
+// logRecords decodes the JSON records logged to buf.
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var r map[string]any
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestTracer(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "duration" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	type hookCall struct {
+		Hook        string
+		Fingerprint string
+		Batch       bool
+		Err         string
+	}
+	var calls []hookCall
+	type spanKey struct{}
+	tracer := pgxtrace.NewTracer(pgxtrace.Options{
+		Logger: logger,
+		Level:  slog.LevelDebug,
+		Start: func(ctx context.Context, q *pgxtrace.Query) context.Context {
+			calls = append(calls, hookCall{Hook: "start", Fingerprint: q.Fingerprint, Batch: q.Batch})
+			return context.WithValue(ctx, spanKey{}, q.Fingerprint)
+		},
+		End: func(ctx context.Context, q *pgxtrace.Query, res pgxtrace.Result) {
+			if span, _ := ctx.Value(spanKey{}).(string); span != q.Fingerprint {
+				t.Errorf("End called without context from Start: got %q, want %q", span, q.Fingerprint)
+			}
+			if fromCtx, ok := pgxtrace.QueryFromContext(ctx); !ok || fromCtx != q {
+				t.Error("query not in context")
+			}
+			c := hookCall{Hook: "end", Fingerprint: q.Fingerprint, Batch: q.Batch}
+			if res.Err != nil {
+				c.Err = res.Err.Error()
+			}
+			calls = append(calls, c)
+		},
+	})
+	conn := connect(t, tracer)
+
+	var x string
+	if err := conn.QueryRow(ctx, "SELECT x FROM t WHERE id = $1", 1).Scan(&x); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Exec(ctx, "UPDATE t SET x = 'a' WHERE id = 2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Exec(ctx, "SELECT * FROM missing"); err == nil {
+		t.Error("expected error from missing table")
+	}
+	if _, err := conn.Exec(ctx, "UPDATE t SET"); err != nil {
+		t.Fatal(err)
+	}
+
+	batch := &pgx.Batch{}
+	batch.Queue("SELECT x FROM t WHERE id = $1", 3)
+	batch.Queue("UPDATE t SET x = 'b' WHERE id = 4")
+	if err := conn.SendBatch(ctx, batch).Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	const (
+		selectFP  = "8749a1e44c9ce0da"
+		updateFP  = "654de85151de8702"
+		missingFP = "65938d17aa31b0a7"
+	)
+	missingErr := `ERROR: relation "missing" does not exist (SQLSTATE 42P01)`
+	wantCalls := []hookCall{
+		{Hook: "start", Fingerprint: selectFP},
+		{Hook: "end", Fingerprint: selectFP},
+		{Hook: "start", Fingerprint: updateFP},
+		{Hook: "end", Fingerprint: updateFP},
+		{Hook: "start", Fingerprint: missingFP},
+		{Hook: "end", Fingerprint: missingFP, Err: missingErr},
+		{Hook: "start"},
+		{Hook: "end"},
+		{Hook: "start", Fingerprint: selectFP, Batch: true},
+		{Hook: "end", Fingerprint: selectFP, Batch: true},
+		{Hook: "start", Fingerprint: updateFP, Batch: true},
+		{Hook: "end", Fingerprint: updateFP, Batch: true},
+	}
+	if diff := cmp.Diff(wantCalls, calls); diff != "" {
+		t.Errorf("unexpected hook calls (-want +got):\n%s", diff)
+	}
+
+	selectTypes := []any{"SelectStmt"}
+	updateTypes := []any{"UpdateStmt"}
+	wantRecords := []map[string]any{
+		{
+			"level": "DEBUG", "msg": "query", "command_tag": "SELECT 1",
+			"db.query.normalized": "SELECT x FROM t WHERE id = $1", "db.query.fingerprint": selectFP, "db.query.statement_types": selectTypes,
+		},
+		{
+			"level": "DEBUG", "msg": "query", "command_tag": "UPDATE 1",
+			"db.query.normalized": "UPDATE t SET x = $1 WHERE id = $2", "db.query.fingerprint": updateFP, "db.query.statement_types": updateTypes,
+		},
+		{
+			"level": "ERROR", "msg": "query", "command_tag": "", "error": missingErr,
+			"db.query.normalized": "SELECT * FROM missing", "db.query.fingerprint": missingFP, "db.query.statement_types": selectTypes,
+		},
+		{
+			"level": "DEBUG", "msg": "query", "command_tag": "UPDATE 1",
+			"db.query.parse_error": "syntax error at end of input",
+		},
+		{
+			"level": "DEBUG", "msg": "query", "command_tag": "SELECT 1", "batch": true,
+			"db.query.normalized": "SELECT x FROM t WHERE id = $1", "db.query.fingerprint": selectFP, "db.query.statement_types": selectTypes,
+		},
+		{
+			"level": "DEBUG", "msg": "query", "command_tag": "UPDATE 1", "batch": true,
+			"db.query.normalized": "UPDATE t SET x = $1 WHERE id = $2", "db.query.fingerprint": updateFP, "db.query.statement_types": updateTypes,
+		},
+	}
+	if diff := cmp.Diff(wantRecords, logRecords(t, &buf)); diff != "" {
+		t.Errorf("unexpected log records (-want +got):\n%s", diff)
+	}
+}
+
+func TestQueryStatementTypes(t *testing.T) {
+	tracer := pgxtrace.NewTracer(pgxtrace.Options{CacheSize: 1})
+	var got []*pgxtrace.Query
+	for _, sql := range []string{"BEGIN; INSERT INTO t VALUES (1); COMMIT", "SELECT 1", "BEGIN; INSERT INTO t VALUES (1); COMMIT"} {
+		ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: sql})
+		q, ok := pgxtrace.QueryFromContext(ctx)
+		if !ok {
+			t.Fatal("query not in context")
+		}
+		got = append(got, q)
+	}
+
+	want := []string{"TransactionStmt", "InsertStmt", "TransactionStmt"}
+	if diff := cmp.Diff(want, got[0].StatementTypes); diff != "" {
+		t.Errorf("unexpected statement types (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(got[0], got[2]); diff != "" {
+		t.Errorf("analysis differs after eviction (-first +second):\n%s", diff)
+	}
+}
+
+func TestQueryStatementTypesCopied(t *testing.T) {
+	tracer := pgxtrace.NewTracer(pgxtrace.Options{})
+	var got []*pgxtrace.Query
+	for range 2 {
+		ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+		q, ok := pgxtrace.QueryFromContext(ctx)
+		if !ok {
+			t.Fatal("query not in context")
+		}
+		got = append(got, q)
+	}
+
+	got[0].StatementTypes[0] = "modified"
+	if diff := cmp.Diff([]string{"SelectStmt"}, got[1].StatementTypes); diff != "" {
+		t.Errorf("cached statement types modified (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,147 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	errMessageTooLarge = errors.New("proxy: message too large")
+	errMalformed       = errors.New("proxy: malformed message")
+)
+
+// maxMessageLen is the maximum length of messages read. Postgres itself
+// limits most messages to 1 GB.
+const maxMessageLen = 1 << 30
+
+// Request codes of startup packets, sent in place of the protocol version.
+const (
+	cancelRequestCode = 80877102
+	sslRequestCode    = 80877103
+	gssencRequestCode = 80877104
+)
+
+// Message types.
+const (
+	msgQuery         = 'Q'
+	msgParse         = 'P'
+	msgSync          = 'S'
+	msgFunctionCall  = 'F'
+	msgTerminate     = 'X'
+	msgErrorResponse = 'E'
+	msgReadyForQuery = 'Z'
+)
+
+// readStartup reads a startup packet, which unlike other messages has no type.
+func readStartup(r *bufio.Reader) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, fmt.Errorf("proxy: reading startup packet: %w", err)
+	}
+	n := binary.BigEndian.Uint32(header[:])
+	if n < 8 || n > maxMessageLen {
+		return nil, errMalformed
+	}
+	packet := make([]byte, n)
+	copy(packet, header[:])
+	if _, err := io.ReadFull(r, packet[4:]); err != nil {
+		return nil, fmt.Errorf("proxy: reading startup packet: %w", err)
+	}
+	return packet, nil
+}
+
+// startupCode returns the protocol version or request code of a startup
+// packet.
+func startupCode(packet []byte) uint32 {
+	return binary.BigEndian.Uint32(packet[4:8])
+}
+
+// startupParams returns the parameters of a startup message, such as user
+// and database.
+func startupParams(packet []byte) map[string]string {
+	params := map[string]string{}
+	rest := packet[8:]
+	for {
+		key, r, ok := cutString(rest)
+		if !ok || key == "" {
+			return params
+		}
+		value, r, ok := cutString(r)
+		if !ok {
+			return params
+		}
+		params[key] = value
+		rest = r
+	}
+}
+
+// message is a typed protocol message.
+type message struct {
+	typ  byte
+	body []byte
+}
+
+func readMessage(r *bufio.Reader) (message, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			return message{}, io.EOF
+		}
+		return message{}, fmt.Errorf("proxy: reading message: %w", err)
+	}
+	n := binary.BigEndian.Uint32(header[1:])
+	if n < 4 {
+		return message{}, errMalformed
+	}
+	if n > maxMessageLen {
+		return message{}, errMessageTooLarge
+	}
+	body := make([]byte, n-4)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return message{}, fmt.Errorf("proxy: reading message: %w", err)
+	}
+	return message{typ: header[0], body: body}, nil
+}
+
+func (m message) bytes() []byte {
+	b := make([]byte, 5, 5+len(m.body))
+	b[0] = m.typ
+	binary.BigEndian.PutUint32(b[1:], uint32(len(m.body)+4)) //nolint:gosec // length is bounded when read
+	return append(b, m.body...)
+}
+
+func writeMessage(w io.Writer, m message) error {
+	if _, err := w.Write(m.bytes()); err != nil {
+		return fmt.Errorf("proxy: writing message: %w", err)
+	}
+	return nil
+}
+
+// cutString cuts a null-terminated string from the start of b.
+func cutString(b []byte) (string, []byte, bool) {
+	i := bytes.IndexByte(b, 0)
+	if i < 0 {
+		return "", nil, false
+	}
+	return string(b[:i]), b[i+1:], true
+}
+
+func appendString(b []byte, s string) []byte {
+	return append(append(b, s...), 0)
+}
+
+// errorResponse returns an ErrorResponse message.
+func errorResponse(code, msg string) message {
+	var body []byte
+	for _, f := range []struct {
+		typ   byte
+		value string
+	}{{'S', "ERROR"}, {'V', "ERROR"}, {'C', code}, {'M', msg}} {
+		body = appendString(append(body, f.typ), f.value)
+	}
+	return message{typ: msgErrorResponse, body: append(body, 0)}
+}
@@ -0,0 +1,330 @@
+// Package proxy implements a PostgreSQL wire protocol proxy that inspects the
+// queries sent by clients.
+//
+// The proxy relays protocol version 3 messages between clients and a backend,
+// intercepting the SQL of simple Query and extended protocol Parse messages.
+// The SQL is parsed and fingerprinted and passed to hooks, which may allow,
+// reject or rewrite it. All other messages, including authentication, are
+// relayed unchanged. TLS is not supported, and clients requesting it are told
+// to continue unencrypted.
+//
+// A rejected query is answered with an ErrorResponse in the place where the
+// backend's error would have been, followed by the ReadyForQuery of the
+// backend. The backend does not see the rejected query, so a rejection within
+// a transaction block does not abort the transaction as a backend error would.
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sync"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+)
+
+// Session is a client connection.
+type Session struct {
+	// RemoteAddr is the address of the client.
+	RemoteAddr net.Addr
+	// Params are the parameters of the startup message, such as user and
+	// database.
+	Params map[string]string
+}
+
+// Query is SQL sent by a client.
+type Query struct {
+	Session *Session
+	SQL     string
+	// Tree is the parse tree of SQL, or nil if it does not parse.
+	Tree *pganalyze.ParseResult
+	// Err is the error parsing SQL, if any.
+	Err error
+	// Fingerprint is the fingerprint of SQL, or empty if it does not parse.
+	Fingerprint string
+	// Extended is whether the SQL is from a Parse message of the extended
+	// query protocol rather than a simple Query message.
+	Extended bool
+	// StatementName is the name of the prepared statement of a Parse message,
+	// empty for the unnamed statement.
+	StatementName string
+}
+
+// Decision is the outcome of a hook for a query.
+type Decision struct {
+	sql     string
+	code    string
+	message string
+}
+
+// Allow is the decision to pass the query on unchanged.
+var Allow = Decision{}
+
+// Reject returns the decision to reject the query with an error with the
+// SQLSTATE code and message, such as "42501" for insufficient privilege.
+func Reject(code, message string) Decision {
+	return Decision{code: code, message: message}
+}
+
+// Rewrite returns the decision to replace the SQL of the query.
+func Rewrite(sql string) Decision {
+	return Decision{sql: sql}
+}
+
+func (d Decision) rejected() bool {
+	return d.code != "" || d.message != ""
+}
+
+// Hook inspects a query. Hooks are called in order, each seeing the SQL
+// rewritten by the previous ones, until one rejects the query.
+type Hook func(ctx context.Context, q *Query) Decision
+
+// Proxy relays connections to a backend.
+type Proxy struct {
+	// Backend connects to the backend for a client connection.
+	Backend func(ctx context.Context) (net.Conn, error)
+	// Hooks are called for each query.
+	Hooks []Hook
+	// ErrorLog logs errors of connections served by Serve. Errors are
+	// discarded if nil.
+	ErrorLog *log.Logger
+}
+
+// Serve accepts connections from l and serves each in a new goroutine until
+// accepting fails.
+func (p *Proxy) Serve(ctx context.Context, l net.Listener) error {
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			return fmt.Errorf("proxy: accepting connection: %w", err)
+		}
+		go func() {
+			if err := p.ServeConn(ctx, c); err != nil && p.ErrorLog != nil {
+				p.ErrorLog.Printf("proxy: connection from %s: %v", c.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+// ServeConn relays the client connection to a new backend connection until
+// either is closed. It closes the client connection when done.
+func (p *Proxy) ServeConn(ctx context.Context, client net.Conn) error {
+	defer client.Close()
+	cr := bufio.NewReader(client)
+
+	var startup []byte
+	for {
+		packet, err := readStartup(cr)
+		if err != nil {
+			return err
+		}
+		code := startupCode(packet)
+		if code != sslRequestCode && code != gssencRequestCode {
+			startup = packet
+			break
+		}
+		if _, err := client.Write([]byte{'N'}); err != nil {
+			return fmt.Errorf("proxy: declining encryption: %w", err)
+		}
+	}
+
+	backend, err := p.Backend(ctx)
+	if err != nil {
+		return fmt.Errorf("proxy: connecting to backend: %w", err)
+	}
+	defer backend.Close()
+	if _, err := backend.Write(startup); err != nil {
+		return fmt.Errorf("proxy: writing startup packet: %w", err)
+	}
+	if startupCode(startup) == cancelRequestCode {
+		// The backend closes the connection after a cancel request.
+		_, _ = io.Copy(io.Discard, backend)
+		return nil
+	}
+
+	c := &conn{
+		proxy:      p,
+		session:    &Session{RemoteAddr: client.RemoteAddr(), Params: startupParams(startup)},
+		client:     cr,
+		clientW:    client,
+		backend:    bufio.NewReader(backend),
+		backendW:   backend,
+		injections: map[int]message{},
+	}
+	errc := make(chan error, 2)
+	go func() { errc <- c.relayClient(ctx) }()
+	go func() { errc <- c.relayBackend() }()
+	err = <-errc
+	// Unblock the other direction.
+	_ = client.Close()
+	_ = backend.Close()
+	<-errc
+	if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+// conn is a relayed connection.
+//
+// Every Query, Sync and FunctionCall message sent to the backend is answered
+// by a ReadyForQuery message, which numbers them. A rejected query is replaced
+// by a Sync, with the error to send before the matching ReadyForQuery.
+type conn struct {
+	proxy    *Proxy
+	session  *Session
+	client   *bufio.Reader
+	clientW  io.Writer
+	backend  *bufio.Reader
+	backendW io.Writer
+
+	// syncs is the number of messages sent to the backend that are answered
+	// by ReadyForQuery, the first answering the startup message.
+	syncs int
+	// rejected is the error of a rejected Parse message, after which messages
+	// are discarded until a Sync as the backend does after an error.
+	rejected *message
+
+	mu         sync.Mutex
+	injections map[int]message
+}
+
+func (c *conn) relayClient(ctx context.Context) error {
+	for {
+		msg, err := readMessage(c.client)
+		if err != nil {
+			return err
+		}
+
+		if c.rejected != nil {
+			if msg.typ != msgSync {
+				continue
+			}
+			c.syncs++
+			c.inject(*c.rejected)
+			c.rejected = nil
+		} else {
+			switch msg.typ {
+			case msgQuery:
+				sql, _, ok := cutString(msg.body)
+				if !ok {
+					return errMalformed
+				}
+				c.syncs++
+				d := c.check(ctx, &Query{SQL: sql})
+				if d.rejected() {
+					c.inject(errorResponse(d.code, d.message))
+					msg = message{typ: msgSync}
+				} else if d.sql != "" {
+					msg.body = appendString(nil, d.sql)
+				}
+			case msgParse:
+				name, rest, ok := cutString(msg.body)
+				if !ok {
+					return errMalformed
+				}
+				sql, params, ok := cutString(rest)
+				if !ok {
+					return errMalformed
+				}
+				d := c.check(ctx, &Query{SQL: sql, Extended: true, StatementName: name})
+				if d.rejected() {
+					errMsg := errorResponse(d.code, d.message)
+					c.rejected = &errMsg
+					continue
+				}
+				if d.sql != "" {
+					msg.body = append(appendString(appendString(nil, name), d.sql), params...)
+				}
+			case msgSync, msgFunctionCall:
+				c.syncs++
+			}
+		}
+
+		if err := writeMessage(c.backendW, msg); err != nil {
+			return err
+		}
+		if msg.typ == msgTerminate {
+			return nil
+		}
+	}
+}
+
+// check runs the hooks for the query, returning the decision with the final
+// SQL if rewritten.
+func (c *conn) check(ctx context.Context, q *Query) Decision {
+	q.Session = c.session
+	original := q.SQL
+	analyze(q)
+	for _, hook := range c.proxy.Hooks {
+		d := hook(ctx, q)
+		if d.rejected() {
+			return d
+		}
+		if d.sql != "" && d.sql != q.SQL {
+			q.SQL = d.sql
+			analyze(q)
+		}
+	}
+	if q.SQL != original {
+		return Rewrite(q.SQL)
+	}
+	return Allow
+}
+
+func analyze(q *Query) {
+	q.Tree, q.Err = pg_query.Parse(q.SQL)
+	q.Fingerprint = ""
+	if q.Err == nil {
+		q.Fingerprint, _ = pg_query.Fingerprint(q.SQL)
+	}
+}
+
+func (c *conn) inject(msg message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.injections[c.syncs] = msg
+}
+
+func (c *conn) takeInjection(n int) (message, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	msg, ok := c.injections[n]
+	delete(c.injections, n)
+	return msg, ok
+}
+
+func (c *conn) relayBackend() error {
+	ready := 0
+	// backendError is whether the backend sent an error since the last
+	// ReadyForQuery, in which case it discarded the messages up to the Sync
+	// like the proxy and an injected error would be a duplicate.
+	backendError := false
+	for {
+		msg, err := readMessage(c.backend)
+		if err != nil {
+			return err
+		}
+		switch msg.typ {
+		case msgErrorResponse:
+			backendError = true
+		case msgReadyForQuery:
+			if inj, ok := c.takeInjection(ready); ok && !backendError {
+				if err := writeMessage(c.clientW, inj); err != nil {
+					return err
+				}
+			}
+			ready++
+			backendError = false
+		}
+		if err := writeMessage(c.clientW, msg); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package proxy_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/wasilibs/go-pgquery/proxy"
+)
+
+type msg struct {
+	typ  byte
+	body string
+}
+
+func writeMsg(t *testing.T, w io.Writer, typ byte, body string) {
+	t.Helper()
+	b := []byte{typ, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(b[1:], uint32(len(body)+4))
+	// Messages are also written from other goroutines, which cannot call
+	// t.Fatal.
+	if _, err := w.Write(append(b, body...)); err != nil {
+		t.Error(err)
+	}
+}
+
+func readMsg(r *bufio.Reader) (msg, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return msg{}, err
+	}
+	body := make([]byte, binary.BigEndian.Uint32(header[1:])-4)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return msg{}, err
+	}
+	return msg{typ: header[0], body: string(body)}, nil
+}
+
+func startupPacket(code uint32, params ...string) []byte {
+	body := binary.BigEndian.AppendUint32(nil, code)
+	for _, p := range params {
+		body = append(append(body, p...), 0)
+	}
+	body = append(body, 0)
+	return append(binary.BigEndian.AppendUint32(nil, uint32(len(body)+4)), body...)
+}
+
+// backend is a fake backend that records the SQL it receives and answers it
+// with fixed responses.
+type backend struct {
+	mu       sync.Mutex
+	received []string
+}
+
+func (b *backend) record(s string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.received = append(b.received, s)
+}
+
+func (b *backend) serve(t *testing.T, c net.Conn) {
+	defer c.Close()
+	r := bufio.NewReader(c)
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return
+	}
+	if _, err := r.Discard(int(binary.BigEndian.Uint32(header[:])) - 4); err != nil {
+		return
+	}
+	writeMsg(t, c, 'R', "\x00\x00\x00\x00")
+	writeMsg(t, c, 'Z', "I")
+	for {
+		m, err := readMsg(r)
+		if err != nil {
+			return
+		}
+		switch m.typ {
+		case 'Q':
+			b.record("Q " + strings.TrimSuffix(m.body, "\x00"))
+			writeMsg(t, c, 'C', "SELECT 1\x00")
+			writeMsg(t, c, 'Z', "I")
+		case 'P':
+			_, rest, _ := strings.Cut(m.body, "\x00")
+			sql, _, _ := strings.Cut(rest, "\x00")
+			b.record("P " + sql)
+			if strings.Contains(sql, "invalid") {
+				writeMsg(t, c, 'E', "SERROR\x00C42601\x00Msyntax error\x00\x00")
+				continue
+			}
+			writeMsg(t, c, '1', "")
+		case 'B':
+			writeMsg(t, c, '2', "")
+		case 'E':
+			writeMsg(t, c, 'C', "SELECT 1\x00")
+		case 'S':
+			b.record("S")
+			writeMsg(t, c, 'Z', "I")
+		case 'X':
+			return
+		}
+	}
+}
+
+type client struct {
+	t *testing.T
+	c net.Conn
+	r *bufio.Reader
+}
+
+func (c *client) send(typ byte, body string) {
+	c.t.Helper()
+	writeMsg(c.t, c.c, typ, body)
+}
+
+// receive returns the messages up to and including the next ReadyForQuery.
+func (c *client) receive() []msg {
+	c.t.Helper()
+	var msgs []msg
+	for {
+		m, err := readMsg(c.r)
+		if err != nil {
+			c.t.Fatal(err)
+		}
+		msgs = append(msgs, m)
+		if m.typ == 'Z' {
+			return msgs
+		}
+	}
+}
+
+func startProxy(t *testing.T, hooks ...proxy.Hook) (*client, *backend) {
+	t.Helper()
+	be := &backend{}
+	p := &proxy.Proxy{
+		Backend: func(context.Context) (net.Conn, error) {
+			proxySide, backendSide := net.Pipe()
+			go be.serve(t, backendSide)
+			return proxySide, nil
+		},
+		Hooks: hooks,
+	}
+	clientSide, proxySide := net.Pipe()
+	done := make(chan error, 1)
+	go func() { done <- p.ServeConn(context.Background(), proxySide) }()
+	t.Cleanup(func() {
+		_ = clientSide.Close()
+		if err := <-done; err != nil {
+			t.Errorf("ServeConn: %v", err)
+		}
+	})
+
+	c := &client{t: t, c: clientSide, r: bufio.NewReader(clientSide)}
+	// Encryption is declined.
+	if _, err := clientSide.Write(startupPacket(80877103)); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := c.r.ReadByte(); err != nil || b != 'N' {
+		t.Fatalf("unexpected response to SSLRequest %q, %v", b, err)
+	}
+	if _, err := clientSide.Write(startupPacket(3<<16, "user", "alice", "database", "app")); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]msg{{'R', "\x00\x00\x00\x00"}, {'Z', "I"}}, c.receive(), cmp.AllowUnexported(msg{})); diff != "" {
+		t.Fatalf("unexpected startup response (-want +got):\n%s", diff)
+	}
+	return c, be
+}
+
+const rejectError = "SERROR\x00VERROR\x00C42501\x00Mdrop is not allowed\x00\x00"
+
+func TestProxy(t *testing.T) {
+	var queries []proxy.Query
+	hooks := []proxy.Hook{
+		func(_ context.Context, q *proxy.Query) proxy.Decision {
+			queries = append(queries, *q)
+			if q.Tree != nil && q.Tree.GetStmts()[0].GetStmt().GetDropStmt() != nil {
+				return proxy.Reject("42501", "drop is not allowed")
+			}
+			return proxy.Allow
+		},
+		func(_ context.Context, q *proxy.Query) proxy.Decision {
+			if strings.Contains(q.SQL, "secrets") {
+				return proxy.Rewrite("SELECT 'redacted'")
+			}
+			return proxy.Allow
+		},
+	}
+	c, be := startProxy(t, hooks...)
+
+	tests := []struct {
+		name string
+		send []msg
+		want []msg
+	}{
+		{
+			name: "allowed",
+			send: []msg{{'Q', "SELECT 1\x00"}},
+			want: []msg{{'C', "SELECT 1\x00"}, {'Z', "I"}},
+		},
+		{
+			name: "rejected",
+			send: []msg{{'Q', "DROP TABLE users\x00"}},
+			want: []msg{{'E', rejectError}, {'Z', "I"}},
+		},
+		{
+			name: "rewritten",
+			send: []msg{{'Q', "SELECT * FROM secrets\x00"}},
+			want: []msg{{'C', "SELECT 1\x00"}, {'Z', "I"}},
+		},
+		{
+			name: "extended rejected",
+			send: []msg{{'P', "s1\x00DROP TABLE users\x00\x00\x00"}, {'B', "\x00s1\x00\x00\x00\x00\x00\x00\x00"}, {'E', "\x00\x00\x00\x00\x00"}, {'S', ""}},
+			want: []msg{{'E', rejectError}, {'Z', "I"}},
+		},
+		{
+			name: "extended rewritten",
+			send: []msg{{'P', "\x00SELECT * FROM secrets\x00\x00\x00"}, {'B', "\x00\x00\x00\x00\x00\x00\x00\x00"}, {'E', "\x00\x00\x00\x00\x00"}, {'S', ""}},
+			want: []msg{{'1', ""}, {'2', ""}, {'C', "SELECT 1\x00"}, {'Z', "I"}},
+		},
+		{
+			name: "backend error",
+			send: []msg{{'P', "\x00SELECT invalid(\x00\x00\x00"}, {'S', ""}},
+			want: []msg{{'E', "SERROR\x00C42601\x00Msyntax error\x00\x00"}, {'Z', "I"}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Pipes are unbuffered, so messages are sent concurrently with
+			// receiving the responses to earlier ones.
+			sent := make(chan struct{})
+			go func() {
+				defer close(sent)
+				for _, m := range tc.send {
+					c.send(m.typ, m.body)
+				}
+			}()
+			defer func() { <-sent }()
+			if diff := cmp.Diff(tc.want, c.receive(), cmp.AllowUnexported(msg{})); diff != "" {
+				t.Errorf("unexpected response (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	c.send('X', "")
+
+	wantReceived := []string{
+		"Q SELECT 1",
+		"S",
+		"Q SELECT 'redacted'",
+		"S",
+		"P SELECT 'redacted'",
+		"S",
+		"P SELECT invalid(",
+		"S",
+	}
+	be.mu.Lock()
+	defer be.mu.Unlock()
+	if diff := cmp.Diff(wantReceived, be.received); diff != "" {
+		t.Errorf("unexpected SQL received by backend (-want +got):\n%s", diff)
+	}
+
+	type seen struct {
+		sql         string
+		fingerprint bool
+		parsed      bool
+		extended    bool
+		name        string
+		user        string
+	}
+	var got []seen
+	for _, q := range queries {
+		got = append(got, seen{q.SQL, q.Fingerprint != "", q.Err == nil, q.Extended, q.StatementName, q.Session.Params["user"]})
+	}
+	want := []seen{
+		{"SELECT 1", true, true, false, "", "alice"},
+		{"DROP TABLE users", true, true, false, "", "alice"},
+		{"SELECT * FROM secrets", true, true, false, "", "alice"},
+		{"DROP TABLE users", true, true, true, "s1", "alice"},
+		{"SELECT * FROM secrets", true, true, true, "", "alice"},
+		{"SELECT invalid(", false, false, true, "", "alice"},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(seen{}), cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("unexpected queries seen by hooks (-want +got):\n%s", diff)
+	}
+}
+
+func TestProxyRelaysUnchanged(t *testing.T) {
+	c, be := startProxy(t)
+	c.send('Q', "SELECT 1; SELECT 2\x00")
+	c.receive()
+	c.send('X', "")
+	be.mu.Lock()
+	defer be.mu.Unlock()
+	if diff := cmp.Diff([]string{"Q SELECT 1; SELECT 2"}, be.received); diff != "" {
+		t.Errorf("unexpected SQL received by backend (-want +got):\n%s", diff)
+	}
+}
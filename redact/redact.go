@@ -0,0 +1,395 @@
+// Package redact masks sensitive constants of SQL statements while keeping
+// other constants intact, working on parse trees.
+//
+// Unlike pg_query.Normalize, which replaces every constant, a Policy only
+// masks constants that are compared to, inserted into or assigned to columns
+// matching its patterns, and optionally constants that look like personal
+// data, so that values useful for debugging such as status strings and small
+// integers stay readable:
+//
+//	p := redact.Policy{Columns: []string{"email", "*_token"}}
+//	sql, _ := p.Redact("SELECT * FROM users WHERE email = 'a@example.com' AND status = 'active'")
+//	// SELECT * FROM users WHERE email = 'REDACTED' AND status = 'active'
+package redact
+
+import (
+	"cmp"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+	"google.golang.org/protobuf/proto"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+	"github.com/wasilibs/go-pgquery/internal/walk"
+)
+
+// DefaultMask is the value replacing redacted constants if Policy.Mask is
+// empty.
+const DefaultMask = "REDACTED"
+
+// Policy configures which constants are redacted.
+type Policy struct {
+	// Columns are patterns of sensitive column names, such as "email",
+	// "*_token" or "ssn". They use the syntax of path.Match and are matched
+	// case-insensitively against unqualified column names.
+	Columns []string
+	// DetectPII also redacts constants that look like email addresses, US
+	// social security numbers or payment card numbers, regardless of how they
+	// are used.
+	DetectPII bool
+	// Mask is the string replacing redacted constants, DefaultMask if empty.
+	Mask string
+}
+
+// Redaction is a constant redacted by a Policy.
+type Redaction struct {
+	// Start and End are the byte offsets of the constant in the input,
+	// including quotes and a leading minus sign.
+	Start, End int
+	// Text is the constant as written in the input.
+	Text string
+	// Column is the name of the column the constant is used with, or empty if
+	// it was redacted because it looks like personal data.
+	Column string
+}
+
+// Find returns the constants of sql the policy redacts, ordered by position.
+func (p Policy) Find(sql string) ([]Redaction, error) {
+	tree, err := pg_query.Parse(sql)
+	if err != nil {
+		return nil, fmt.Errorf("redact: parsing input: %w", err)
+	}
+	scan, err := pg_query.Scan(sql)
+	if err != nil {
+		return nil, fmt.Errorf("redact: scanning input: %w", err)
+	}
+	consts, err := p.find(tree)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := scan.GetTokens()
+	var res []Redaction
+	for c, column := range consts {
+		start, end, ok := constSpan(sql, tokens, int(c.GetLocation()))
+		if !ok {
+			continue
+		}
+		res = append(res, Redaction{Start: start, End: end, Text: sql[start:end], Column: column})
+	}
+	slices.SortFunc(res, func(a, b Redaction) int {
+		return cmp.Compare(a.Start, b.Start)
+	})
+	return res, nil
+}
+
+// Redact returns sql with the constants the policy redacts replaced by the
+// mask as a string constant. The rest of sql, including formatting and
+// comments, is kept as is.
+func (p Policy) Redact(sql string) (string, error) {
+	redactions, err := p.Find(sql)
+	if err != nil {
+		return "", err
+	}
+	mask := quote(p.mask())
+	var b strings.Builder
+	last := 0
+	for _, r := range redactions {
+		b.WriteString(sql[last:r.Start])
+		b.WriteString(mask)
+		last = r.End
+	}
+	b.WriteString(sql[last:])
+	return b.String(), nil
+}
+
+// RedactTree returns a copy of tree with the constants the policy redacts
+// replaced by the mask as a string constant, for use with pg_query.Deparse.
+func (p Policy) RedactTree(tree *pganalyze.ParseResult) (*pganalyze.ParseResult, error) {
+	tree = proto.CloneOf(tree)
+	consts, err := p.find(tree)
+	if err != nil {
+		return nil, err
+	}
+	for c := range consts {
+		c.Isnull = false
+		c.Val = &pganalyze.A_Const_Sval{Sval: &pganalyze.String{Sval: p.mask()}}
+	}
+	return tree, nil
+}
+
+func (p Policy) mask() string {
+	if p.Mask == "" {
+		return DefaultMask
+	}
+	return p.Mask
+}
+
+// find returns the constants of tree to redact with the column they are used
+// with.
+func (p Policy) find(tree *pganalyze.ParseResult) (map[*pganalyze.A_Const]string, error) {
+	patterns := make([]string, len(p.Columns))
+	for i, pattern := range p.Columns {
+		pattern = strings.ToLower(pattern)
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("redact: column pattern %q: %w", p.Columns[i], err)
+		}
+		patterns[i] = pattern
+	}
+
+	f := &finder{patterns: patterns, consts: map[*pganalyze.A_Const]string{}}
+	walk.Walk(tree, func(msg proto.Message) bool {
+		switch msg := msg.(type) {
+		case *pganalyze.A_Expr:
+			f.compare(msg.GetLexpr(), msg.GetRexpr())
+		case *pganalyze.InsertStmt:
+			f.insert(msg)
+		case *pganalyze.UpdateStmt:
+			f.assign(msg.GetTargetList())
+		case *pganalyze.OnConflictClause:
+			f.assign(msg.GetTargetList())
+		case *pganalyze.MergeWhenClause:
+			if msg.GetCommandType() == pganalyze.CmdType_CMD_INSERT {
+				f.values(msg.GetTargetList(), msg.GetValues())
+			} else {
+				f.assign(msg.GetTargetList())
+			}
+		case *pganalyze.A_Const:
+			if _, ok := f.consts[msg]; !ok && p.DetectPII && looksLikePII(msg) {
+				f.consts[msg] = ""
+			}
+		}
+		return true
+	})
+	return f.consts, nil
+}
+
+type finder struct {
+	patterns []string
+	consts   map[*pganalyze.A_Const]string
+}
+
+// sensitive returns whether column matches a pattern.
+func (f *finder) sensitive(column string) bool {
+	if column == "" {
+		return false
+	}
+	column = strings.ToLower(column)
+	for _, pattern := range f.patterns {
+		if ok, _ := path.Match(pattern, column); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// compare redacts the constants of an operand compared to a sensitive column
+// in the other operand, pairing the elements of row comparisons.
+func (f *finder) compare(l, r *pganalyze.Node) {
+	if lr, rr := l.GetRowExpr().GetArgs(), r.GetRowExpr().GetArgs(); lr != nil && len(lr) == len(rr) {
+		for i := range lr {
+			f.compare(lr[i], rr[i])
+		}
+		return
+	}
+	if column := f.column(l); column != "" {
+		f.redact(r, column)
+	}
+	if column := f.column(r); column != "" {
+		f.redact(l, column)
+	}
+}
+
+// column returns the name of a sensitive column referenced by n outside of
+// subqueries, or an empty string.
+func (f *finder) column(n *pganalyze.Node) string {
+	var column string
+	walk.Walk(n, func(msg proto.Message) bool {
+		switch msg := msg.(type) {
+		case *pganalyze.SubLink:
+			return false
+		case *pganalyze.ColumnRef:
+			fields := msg.GetFields()
+			if name := fields[len(fields)-1].GetString_().GetSval(); column == "" && f.sensitive(name) {
+				column = name
+			}
+		}
+		return column == ""
+	})
+	return column
+}
+
+// insert redacts the values inserted into sensitive columns by VALUES lists
+// or the target list of a SELECT.
+func (f *finder) insert(s *pganalyze.InsertStmt) {
+	f.insertSelect(s.GetCols(), s.GetSelectStmt().GetSelectStmt())
+}
+
+// insertSelect redacts the values sel inserts into the columns cols. Each
+// branch of a set operation inserts into the columns, as does a subquery whose
+// columns are all selected.
+func (f *finder) insertSelect(cols []*pganalyze.Node, sel *pganalyze.SelectStmt) {
+	if sel == nil {
+		return
+	}
+	if sel.GetOp() != pganalyze.SetOperation_SETOP_NONE {
+		f.insertSelect(cols, sel.GetLarg())
+		f.insertSelect(cols, sel.GetRarg())
+		return
+	}
+	for _, row := range sel.GetValuesLists() {
+		f.values(cols, row.GetList().GetItems())
+	}
+	if sub := starSubquery(sel); sub != nil {
+		f.insertSelect(cols, sub)
+		return
+	}
+	var values []*pganalyze.Node
+	for _, t := range sel.GetTargetList() {
+		values = append(values, t.GetResTarget().GetVal())
+	}
+	f.values(cols, values)
+}
+
+// starSubquery returns the subquery of SELECT * FROM (subquery), or nil.
+func starSubquery(sel *pganalyze.SelectStmt) *pganalyze.SelectStmt {
+	targets, from := sel.GetTargetList(), sel.GetFromClause()
+	if len(targets) != 1 || len(from) != 1 {
+		return nil
+	}
+	fields := targets[0].GetResTarget().GetVal().GetColumnRef().GetFields()
+	if len(fields) != 1 || fields[0].GetAStar() == nil {
+		return nil
+	}
+	return from[0].GetRangeSubselect().GetSubquery().GetSelectStmt()
+}
+
+// values redacts the values assigned to sensitive columns by position.
+func (f *finder) values(cols, values []*pganalyze.Node) {
+	for i, c := range cols {
+		if name := c.GetResTarget().GetName(); i < len(values) && f.sensitive(name) {
+			f.redact(values[i], name)
+		}
+	}
+}
+
+// assign redacts the values assigned to sensitive columns by SET clauses.
+func (f *finder) assign(targets []*pganalyze.Node) {
+	for _, t := range targets {
+		rt := t.GetResTarget()
+		if !f.sensitive(rt.GetName()) {
+			continue
+		}
+		val := rt.GetVal()
+		// SET (a, b) = (1, 2) assigns each column an element of the row.
+		if mar := val.GetMultiAssignRef(); mar != nil {
+			args := mar.GetSource().GetRowExpr().GetArgs()
+			if i := int(mar.GetColno()) - 1; i >= 0 && i < len(args) {
+				val = args[i]
+			} else {
+				val = mar.GetSource()
+			}
+		}
+		f.redact(val, rt.GetName())
+	}
+}
+
+// redact records the constants of n outside of subqueries and type modifiers
+// as used with column.
+func (f *finder) redact(n *pganalyze.Node, column string) {
+	walk.Walk(n, func(msg proto.Message) bool {
+		switch msg := msg.(type) {
+		case *pganalyze.SubLink, *pganalyze.TypeName:
+			return false
+		case *pganalyze.A_Const:
+			if !msg.GetIsnull() {
+				f.consts[msg] = column
+			}
+		}
+		return true
+	})
+}
+
+var (
+	emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[A-Za-z]{2,}$`)
+	ssnPattern   = regexp.MustCompile(`^\d{3}-\d{2}-\d{4}$`)
+	cardPattern  = regexp.MustCompile(`^\d(?:[ -]?\d){12,18}$`)
+)
+
+// looksLikePII returns whether the value of c looks like an email address, a
+// social security number or a payment card number.
+func looksLikePII(c *pganalyze.A_Const) bool {
+	var s string
+	switch {
+	case c.GetSval() != nil:
+		s = strings.TrimSpace(c.GetSval().GetSval())
+	case c.GetFval() != nil:
+		// Card numbers do not fit in integers.
+		s = c.GetFval().GetFval()
+	default:
+		return false
+	}
+	if emailPattern.MatchString(s) || ssnPattern.MatchString(s) {
+		return true
+	}
+	return cardPattern.MatchString(s) && luhn(strings.NewReplacer(" ", "", "-", "").Replace(s))
+}
+
+// luhn returns whether the digits pass the Luhn checksum of card numbers.
+func luhn(digits string) bool {
+	sum := 0
+	for i := range len(digits) {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// constSpan returns the byte range of the constant at location, which is the
+// token there or, for negative numbers, the minus sign and the next token.
+func constSpan(sql string, tokens []*pganalyze.ScanToken, location int) (int, int, bool) {
+	if location < 0 {
+		return 0, 0, false
+	}
+	i := sort.Search(len(tokens), func(i int) bool { return int(tokens[i].GetStart()) >= location })
+	if i == len(tokens) || int(tokens[i].GetStart()) != location {
+		return 0, 0, false
+	}
+	if sql[location] == '-' {
+		// Scan results include comments, which the parser skips.
+		i++
+		for i < len(tokens) && isComment(tokens[i]) {
+			i++
+		}
+		if i == len(tokens) {
+			return 0, 0, false
+		}
+	}
+	// The escape character of Unicode escape strings is part of the constant.
+	if tokens[i].GetToken() == pganalyze.Token_USCONST && i+2 < len(tokens) && tokens[i+1].GetToken() == pganalyze.Token_UESCAPE {
+		i += 2
+	}
+	// Unicode escape strings are scanned with following whitespace.
+	end := len(strings.TrimRight(sql[:tokens[i].GetEnd()], " \t\n\r\f\v"))
+	return location, end, true
+}
+
+func isComment(t *pganalyze.ScanToken) bool {
+	return t.GetToken() == pganalyze.Token_SQL_COMMENT || t.GetToken() == pganalyze.Token_C_COMMENT
+}
+
+// quote returns s as a string constant.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,169 @@
+package redact_test
+
+import (
+	"errors"
+	"path"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+	"github.com/wasilibs/go-pgquery/redact"
+)
+
+var policy = redact.Policy{Columns: []string{"email", "*_token", "SSN"}}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy redact.Policy
+		sql    string
+		want   string
+	}{
+		{
+			name:   "comparisons",
+			policy: policy,
+			sql:    "SELECT * FROM users WHERE email = 'a@example.com' AND status = 'active' AND age > 30",
+			want:   "SELECT * FROM users WHERE email = 'REDACTED' AND status = 'active' AND age > 30",
+		},
+		{
+			name:   "expressions and lists",
+			policy: policy,
+			sql:    "SELECT * FROM users u WHERE lower(u.Email) IN ('a', 'b') AND id = 1",
+			want:   "SELECT * FROM users u WHERE lower(u.Email) IN ('REDACTED', 'REDACTED') AND id = 1",
+		},
+		{
+			name:   "row comparison",
+			policy: policy,
+			sql:    "SELECT * FROM users WHERE (email, status) = ('x', 'active')",
+			want:   "SELECT * FROM users WHERE (email, status) = ('REDACTED', 'active')",
+		},
+		{
+			name:   "insert values",
+			policy: policy,
+			sql:    "INSERT INTO users (id, email, api_token) VALUES (1, 'x', 'tok'), (2, 'y', upper('t2'))",
+			want:   "INSERT INTO users (id, email, api_token) VALUES (1, 'REDACTED', 'REDACTED'), (2, 'REDACTED', upper('REDACTED'))",
+		},
+		{
+			name:   "insert select",
+			policy: policy,
+			sql:    "INSERT INTO users (id, ssn) SELECT 1, -123456789",
+			want:   "INSERT INTO users (id, ssn) SELECT 1, 'REDACTED'",
+		},
+		{
+			name:   "insert set operation",
+			policy: policy,
+			sql:    "INSERT INTO users (id, email) SELECT 1, 'a' UNION SELECT 2, 'b' UNION ALL (SELECT * FROM (VALUES (3, 'c')) v INTERSECT SELECT * FROM (SELECT 4, 'd') s)",
+			want:   "INSERT INTO users (id, email) SELECT 1, 'REDACTED' UNION SELECT 2, 'REDACTED' UNION ALL (SELECT * FROM (VALUES (3, 'REDACTED')) v INTERSECT SELECT * FROM (SELECT 4, 'REDACTED') s)",
+		},
+		{
+			name:   "update",
+			policy: policy,
+			sql:    "UPDATE users SET email = 'x', status = 'active', (ssn, name) = ('1', 'n') WHERE id = 5",
+			want:   "UPDATE users SET email = 'REDACTED', status = 'active', (ssn, name) = ('REDACTED', 'n') WHERE id = 5",
+		},
+		{
+			name:   "on conflict",
+			policy: policy,
+			sql:    "INSERT INTO users (id, email) VALUES (1, 'x') ON CONFLICT (id) DO UPDATE SET email = 'y'",
+			want:   "INSERT INTO users (id, email) VALUES (1, 'REDACTED') ON CONFLICT (id) DO UPDATE SET email = 'REDACTED'",
+		},
+		{
+			name:   "merge",
+			policy: policy,
+			sql:    "MERGE INTO users u USING s ON u.id = s.id WHEN MATCHED THEN UPDATE SET email = 'm' WHEN NOT MATCHED THEN INSERT (id, email) VALUES (3, 'n')",
+			want:   "MERGE INTO users u USING s ON u.id = s.id WHEN MATCHED THEN UPDATE SET email = 'REDACTED' WHEN NOT MATCHED THEN INSERT (id, email) VALUES (3, 'REDACTED')",
+		},
+		{
+			name:   "formatting kept",
+			policy: policy,
+			sql:    "SELECT * FROM t\nWHERE email = 'x' /* c */ -- d\n  AND refresh_token = U&'d!0061t' UESCAPE '!'",
+			want:   "SELECT * FROM t\nWHERE email = 'REDACTED' /* c */ -- d\n  AND refresh_token = 'REDACTED'",
+		},
+		{
+			name:   "pii",
+			policy: redact.Policy{DetectPII: true},
+			sql:    "SELECT 'john@example.com', '123-45-6789', 4111111111111111, '4111 1111 1111 1112', 'hello', 42",
+			want:   "SELECT 'REDACTED', 'REDACTED', 'REDACTED', '4111 1111 1111 1112', 'hello', 42",
+		},
+		{
+			name:   "pii not detected",
+			policy: policy,
+			sql:    "SELECT 'john@example.com'",
+			want:   "SELECT 'john@example.com'",
+		},
+		{
+			name:   "mask",
+			policy: redact.Policy{Columns: []string{"email"}, Mask: "it's hidden"},
+			sql:    "SELECT * FROM users WHERE email = 'x'",
+			want:   "SELECT * FROM users WHERE email = 'it''s hidden'",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.policy.Redact(tc.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("expected %s\nactual %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	p := policy
+	p.DetectPII = true
+	got, err := p.Find("SELECT * FROM users WHERE email = 'x' AND note = 'a@example.com' AND n = - 5 AND ssn = - 5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []redact.Redaction{
+		{Start: 34, End: 37, Text: "'x'", Column: "email"},
+		{Start: 49, End: 64, Text: "'a@example.com'"},
+		{Start: 87, End: 90, Text: "- 5", Column: "ssn"},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.EquateEmpty()); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestRedactTree(t *testing.T) {
+	tree, err := pg_query.Parse("SELECT * FROM users WHERE email = 'x' AND id = 1; UPDATE users SET reset_token = NULL, ssn = 123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	redacted, err := policy.RedactTree(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := pg_query.Deparse(redacted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "SELECT * FROM users WHERE email = 'REDACTED' AND id = 1; UPDATE users SET reset_token = NULL, ssn = 'REDACTED'"
+	if got != want {
+		t.Errorf("expected %s\nactual %s", want, got)
+	}
+
+	orig, err := pg_query.Deparse(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "SELECT * FROM users WHERE email = 'x' AND id = 1; UPDATE users SET reset_token = NULL, ssn = 123"; orig != want {
+		t.Errorf("tree was modified: %s", orig)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	p := redact.Policy{Columns: []string{"[email"}}
+	if _, err := p.Redact("SELECT 1"); !errors.Is(err, path.ErrBadPattern) {
+		t.Errorf("expected bad pattern error, actual %v", err)
+	}
+	if _, err := policy.Redact("SELECT FROM WHERE"); err == nil {
+		t.Error("expected parse error")
+	}
+}
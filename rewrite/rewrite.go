@@ -0,0 +1,323 @@
+// Package rewrite changes SQL statements with minimal textual edits, keeping
+// the formatting and comments of the rest of the input.
+//
+// Rewriting a parse tree and deparsing it reformats the whole statement. A
+// Rewriter instead locates the nodes of the parse tree in the input, records
+// edits replacing or inserting text at their positions, and applies them to the
+// original text, checking that the result still parses:
+//
+//	r, _ := rewrite.New("SELECT id FROM users -- active only\nWHERE active")
+//	sel := r.Tree().GetStmts()[0].GetStmt().GetSelectStmt()
+//	_ = r.RenameTable(sel.GetFromClause()[0].GetRangeVar(), "accounts")
+//	_ = r.AddWhere(sel, "tenant_id = $1")
+//	sql, _, _ := r.Apply()
+//	// SELECT id FROM accounts -- active only
+//	// WHERE active AND tenant_id = $1
+package rewrite
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+	"google.golang.org/protobuf/proto"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+	"github.com/wasilibs/go-pgquery/analysis"
+)
+
+var (
+	// ErrNoPosition is returned for nodes whose position in the input is
+	// unknown, such as nodes not from the tree of the Rewriter.
+	ErrNoPosition = errors.New("rewrite: node has no position in the input")
+	// ErrOverlappingEdits is returned by Apply if edits replace overlapping
+	// text, or insert text within text replaced by another edit.
+	ErrOverlappingEdits = errors.New("rewrite: overlapping edits")
+	// ErrUnsupportedStatement is returned by AddWhere for statements without
+	// a WHERE clause, such as set operations.
+	ErrUnsupportedStatement = errors.New("rewrite: unsupported statement")
+	// ErrInvalidPredicate is returned by AddWhere for predicates that are not
+	// a single expression.
+	ErrInvalidPredicate = errors.New("rewrite: invalid predicate")
+)
+
+// Edit is a replacement of text of the input.
+type Edit struct {
+	// Start and End are the byte offsets of the replaced text in the input.
+	// They are equal for insertions.
+	Start, End int
+	// Text is the replacement.
+	Text string
+}
+
+// Rewriter records edits of SQL and applies them.
+type Rewriter struct {
+	sql   string
+	tree  *pganalyze.ParseResult
+	spans map[proto.Message]analysis.Span
+	// tokens are the tokens of sql without comments.
+	tokens []*pganalyze.ScanToken
+	edits  []Edit
+}
+
+// New parses sql to rewrite it.
+func New(sql string) (*Rewriter, error) {
+	tree, err := pg_query.Parse(sql)
+	if err != nil {
+		return nil, fmt.Errorf("rewrite: parsing input: %w", err)
+	}
+	spans, err := analysis.Spans(sql, tree)
+	if err != nil {
+		return nil, fmt.Errorf("rewrite: locating nodes: %w", err)
+	}
+	scan, err := pg_query.Scan(sql)
+	if err != nil {
+		return nil, fmt.Errorf("rewrite: scanning input: %w", err)
+	}
+	r := &Rewriter{sql: sql, tree: tree, spans: spans}
+	for _, t := range scan.GetTokens() {
+		if t.GetToken() != pganalyze.Token_SQL_COMMENT && t.GetToken() != pganalyze.Token_C_COMMENT {
+			r.tokens = append(r.tokens, t)
+		}
+	}
+	return r, nil
+}
+
+// Tree returns the parse tree of the input, whose nodes are passed to the
+// methods of r. It must not be modified.
+func (r *Rewriter) Tree() *pganalyze.ParseResult {
+	return r.tree
+}
+
+// Span returns the position of node in the input, as computed by
+// analysis.Spans.
+func (r *Rewriter) Span(node proto.Message) (analysis.Span, bool) {
+	s, ok := r.spans[node]
+	return s, ok
+}
+
+// Edits returns the edits recorded so far, in the order they were recorded.
+func (r *Rewriter) Edits() []Edit {
+	return slices.Clone(r.edits)
+}
+
+// Replace replaces the text of node with text.
+func (r *Rewriter) Replace(node proto.Message, text string) error {
+	s, ok := r.spans[node]
+	if !ok {
+		return ErrNoPosition
+	}
+	r.edits = append(r.edits, Edit{Start: s.Start, End: s.End, Text: text})
+	return nil
+}
+
+// InsertBefore inserts text before the text of node.
+func (r *Rewriter) InsertBefore(node proto.Message, text string) error {
+	s, ok := r.spans[node]
+	if !ok {
+		return ErrNoPosition
+	}
+	r.edits = append(r.edits, Edit{Start: s.Start, End: s.Start, Text: text})
+	return nil
+}
+
+// InsertAfter inserts text after the text of node.
+func (r *Rewriter) InsertAfter(node proto.Message, text string) error {
+	s, ok := r.spans[node]
+	if !ok {
+		return ErrNoPosition
+	}
+	r.edits = append(r.edits, Edit{Start: s.End, End: s.End, Text: text})
+	return nil
+}
+
+// RenameTable replaces the name of the relation of rv with name, keeping its
+// schema and alias. name is quoted if needed.
+func (r *Rewriter) RenameTable(rv *pganalyze.RangeVar, name string) error {
+	names := 1
+	if rv.GetSchemaname() != "" {
+		names++
+	}
+	if rv.GetCatalogname() != "" {
+		names++
+	}
+	t, ok := r.nameToken(int(rv.GetLocation()), names-1)
+	if !ok {
+		return ErrNoPosition
+	}
+	r.edits = append(r.edits, Edit{Start: int(t.GetStart()), End: int(t.GetEnd()), Text: pg_query.QuoteIdentifier(name)})
+	return nil
+}
+
+// QualifyColumn qualifies the column referenced by cr with qualifier, such as
+// a table name or alias, replacing any existing qualification. qualifier is
+// quoted if needed.
+func (r *Rewriter) QualifyColumn(cr *pganalyze.ColumnRef, qualifier string) error {
+	start, ok := r.nameToken(int(cr.GetLocation()), 0)
+	if !ok {
+		return ErrNoPosition
+	}
+	last, ok := r.nameToken(int(cr.GetLocation()), len(cr.GetFields())-1)
+	if !ok {
+		return ErrNoPosition
+	}
+	r.edits = append(r.edits, Edit{Start: int(start.GetStart()), End: int(last.GetStart()), Text: pg_query.QuoteIdentifier(qualifier) + "."})
+	return nil
+}
+
+// AddWhere adds predicate, an SQL boolean expression, to the WHERE clause of
+// stmt, which must be a *pganalyze.SelectStmt without set operations, an
+// *pganalyze.UpdateStmt or a *pganalyze.DeleteStmt. An existing condition is
+// combined with predicate using AND, with parentheses added where needed.
+func (r *Rewriter) AddWhere(stmt proto.Message, predicate string) error {
+	// The WHERE clause is added after the last of the nodes preceding it.
+	var where *pganalyze.Node
+	var before []proto.Message
+	switch stmt := stmt.(type) {
+	case *pganalyze.SelectStmt:
+		if stmt.GetOp() != pganalyze.SetOperation_SETOP_NONE || len(stmt.GetValuesLists()) > 0 {
+			return ErrUnsupportedStatement
+		}
+		where = stmt.GetWhereClause()
+		before = nodes(stmt.GetTargetList(), stmt.GetFromClause())
+	case *pganalyze.UpdateStmt:
+		where = stmt.GetWhereClause()
+		before = nodes(stmt.GetTargetList(), stmt.GetFromClause())
+	case *pganalyze.DeleteStmt:
+		where = stmt.GetWhereClause()
+		before = append(nodes(stmt.GetUsingClause()), stmt.GetRelation())
+	default:
+		return ErrUnsupportedStatement
+	}
+
+	or, err := isOr(predicate)
+	if err != nil {
+		return err
+	}
+	if or {
+		predicate = "(" + predicate + ")"
+	}
+
+	if where != nil {
+		s, ok := r.spans[where]
+		if !ok {
+			return ErrNoPosition
+		}
+		if b := where.GetBoolExpr(); b != nil && b.GetBoolop() == pganalyze.BoolExprType_OR_EXPR {
+			r.edits = append(r.edits, Edit{Start: s.Start, End: s.Start, Text: "("}, Edit{Start: s.End, End: s.End, Text: ")"})
+		}
+		r.edits = append(r.edits, Edit{Start: s.End, End: s.End, Text: " AND " + predicate})
+		return nil
+	}
+
+	end := -1
+	for _, n := range before {
+		if s, ok := r.spans[n]; ok {
+			end = max(end, s.End)
+		}
+	}
+	if end < 0 {
+		return ErrNoPosition
+	}
+	r.edits = append(r.edits, Edit{Start: end, End: end, Text: " WHERE " + predicate})
+	return nil
+}
+
+// Apply applies the recorded edits to the input, returning the result and the
+// edits ordered by position. The result must parse. Edits inserting text at
+// the same position are applied in the order they were recorded.
+func (r *Rewriter) Apply() (string, []Edit, error) {
+	edits := slices.Clone(r.edits)
+	slices.SortStableFunc(edits, func(a, b Edit) int {
+		if c := cmp.Compare(a.Start, b.Start); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.End, b.End)
+	})
+
+	var b strings.Builder
+	last := 0
+	for _, e := range edits {
+		if e.Start < last {
+			return "", nil, fmt.Errorf("%w: %q at offset %d", ErrOverlappingEdits, e.Text, e.Start)
+		}
+		b.WriteString(r.sql[last:e.Start])
+		b.WriteString(e.Text)
+		last = e.End
+	}
+	b.WriteString(r.sql[last:])
+
+	out := b.String()
+	if _, err := pg_query.Parse(out); err != nil {
+		return "", nil, fmt.Errorf("rewrite: parsing result: %w", err)
+	}
+	return out, edits, nil
+}
+
+// nameToken returns the token of the name at index i of the qualified name
+// starting at location.
+func (r *Rewriter) nameToken(location, i int) (*pganalyze.ScanToken, bool) {
+	if location < 0 {
+		return nil, false
+	}
+	k := sort.Search(len(r.tokens), func(k int) bool { return int(r.tokens[k].GetStart()) >= location }) + 2*i
+	if k >= len(r.tokens) {
+		return nil, false
+	}
+	return r.tokens[k], true
+}
+
+// isOr returns whether predicate is a disjunction, which binds less tightly
+// than AND. Predicates that parse as more than an expression, such as ones
+// followed by other clauses, are invalid, as are predicates with comments,
+// which may hide the text following the predicate.
+func isOr(predicate string) (bool, error) {
+	scan, err := pg_query.Scan(predicate)
+	if err != nil {
+		return false, fmt.Errorf("rewrite: scanning predicate: %w", err)
+	}
+	for _, t := range scan.GetTokens() {
+		if t.GetToken() == pganalyze.Token_SQL_COMMENT || t.GetToken() == pganalyze.Token_C_COMMENT {
+			return false, ErrInvalidPredicate
+		}
+	}
+	tree, err := pg_query.Parse("SELECT WHERE " + predicate)
+	if err != nil {
+		return false, fmt.Errorf("rewrite: parsing predicate: %w", err)
+	}
+	stmts := tree.GetStmts()
+	if len(stmts) != 1 {
+		return false, ErrInvalidPredicate
+	}
+	sel := stmts[0].GetStmt().GetSelectStmt()
+	if sel == nil || sel.GetWhereClause() == nil {
+		return false, ErrInvalidPredicate
+	}
+	rest := proto.CloneOf(sel)
+	rest.WhereClause = nil
+	if !proto.Equal(rest, emptySelect) {
+		return false, ErrInvalidPredicate
+	}
+	b := sel.GetWhereClause().GetBoolExpr()
+	return b != nil && b.GetBoolop() == pganalyze.BoolExprType_OR_EXPR, nil
+}
+
+// emptySelect is the parse tree of SELECT without any clauses.
+var emptySelect = &pganalyze.SelectStmt{
+	LimitOption: pganalyze.LimitOption_LIMIT_OPTION_DEFAULT,
+	Op:          pganalyze.SetOperation_SETOP_NONE,
+}
+
+func nodes(lists ...[]*pganalyze.Node) []proto.Message {
+	var res []proto.Message
+	for _, l := range lists {
+		for _, n := range l {
+			res = append(res, n)
+		}
+	}
+	return res
+}
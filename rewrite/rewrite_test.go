@@ -0,0 +1,191 @@
+package rewrite_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+
+	"github.com/wasilibs/go-pgquery/rewrite"
+)
+
+func selectStmt(r *rewrite.Rewriter, i int) *pganalyze.SelectStmt {
+	return r.Tree().GetStmts()[i].GetStmt().GetSelectStmt()
+}
+
+func TestRewrite(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		edit func(r *rewrite.Rewriter) error
+		want string
+	}{
+		{
+			name: "rename table",
+			sql:  "SELECT * FROM public.users u /* keep */ JOIN users ON true",
+			edit: func(r *rewrite.Rewriter) error {
+				join := selectStmt(r, 0).GetFromClause()[0].GetJoinExpr()
+				if err := r.RenameTable(join.GetLarg().GetRangeVar(), "accounts"); err != nil {
+					return err
+				}
+				return r.RenameTable(join.GetRarg().GetRangeVar(), "Accounts")
+			},
+			want: `SELECT * FROM public.accounts u /* keep */ JOIN "Accounts" ON true`,
+		},
+		{
+			name: "qualify column",
+			sql:  "SELECT id, x.name, * FROM users",
+			edit: func(r *rewrite.Rewriter) error {
+				for _, target := range selectStmt(r, 0).GetTargetList() {
+					if err := r.QualifyColumn(target.GetResTarget().GetVal().GetColumnRef(), "user"); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			want: `SELECT "user".id, "user".name, "user".* FROM users`,
+		},
+		{
+			name: "add where",
+			sql:  "SELECT id FROM users -- active only\nWHERE active",
+			edit: func(r *rewrite.Rewriter) error {
+				return r.AddWhere(selectStmt(r, 0), "tenant_id = $1")
+			},
+			want: "SELECT id FROM users -- active only\nWHERE active AND tenant_id = $1",
+		},
+		{
+			name: "add where to disjunction",
+			sql:  "SELECT id FROM users WHERE a OR b ORDER BY id",
+			edit: func(r *rewrite.Rewriter) error {
+				return r.AddWhere(selectStmt(r, 0), "c OR d")
+			},
+			want: "SELECT id FROM users WHERE (a OR b) AND (c OR d) ORDER BY id",
+		},
+		{
+			name: "add where clause",
+			sql:  "SELECT id FROM users u, orders o GROUP BY id; UPDATE t SET a = 1; DELETE FROM t USING u",
+			edit: func(r *rewrite.Rewriter) error {
+				if err := r.AddWhere(selectStmt(r, 0), "u.id = o.user_id"); err != nil {
+					return err
+				}
+				if err := r.AddWhere(r.Tree().GetStmts()[1].GetStmt().GetUpdateStmt(), "b"); err != nil {
+					return err
+				}
+				return r.AddWhere(r.Tree().GetStmts()[2].GetStmt().GetDeleteStmt(), "t.id = u.id")
+			},
+			want: "SELECT id FROM users u, orders o WHERE u.id = o.user_id GROUP BY id; UPDATE t SET a = 1 WHERE b; DELETE FROM t USING u WHERE t.id = u.id",
+		},
+		{
+			name: "replace and insert",
+			sql:  "SELECT f(a)  ,  b FROM t",
+			edit: func(r *rewrite.Rewriter) error {
+				targets := selectStmt(r, 0).GetTargetList()
+				if err := r.Replace(targets[0].GetResTarget().GetVal(), "g(a, 1)"); err != nil {
+					return err
+				}
+				if err := r.InsertBefore(targets[1], "c, "); err != nil {
+					return err
+				}
+				return r.InsertAfter(targets[1], " AS b2")
+			},
+			want: "SELECT g(a, 1)  ,  c, b AS b2 FROM t",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := rewrite.New(tc.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tc.edit(r); err != nil {
+				t.Fatal(err)
+			}
+			got, _, err := r.Apply()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestApplyEdits(t *testing.T) {
+	r, err := rewrite.New("SELECT a FROM t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sel := selectStmt(r, 0)
+	if err := r.AddWhere(sel, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RenameTable(sel.GetFromClause()[0].GetRangeVar(), "u"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, edits, err := r.Apply()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []rewrite.Edit{
+		{Start: 14, End: 15, Text: "u"},
+		{Start: 15, End: 15, Text: " WHERE b"},
+	}
+	if diff := cmp.Diff(want, edits); diff != "" {
+		t.Errorf("edits mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRewriteErrors(t *testing.T) {
+	r, err := rewrite.New("SELECT a FROM t UNION SELECT b FROM u")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sel := selectStmt(r, 0)
+
+	if err := r.AddWhere(sel, "true"); !errors.Is(err, rewrite.ErrUnsupportedStatement) {
+		t.Errorf("AddWhere to union: unexpected error %v", err)
+	}
+	for _, predicate := range []string{
+		"true; DROP TABLE t",
+		"true UNION SELECT secret FROM s",
+		"true LIMIT 1",
+		"c FOR UPDATE",
+		"true ORDER BY 1",
+		"true GROUP BY a",
+		"true WINDOW w AS ()",
+		"x = 1 -- hi",
+		"x = 1 /* hi */",
+	} {
+		if err := r.AddWhere(sel.GetLarg(), predicate); !errors.Is(err, rewrite.ErrInvalidPredicate) {
+			t.Errorf("AddWhere(%q): unexpected error %v", predicate, err)
+		}
+	}
+	if err := r.Replace(&pganalyze.ColumnRef{}, "x"); !errors.Is(err, rewrite.ErrNoPosition) {
+		t.Errorf("Replace of other node: unexpected error %v", err)
+	}
+
+	larg := sel.GetLarg().GetTargetList()[0].GetResTarget()
+	if err := r.Replace(larg, "x"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Replace(larg.GetVal(), "y"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := r.Apply(); !errors.Is(err, rewrite.ErrOverlappingEdits) {
+		t.Errorf("Apply overlapping: unexpected error %v", err)
+	}
+
+	r, err = rewrite.New("SELECT a FROM t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.InsertAfter(selectStmt(r, 0).GetTargetList()[0], " FROM"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := r.Apply(); err == nil {
+		t.Error("Apply with invalid result: expected error")
+	}
+}
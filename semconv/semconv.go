@@ -0,0 +1,223 @@
+// Package semconv derives the attributes of the OpenTelemetry database
+// semantic conventions from SQL.
+//
+// The query text is sanitized by normalizing it, replacing constants with
+// parameter references, and the operation and collection names and query
+// summary are derived from the parse tree. These are low-cardinality values
+// suitable for span names and metric attributes.
+//
+// Analyzing a query requires parsing it, so a Cache should be used to derive
+// the attributes of queries executed repeatedly.
+package semconv
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+	"go.opentelemetry.io/otel/attribute"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+	"github.com/wasilibs/go-pgquery/analysis"
+	"github.com/wasilibs/go-pgquery/semconv/internal/lru"
+)
+
+// Attribute keys of the database semantic conventions.
+const (
+	DBSystemNameKey     = attribute.Key("db.system.name")
+	DBOperationNameKey  = attribute.Key("db.operation.name")
+	DBCollectionNameKey = attribute.Key("db.collection.name")
+	DBQueryTextKey      = attribute.Key("db.query.text")
+	DBQuerySummaryKey   = attribute.Key("db.query.summary")
+)
+
+// maxSummaryLen is the maximum length of a query summary recommended by the
+// semantic conventions.
+const maxSummaryLen = 255
+
+// Attributes are the semantic convention attributes of a query.
+type Attributes struct {
+	// OperationName is the SQL command of the query, such as "SELECT". For
+	// queries with multiple statements, it is "BATCH" followed by the command
+	// if all statements have the same one, and empty otherwise.
+	OperationName string
+	// CollectionName is the possibly schema-qualified name of the only
+	// relation the query references, or empty if it references none or
+	// several.
+	CollectionName string
+	// QueryText is the query with constants replaced by parameter references.
+	QueryText string
+	// QuerySummary is the command of each statement followed by the relations
+	// it references, such as "SELECT users orders", truncated to 255
+	// characters.
+	QuerySummary string
+}
+
+// KeyValues returns the attributes as OpenTelemetry attributes, including
+// db.system.name and omitting empty values.
+func (a Attributes) KeyValues() []attribute.KeyValue {
+	kvs := []attribute.KeyValue{DBSystemNameKey.String("postgresql")}
+	for _, kv := range []attribute.KeyValue{
+		DBOperationNameKey.String(a.OperationName),
+		DBCollectionNameKey.String(a.CollectionName),
+		DBQueryTextKey.String(a.QueryText),
+		DBQuerySummaryKey.String(a.QuerySummary),
+	} {
+		if kv.Value.AsString() != "" {
+			kvs = append(kvs, kv)
+		}
+	}
+	return kvs
+}
+
+// Analyze returns the attributes of sql, or an error if it does not parse.
+func Analyze(sql string) (Attributes, error) {
+	tree, err := pg_query.Parse(sql)
+	if err != nil {
+		return Attributes{}, fmt.Errorf("semconv: parsing query: %w", err)
+	}
+	normalized, err := pg_query.Normalize(sql)
+	if err != nil {
+		return Attributes{}, fmt.Errorf("semconv: normalizing query: %w", err)
+	}
+
+	stmts := tree.GetStmts()
+	ops := make([]string, len(stmts))
+	var scan *pganalyze.ScanResult
+	for i, s := range stmts {
+		ops[i] = dmlOperation(s.GetStmt())
+		if ops[i] != "" {
+			continue
+		}
+		if scan == nil {
+			if scan, err = pg_query.Scan(sql); err != nil {
+				return Attributes{}, fmt.Errorf("semconv: scanning query: %w", err)
+			}
+		}
+		ops[i] = leadingKeyword(sql, scan, s.GetStmtLocation())
+	}
+
+	tables := make([][]string, len(stmts))
+	var collections []string
+	refs, _ := analysis.ExtractReferences(tree)
+	for _, ref := range refs {
+		name := ref.Relation.String()
+		if !slices.Contains(tables[ref.Stmt], name) {
+			tables[ref.Stmt] = append(tables[ref.Stmt], name)
+		}
+		if !slices.Contains(collections, name) {
+			collections = append(collections, name)
+		}
+	}
+
+	a := Attributes{QueryText: normalized}
+	if len(collections) == 1 {
+		a.CollectionName = collections[0]
+	}
+	switch {
+	case len(ops) == 1:
+		a.OperationName = ops[0]
+	case len(ops) > 1 && allEqual(ops):
+		a.OperationName = "BATCH " + ops[0]
+	}
+	var words []string
+	for i, op := range ops {
+		words = append(words, op)
+		words = append(words, tables[i]...)
+	}
+	a.QuerySummary = summary(words)
+	return a, nil
+}
+
+// dmlOperation returns the command of DML statements, which may not be the
+// leading keyword when they have a WITH clause.
+func dmlOperation(n *pganalyze.Node) string {
+	switch n.GetNode().(type) {
+	case *pganalyze.Node_SelectStmt:
+		return "SELECT"
+	case *pganalyze.Node_InsertStmt:
+		return "INSERT"
+	case *pganalyze.Node_UpdateStmt:
+		return "UPDATE"
+	case *pganalyze.Node_DeleteStmt:
+		return "DELETE"
+	case *pganalyze.Node_MergeStmt:
+		return "MERGE"
+	}
+	return ""
+}
+
+// leadingKeyword returns the first keyword of the statement starting at
+// location, uppercased.
+func leadingKeyword(sql string, scan *pganalyze.ScanResult, location int32) string {
+	for _, t := range scan.GetTokens() {
+		if t.GetStart() < location || t.GetToken() == pganalyze.Token_SQL_COMMENT || t.GetToken() == pganalyze.Token_C_COMMENT {
+			continue
+		}
+		if t.GetKeywordKind() == pganalyze.KeywordKind_NO_KEYWORD {
+			return ""
+		}
+		return strings.ToUpper(sql[t.GetStart():t.GetEnd()])
+	}
+	return ""
+}
+
+// summary joins words with spaces, dropping the words that would exceed the
+// maximum summary length.
+func summary(words []string) string {
+	var sb strings.Builder
+	for _, w := range words {
+		if w == "" {
+			continue
+		}
+		n := len(w)
+		if sb.Len() > 0 {
+			n++
+		}
+		if sb.Len()+n > maxSummaryLen {
+			break
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(w)
+	}
+	return sb.String()
+}
+
+func allEqual(ops []string) bool {
+	for _, op := range ops[1:] {
+		if op != ops[0] {
+			return false
+		}
+	}
+	return ops[0] != ""
+}
+
+// Cache caches the attributes of queries by SQL text. It is safe for
+// concurrent use.
+type Cache struct {
+	cache *lru.Cache[string, result]
+}
+
+type result struct {
+	attrs Attributes
+	err   error
+}
+
+// NewCache returns a cache holding the attributes of at most size queries.
+func NewCache(size int) *Cache {
+	return &Cache{cache: lru.New[string, result](size)}
+}
+
+// Analyze returns the attributes of sql like the package-level Analyze,
+// returning the cached result if sql was analyzed before.
+func (c *Cache) Analyze(sql string) (Attributes, error) {
+	r, ok := c.cache.Get(sql)
+	if !ok {
+		r.attrs, r.err = Analyze(sql)
+		c.cache.Add(sql, r)
+	}
+	return r.attrs, r.err
+}
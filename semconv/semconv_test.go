@@ -0,0 +1,170 @@
+package semconv_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/wasilibs/go-pgquery/semconv"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want semconv.Attributes
+	}{
+		{
+			name: "select",
+			sql:  "SELECT name FROM users WHERE id = 42",
+			want: semconv.Attributes{
+				OperationName:  "SELECT",
+				CollectionName: "users",
+				QueryText:      "SELECT name FROM users WHERE id = $1",
+				QuerySummary:   "SELECT users",
+			},
+		},
+		{
+			name: "join",
+			sql:  "SELECT u.name, o.total FROM app.users u JOIN orders o ON o.user_id = u.id WHERE o.total > 100",
+			want: semconv.Attributes{
+				OperationName: "SELECT",
+				QueryText:     "SELECT u.name, o.total FROM app.users u JOIN orders o ON o.user_id = u.id WHERE o.total > $1",
+				QuerySummary:  "SELECT app.users orders",
+			},
+		},
+		{
+			name: "cte",
+			sql:  "WITH recent AS (SELECT id FROM orders WHERE at > now() - interval '1 day') DELETE FROM orders WHERE id IN (SELECT id FROM recent)",
+			want: semconv.Attributes{
+				OperationName:  "DELETE",
+				CollectionName: "orders",
+				QueryText:      "WITH recent AS (SELECT id FROM orders WHERE at > now() - interval $1) DELETE FROM orders WHERE id IN (SELECT id FROM recent)",
+				QuerySummary:   "DELETE orders",
+			},
+		},
+		{
+			name: "insert select",
+			sql:  "INSERT INTO archive SELECT * FROM events WHERE at < '2024-01-01'",
+			want: semconv.Attributes{
+				OperationName: "INSERT",
+				QueryText:     "INSERT INTO archive SELECT * FROM events WHERE at < $1",
+				QuerySummary:  "INSERT archive events",
+			},
+		},
+		{
+			name: "utility",
+			sql:  "/* migration */ CREATE TABLE t (id int DEFAULT 0)",
+			want: semconv.Attributes{
+				OperationName:  "CREATE",
+				CollectionName: "t",
+				QueryText:      "/* migration */ CREATE TABLE t (id int DEFAULT 0)",
+				QuerySummary:   "CREATE t",
+			},
+		},
+		{
+			name: "batch",
+			sql:  "UPDATE a SET x = 1; UPDATE b SET x = 2",
+			want: semconv.Attributes{
+				OperationName: "BATCH UPDATE",
+				QueryText:     "UPDATE a SET x = $1; UPDATE b SET x = $2",
+				QuerySummary:  "UPDATE a UPDATE b",
+			},
+		},
+		{
+			name: "mixed",
+			sql:  "BEGIN; SELECT 1; COMMIT",
+			want: semconv.Attributes{
+				QueryText:    "BEGIN; SELECT $1; COMMIT",
+				QuerySummary: "BEGIN SELECT COMMIT",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := semconv.Analyze(tc.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected attributes (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAnalyzeSummaryLength(t *testing.T) {
+	tables := make([]string, 50)
+	for i := range tables {
+		tables[i] = "table_with_a_long_name_" + string(rune('a'+i%26)) + string(rune('a'+i/26))
+	}
+	got, err := semconv.Analyze("SELECT * FROM " + strings.Join(tables, ", "))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.QuerySummary) > 255 {
+		t.Errorf("summary is %d characters long", len(got.QuerySummary))
+	}
+	if want := "SELECT " + tables[0] + " " + tables[1]; !strings.HasPrefix(got.QuerySummary, want) {
+		t.Errorf("summary %q does not start with %q", got.QuerySummary, want)
+	}
+}
+
+func TestAnalyzeError(t *testing.T) {
+	c := semconv.NewCache(10)
+	for range 2 {
+		if _, err := c.Analyze("SELECT FROM WHERE"); err == nil || !strings.HasPrefix(err.Error(), "semconv: parsing query: ") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestKeyValues(t *testing.T) {
+	got := semconv.Attributes{
+		OperationName: "SELECT",
+		QueryText:     "SELECT $1",
+		QuerySummary:  "SELECT",
+	}.KeyValues()
+	want := []attribute.KeyValue{
+		attribute.String("db.system.name", "postgresql"),
+		attribute.String("db.operation.name", "SELECT"),
+		attribute.String("db.query.text", "SELECT $1"),
+		attribute.String("db.query.summary", "SELECT"),
+	}
+	if diff := cmp.Diff(want, got, cmp.Comparer(func(a, b attribute.Value) bool { return a == b })); diff != "" {
+		t.Errorf("unexpected key values (-want +got):\n%s", diff)
+	}
+}
+
+var (
+	resultAttrs semconv.Attributes
+	resultErr   error
+)
+
+const benchmarkQuery = "SELECT u.name, o.total FROM users u JOIN orders o ON o.user_id = u.id WHERE o.created_at > $1 AND o.status = 'paid'"
+
+func BenchmarkAnalyze(b *testing.B) {
+	for range b.N {
+		resultAttrs, resultErr = semconv.Analyze(benchmarkQuery)
+		if resultErr != nil {
+			b.Fatal(resultErr)
+		}
+	}
+}
+
+func BenchmarkCacheAnalyze(b *testing.B) {
+	c := semconv.NewCache(1000)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			attrs, err := c.Analyze(benchmarkQuery)
+			if err != nil {
+				b.Error(err)
+				return
+			}
+			_ = attrs.KeyValues()
+		}
+	})
+}
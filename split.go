@@ -0,0 +1,63 @@
+package pg_query //nolint:revive // Keep package name aligned with existing public API.
+
+import (
+	"strings"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+)
+
+// SplitWithScanner - Splits the given SQL text into statements at semicolons using only the scanner.
+// This also works for input that does not parse, but does not handle semicolons within function
+// bodies written with BEGIN ATOMIC. Statements without any tokens are omitted.
+func SplitWithScanner(input string, trimSpace bool) (result []string, err error) {
+	scan, err := Scan(input)
+	if err != nil {
+		return
+	}
+
+	start := 0
+	hasTokens := false
+	for _, t := range scan.GetTokens() {
+		switch t.GetToken() {
+		case pganalyze.Token_SQL_COMMENT, pganalyze.Token_C_COMMENT:
+		case pganalyze.Token_ASCII_59:
+			if hasTokens {
+				result = append(result, splitStatement(input[start:t.GetStart()], trimSpace))
+			}
+			start = int(t.GetEnd())
+			hasTokens = false
+		default:
+			hasTokens = true
+		}
+	}
+	if hasTokens {
+		result = append(result, splitStatement(input[start:], trimSpace))
+	}
+	return
+}
+
+// SplitWithParser - Splits the given SQL text into statements using the parser. Unlike
+// SplitWithScanner, this fails for input that does not parse.
+func SplitWithParser(input string, trimSpace bool) (result []string, err error) {
+	tree, err := Parse(input)
+	if err != nil {
+		return
+	}
+
+	for _, raw := range tree.GetStmts() {
+		start := int(raw.GetStmtLocation())
+		end := len(input)
+		if raw.GetStmtLen() > 0 {
+			end = start + int(raw.GetStmtLen())
+		}
+		result = append(result, splitStatement(input[start:end], trimSpace))
+	}
+	return
+}
+
+func splitStatement(stmt string, trimSpace bool) string {
+	if trimSpace {
+		return strings.TrimSpace(stmt)
+	}
+	return stmt
+}
@@ -0,0 +1,68 @@
+package pg_query_test
+
+import (
+	"reflect"
+	"testing"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+)
+
+var splitTests = []struct {
+	input     string
+	trimSpace bool
+	scanner   []string
+	parser    []string
+}{
+	{
+		"SELECT 1; SELECT 2",
+		true,
+		[]string{"SELECT 1", "SELECT 2"},
+		[]string{"SELECT 1", "SELECT 2"},
+	},
+	{
+		"SELECT ';'; -- trailing\n;; SELECT 2;",
+		false,
+		[]string{"SELECT ';'", " SELECT 2"},
+		[]string{"SELECT ';'", " SELECT 2"},
+	},
+	{
+		"CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql;\n/* done */",
+		true,
+		[]string{"CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql"},
+		[]string{"CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql"},
+	},
+}
+
+func TestSplitWithScanner(t *testing.T) {
+	for _, test := range splitTests {
+		actual, err := pg_query.SplitWithScanner(test.input, test.trimSpace)
+
+		if err != nil {
+			t.Errorf("SplitWithScanner(%s)\nerror %s\n\n", test.input, err)
+		} else if !reflect.DeepEqual(actual, test.scanner) {
+			t.Errorf("SplitWithScanner(%s)\nexpected %q\nactual %q\n\n", test.input, test.scanner, actual)
+		}
+	}
+}
+
+func TestSplitWithParser(t *testing.T) {
+	for _, test := range splitTests {
+		actual, err := pg_query.SplitWithParser(test.input, test.trimSpace)
+
+		if err != nil {
+			t.Errorf("SplitWithParser(%s)\nerror %s\n\n", test.input, err)
+		} else if !reflect.DeepEqual(actual, test.parser) {
+			t.Errorf("SplitWithParser(%s)\nexpected %q\nactual %q\n\n", test.input, test.parser, actual)
+		}
+	}
+}
+
+func TestSplitWithParserError(t *testing.T) {
+	if _, err := pg_query.SplitWithParser("SELECT 1; SELEC 2", true); err == nil {
+		t.Error("expected error for invalid input")
+	}
+	actual, err := pg_query.SplitWithScanner("SELECT 1; SELEC 2", true)
+	if err != nil || !reflect.DeepEqual(actual, []string{"SELECT 1", "SELEC 2"}) {
+		t.Errorf("SplitWithScanner of invalid input returned %q, %v", actual, err)
+	}
+}
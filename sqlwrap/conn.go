@@ -0,0 +1,252 @@
+package sqlwrap
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+)
+
+var (
+	errNamedArgs = errors.New("sqlwrap: driver does not support named arguments")
+	errIsolation = errors.New("sqlwrap: driver does not support non-default isolation level")
+	errReadOnly  = errors.New("sqlwrap: driver does not support read-only transactions")
+)
+
+// conn wraps a connection, implementing the optional interfaces by delegating
+// to the wrapped connection or falling back as database/sql would without
+// them.
+type conn struct {
+	conn driver.Conn
+	w    *wrapper
+}
+
+func wrapConn(c driver.Conn, w *wrapper) *conn {
+	return &conn{conn: c, w: w}
+}
+
+var (
+	_ driver.Conn               = (*conn)(nil)
+	_ driver.ConnPrepareContext = (*conn)(nil)
+	_ driver.ConnBeginTx        = (*conn)(nil)
+	_ driver.ExecerContext      = (*conn)(nil)
+	_ driver.QueryerContext     = (*conn)(nil)
+	_ driver.Pinger             = (*conn)(nil)
+	_ driver.SessionResetter    = (*conn)(nil)
+	_ driver.Validator          = (*conn)(nil)
+	_ driver.NamedValueChecker  = (*conn)(nil)
+)
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	ctx, err := c.w.check(ctx, OpPrepare, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	var s driver.Stmt
+	if pc, ok := c.conn.(driver.ConnPrepareContext); ok {
+		s, err = pc.PrepareContext(ctx, query)
+	} else {
+		s, err = c.conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err //nolint:wrapcheck // driver errors are returned as is
+	}
+	ws := &stmt{stmt: s, query: query, w: c.w}
+	if cc, ok := s.(driver.ColumnConverter); ok { //nolint:staticcheck // forwarded for drivers still implementing it
+		return &converterStmt{stmt: ws, cc: cc}, nil
+	}
+	return ws, nil
+}
+
+func (c *conn) Close() error {
+	return c.conn.Close() //nolint:wrapcheck // driver errors are returned as is
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.conn.Begin() //nolint:wrapcheck,staticcheck // driver errors are returned as is, fallback for drivers without BeginTx
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if bc, ok := c.conn.(driver.ConnBeginTx); ok {
+		return bc.BeginTx(ctx, opts) //nolint:wrapcheck // driver errors are returned as is
+	}
+	// Like database/sql, refuse options the fallback cannot honor.
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) {
+		return nil, errIsolation
+	}
+	if opts.ReadOnly {
+		return nil, errReadOnly
+	}
+	return c.conn.Begin() //nolint:wrapcheck,staticcheck // driver errors are returned as is, fallback for drivers without BeginTx
+}
+
+// ExecContext runs hooks and executes the query directly if the wrapped
+// connection supports it. Otherwise, database/sql falls back to preparing a
+// statement.
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ecOK := c.conn.(driver.ExecerContext)
+	e, eOK := c.conn.(driver.Execer) //nolint:staticcheck // fallback for drivers without ExecerContext
+	if !ecOK && !eOK {
+		return nil, driver.ErrSkip
+	}
+	ctx, err := c.w.check(ctx, OpExec, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if ecOK {
+		return ec.ExecContext(ctx, query, args) //nolint:wrapcheck // driver errors are returned as is
+	}
+	values, err := namedValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return e.Exec(query, values) //nolint:wrapcheck // driver errors are returned as is
+}
+
+// QueryContext runs hooks and executes the query directly if the wrapped
+// connection supports it. Otherwise, database/sql falls back to preparing a
+// statement.
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, qcOK := c.conn.(driver.QueryerContext)
+	q, qOK := c.conn.(driver.Queryer) //nolint:staticcheck // fallback for drivers without QueryerContext
+	if !qcOK && !qOK {
+		return nil, driver.ErrSkip
+	}
+	ctx, err := c.w.check(ctx, OpQuery, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if qcOK {
+		return qc.QueryContext(ctx, query, args) //nolint:wrapcheck // driver errors are returned as is
+	}
+	values, err := namedValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return q.Query(query, values) //nolint:wrapcheck // driver errors are returned as is
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.conn.(driver.Pinger); ok {
+		return p.Ping(ctx) //nolint:wrapcheck // driver errors are returned as is
+	}
+	return nil
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if r, ok := c.conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx) //nolint:wrapcheck // driver errors are returned as is
+	}
+	return nil
+}
+
+func (c *conn) IsValid() bool {
+	if v, ok := c.conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := c.conn.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv) //nolint:wrapcheck // driver errors are returned as is
+	}
+	return driver.ErrSkip
+}
+
+// stmt wraps a prepared statement, running hooks for each execution.
+type stmt struct {
+	stmt  driver.Stmt
+	query string
+	w     *wrapper
+}
+
+var (
+	_ driver.Stmt             = (*stmt)(nil)
+	_ driver.StmtExecContext  = (*stmt)(nil)
+	_ driver.StmtQueryContext = (*stmt)(nil)
+)
+
+func (s *stmt) Close() error {
+	return s.stmt.Close() //nolint:wrapcheck // driver errors are returned as is
+}
+
+func (s *stmt) NumInput() int {
+	return s.stmt.NumInput()
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), valuesNamed(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), valuesNamed(args))
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	ctx, err := s.w.check(ctx, OpExec, s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	if ec, ok := s.stmt.(driver.StmtExecContext); ok {
+		return ec.ExecContext(ctx, args) //nolint:wrapcheck // driver errors are returned as is
+	}
+	values, err := namedValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.stmt.Exec(values) //nolint:wrapcheck,staticcheck // driver errors are returned as is, fallback for drivers without StmtExecContext
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	ctx, err := s.w.check(ctx, OpQuery, s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	if qc, ok := s.stmt.(driver.StmtQueryContext); ok {
+		return qc.QueryContext(ctx, args) //nolint:wrapcheck // driver errors are returned as is
+	}
+	values, err := namedValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.stmt.Query(values) //nolint:wrapcheck,staticcheck // driver errors are returned as is, fallback for drivers without StmtQueryContext
+}
+
+// converterStmt wraps a prepared statement implementing
+// driver.ColumnConverter. database/sql converts arguments with the column
+// converters of statements implementing it, so it is only implemented if the
+// wrapped statement does.
+type converterStmt struct {
+	*stmt
+	cc driver.ColumnConverter //nolint:staticcheck // forwarded for drivers still implementing it
+}
+
+var _ driver.ColumnConverter = (*converterStmt)(nil) //nolint:staticcheck // forwarded for drivers still implementing it
+
+func (s *converterStmt) ColumnConverter(idx int) driver.ValueConverter {
+	return s.cc.ColumnConverter(idx)
+}
+
+func namedValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errNamedArgs
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
+
+func valuesNamed(values []driver.Value) []driver.NamedValue {
+	args := make([]driver.NamedValue, len(values))
+	for i, v := range values {
+		args[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return args
+}
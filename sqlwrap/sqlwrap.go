@@ -0,0 +1,222 @@
+// Package sqlwrap wraps database/sql drivers to fingerprint, normalize and
+// validate the queries sent through them.
+//
+// Each query is fingerprinted and normalized before it is sent to the wrapped
+// driver, and passed to hooks which may reject it by returning an error. The
+// analyzed query is also added to the context passed to the wrapped driver,
+// where it can be retrieved with QueryFromContext, for example to tag traces
+// or logs with the fingerprint.
+//
+//	db := sql.OpenDB(sqlwrap.WrapConnector(connector, sqlwrap.Options{
+//		RejectInvalid: true,
+//	}))
+package sqlwrap
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+	"github.com/wasilibs/go-pgquery/internal/lru"
+)
+
+// ErrInvalidQuery is returned for queries that do not parse when
+// Options.RejectInvalid is set.
+var ErrInvalidQuery = errors.New("sqlwrap: invalid query")
+
+// Op is the operation of a query.
+type Op int
+
+const (
+	// OpExec is the execution of a statement not returning rows.
+	OpExec Op = iota
+	// OpQuery is the execution of a statement returning rows.
+	OpQuery
+	// OpPrepare is the preparation of a statement, which is later executed
+	// with OpExec or OpQuery.
+	OpPrepare
+)
+
+func (o Op) String() string {
+	switch o {
+	case OpExec:
+		return "exec"
+	case OpQuery:
+		return "query"
+	case OpPrepare:
+		return "prepare"
+	}
+	return fmt.Sprintf("Op(%d)", int(o))
+}
+
+// Query is a query sent through a wrapped driver.
+type Query struct {
+	Op  Op
+	SQL string
+	// Fingerprint is the fingerprint of SQL, or empty if it does not parse.
+	Fingerprint string
+	// Normalized is SQL with constants replaced by parameter references, or
+	// empty if it does not parse.
+	Normalized string
+	// Err is the error parsing SQL, if any.
+	Err error
+	// Args are the arguments of the execution, nil for OpPrepare.
+	Args []driver.NamedValue
+}
+
+// Hook is called before a query is sent to the wrapped driver. Returning an
+// error rejects the query, returning the error from the database/sql call.
+type Hook func(ctx context.Context, q *Query) error
+
+// Options configures a wrapped driver.
+type Options struct {
+	// Hooks are called in order for each query until one returns an error.
+	Hooks []Hook
+	// RejectInvalid rejects queries that do not parse with ErrInvalidQuery
+	// before calling hooks.
+	RejectInvalid bool
+	// CacheSize is the number of distinct SQL strings whose fingerprint and
+	// normalized form are cached, 1000 if zero.
+	CacheSize int
+}
+
+// analysis is the cached analysis of SQL.
+type analysis struct {
+	fingerprint string
+	normalized  string
+	err         error
+}
+
+// wrapper holds the state shared by the connections of a wrapped driver.
+type wrapper struct {
+	opts  Options
+	cache *lru.Cache[string, analysis]
+}
+
+func newWrapper(opts Options) *wrapper {
+	if opts.CacheSize == 0 {
+		opts.CacheSize = 1000
+	}
+	return &wrapper{opts: opts, cache: lru.New[string, analysis](opts.CacheSize)}
+}
+
+type queryKey struct{}
+
+// QueryFromContext returns the query added to the context passed to the
+// wrapped driver.
+func QueryFromContext(ctx context.Context) (*Query, bool) {
+	q, ok := ctx.Value(queryKey{}).(*Query)
+	return q, ok
+}
+
+// check analyzes the query and runs the hooks, returning the context to pass
+// to the wrapped driver.
+func (w *wrapper) check(ctx context.Context, op Op, sql string, args []driver.NamedValue) (context.Context, error) {
+	a, ok := w.cache.Get(sql)
+	if !ok {
+		a.fingerprint, a.err = pg_query.Fingerprint(sql)
+		if a.err == nil {
+			a.normalized, a.err = pg_query.Normalize(sql)
+		}
+		w.cache.Add(sql, a)
+	}
+
+	q := &Query{Op: op, SQL: sql, Fingerprint: a.fingerprint, Normalized: a.normalized, Err: a.err, Args: args}
+	if a.err != nil {
+		q.Fingerprint, q.Normalized = "", ""
+		if w.opts.RejectInvalid {
+			return ctx, fmt.Errorf("%w: %w", ErrInvalidQuery, a.err)
+		}
+	}
+	for _, hook := range w.opts.Hooks {
+		if err := hook(ctx, q); err != nil {
+			return ctx, err
+		}
+	}
+	return context.WithValue(ctx, queryKey{}, q), nil
+}
+
+// Wrap returns a driver wrapping d.
+func Wrap(d driver.Driver, opts Options) driver.Driver {
+	return &wrappedDriver{driver: d, w: newWrapper(opts)}
+}
+
+// WrapConnector returns a connector wrapping c, for use with sql.OpenDB.
+func WrapConnector(c driver.Connector, opts Options) driver.Connector {
+	return &wrappedConnector{connector: c, w: newWrapper(opts)}
+}
+
+type wrappedDriver struct {
+	driver driver.Driver
+	w      *wrapper
+}
+
+func (d *wrappedDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.driver.Open(name)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // driver errors are returned as is
+	}
+	return wrapConn(c, d.w), nil
+}
+
+func (d *wrappedDriver) OpenConnector(name string) (driver.Connector, error) {
+	if dc, ok := d.driver.(driver.DriverContext); ok {
+		c, err := dc.OpenConnector(name)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // driver errors are returned as is
+		}
+		return &wrappedConnector{connector: c, driver: d, w: d.w}, nil
+	}
+	return &wrappedConnector{connector: dsnConnector{name: name, driver: d.driver}, driver: d, w: d.w}, nil
+}
+
+// dsnConnector is a connector for drivers that do not implement
+// driver.DriverContext.
+type dsnConnector struct {
+	name   string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.name) //nolint:wrapcheck // driver errors are returned as is
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+var _ io.Closer = (*wrappedConnector)(nil)
+
+type wrappedConnector struct {
+	connector driver.Connector
+	// driver is the wrapped driver the connector was opened by, if any.
+	driver driver.Driver
+	w      *wrapper
+}
+
+func (c *wrappedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // driver errors are returned as is
+	}
+	return wrapConn(conn, c.w), nil
+}
+
+// Close closes the wrapped connector if it implements io.Closer, which
+// sql.DB.Close does for the connector it was opened with.
+func (c *wrappedConnector) Close() error {
+	if cl, ok := c.connector.(io.Closer); ok {
+		return cl.Close() //nolint:wrapcheck // driver errors are returned as is
+	}
+	return nil
+}
+
+func (c *wrappedConnector) Driver() driver.Driver {
+	if c.driver != nil {
+		return c.driver
+	}
+	return &wrappedDriver{driver: c.connector.Driver(), w: c.w}
+}
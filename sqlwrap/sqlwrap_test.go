@@ -0,0 +1,335 @@
+package sqlwrap_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/wasilibs/go-pgquery/sqlwrap"
+)
+
+// stubDriver records the queries it receives along with the fingerprint in
+// their context. Connections execute queries directly if direct is set, and
+// only through prepared statements otherwise. Prepared statements convert
+// their arguments to upper case if convert is set.
+type stubDriver struct {
+	direct  bool
+	convert bool
+
+	mu  sync.Mutex
+	log []string
+}
+
+func (d *stubDriver) record(ctx context.Context, op, query string) {
+	fp := "-"
+	if q, ok := sqlwrap.QueryFromContext(ctx); ok {
+		fp = q.Fingerprint
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.log = append(d.log, op+" "+query+" "+fp)
+}
+
+func (d *stubDriver) Open(string) (driver.Conn, error) {
+	c := &stubConn{d: d}
+	if d.direct {
+		return &directConn{c}, nil
+	}
+	return c, nil
+}
+
+func (d *stubDriver) Connect(context.Context) (driver.Conn, error) {
+	return d.Open("")
+}
+
+func (d *stubDriver) Driver() driver.Driver {
+	return d
+}
+
+func (d *stubDriver) Close() error {
+	d.record(context.Background(), "close", "")
+	return nil
+}
+
+type stubConn struct {
+	d *stubDriver
+}
+
+func (c *stubConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *stubConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	c.d.record(ctx, "prepare", query)
+	s := &stubStmt{d: c.d, query: query}
+	if c.d.convert {
+		return &convertingStmt{s}, nil
+	}
+	return s, nil
+}
+
+func (c *stubConn) Close() error {
+	return nil
+}
+
+func (c *stubConn) Begin() (driver.Tx, error) {
+	c.d.record(context.Background(), "begin", "")
+	return stubTx{}, nil
+}
+
+type stubTx struct{}
+
+func (stubTx) Commit() error {
+	return nil
+}
+
+func (stubTx) Rollback() error {
+	return nil
+}
+
+type directConn struct {
+	*stubConn
+}
+
+func (c *directConn) ExecContext(ctx context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.d.record(ctx, "exec", query)
+	return driver.RowsAffected(1), nil
+}
+
+func (c *directConn) QueryContext(ctx context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.d.record(ctx, "query", query)
+	return stubRows{}, nil
+}
+
+type stubStmt struct {
+	d     *stubDriver
+	query string
+}
+
+func (s *stubStmt) Close() error {
+	return nil
+}
+
+func (s *stubStmt) NumInput() int {
+	return -1
+}
+
+func (s *stubStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (s *stubStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (s *stubStmt) ExecContext(ctx context.Context, _ []driver.NamedValue) (driver.Result, error) {
+	s.d.record(ctx, "stmt exec", s.query)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *stubStmt) QueryContext(ctx context.Context, _ []driver.NamedValue) (driver.Rows, error) {
+	s.d.record(ctx, "stmt query", s.query)
+	return stubRows{}, nil
+}
+
+// convertingStmt converts string arguments to upper case and records them.
+type convertingStmt struct {
+	*stubStmt
+}
+
+func (s *convertingStmt) ColumnConverter(int) driver.ValueConverter {
+	return upperConverter{}
+}
+
+func (s *convertingStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	for _, a := range args {
+		s.d.record(ctx, "arg", a.Value.(string))
+	}
+	return s.stubStmt.ExecContext(ctx, args)
+}
+
+type upperConverter struct{}
+
+func (upperConverter) ConvertValue(v any) (driver.Value, error) {
+	return strings.ToUpper(v.(string)), nil
+}
+
+type stubRows struct{}
+
+func (stubRows) Columns() []string {
+	return []string{"x"}
+}
+
+func (stubRows) Close() error {
+	return nil
+}
+
+func (stubRows) Next([]driver.Value) error {
+	return io.EOF
+}
+
+var errForbidden = errors.New("forbidden")
+
+type seen struct {
+	op         string
+	normalized string
+	args       int
+}
+
+func newDB(t *testing.T, d *stubDriver, opts sqlwrap.Options, seenQueries *[]seen) *sql.DB {
+	t.Helper()
+	opts.Hooks = append(opts.Hooks, func(_ context.Context, q *sqlwrap.Query) error {
+		*seenQueries = append(*seenQueries, seen{op: q.Op.String(), normalized: q.Normalized, args: len(q.Args)})
+		if strings.Contains(q.SQL, "secrets") {
+			return errForbidden
+		}
+		return nil
+	})
+	db := sql.OpenDB(sqlwrap.WrapConnector(d, opts))
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func TestDirect(t *testing.T) {
+	ctx := context.Background()
+	d := &stubDriver{direct: true}
+	var queries []seen
+	db := newDB(t, d, sqlwrap.Options{RejectInvalid: true}, &queries)
+
+	if _, err := db.ExecContext(ctx, "UPDATE t SET a = 1 WHERE b = $1", 5); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.QueryContext(ctx, "SELECT a FROM t WHERE b = 'x'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		t.Error("unexpected row")
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.ExecContext(ctx, "DELETE FROM secrets"); !errors.Is(err, errForbidden) {
+		t.Errorf("unexpected error for rejected query: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, "DELETE FROM WHERE"); !errors.Is(err, sqlwrap.ErrInvalidQuery) {
+		t.Errorf("unexpected error for invalid query: %v", err)
+	}
+
+	wantLog := []string{
+		"exec UPDATE t SET a = 1 WHERE b = $1 e08ea12a2f2eed31",
+		"query SELECT a FROM t WHERE b = 'x' f5ccd2dc9a65d441",
+	}
+	if diff := cmp.Diff(wantLog, d.log); diff != "" {
+		t.Errorf("unexpected driver log (-want +got):\n%s", diff)
+	}
+	wantSeen := []seen{
+		{op: "exec", normalized: "UPDATE t SET a = $2 WHERE b = $1", args: 1},
+		{op: "query", normalized: "SELECT a FROM t WHERE b = $1"},
+		{op: "exec", normalized: "DELETE FROM secrets"},
+	}
+	if diff := cmp.Diff(wantSeen, queries, cmp.AllowUnexported(seen{})); diff != "" {
+		t.Errorf("unexpected queries seen by hooks (-want +got):\n%s", diff)
+	}
+}
+
+func TestPrepared(t *testing.T) {
+	var queries []seen
+	d := &stubDriver{}
+	sql.Register("sqlwrap-stub", sqlwrap.Wrap(d, sqlwrap.Options{
+		Hooks: []sqlwrap.Hook{func(_ context.Context, q *sqlwrap.Query) error {
+			queries = append(queries, seen{op: q.Op.String(), normalized: q.Normalized, args: len(q.Args)})
+			return nil
+		}},
+	}))
+	db, err := sql.Open("sqlwrap-stub", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// Without direct execution, database/sql prepares a statement.
+	if _, err := db.Exec("INSERT INTO t VALUES ($1, 2)", 1); err != nil {
+		t.Fatal(err)
+	}
+	// Invalid queries are passed on without RejectInvalid.
+	if _, err := db.Exec("INSERT INTO"); err != nil {
+		t.Fatal(err)
+	}
+
+	wantLog := []string{
+		"prepare INSERT INTO t VALUES ($1, 2) 7e11840ee96d5416",
+		"stmt exec INSERT INTO t VALUES ($1, 2) 7e11840ee96d5416",
+		"prepare INSERT INTO ",
+		"stmt exec INSERT INTO ",
+	}
+	if diff := cmp.Diff(wantLog, d.log); diff != "" {
+		t.Errorf("unexpected driver log (-want +got):\n%s", diff)
+	}
+	wantSeen := []seen{
+		{op: "prepare", normalized: "INSERT INTO t VALUES ($1, $2)"},
+		{op: "exec", normalized: "INSERT INTO t VALUES ($1, $2)", args: 1},
+		{op: "prepare"},
+		{op: "exec"},
+	}
+	if diff := cmp.Diff(wantSeen, queries, cmp.AllowUnexported(seen{})); diff != "" {
+		t.Errorf("unexpected queries seen by hooks (-want +got):\n%s", diff)
+	}
+}
+
+func TestBeginTx(t *testing.T) {
+	ctx := context.Background()
+	d := &stubDriver{}
+	db := sql.OpenDB(sqlwrap.WrapConnector(d, sqlwrap.Options{}))
+	defer db.Close()
+
+	// Without BeginTx, options other than the defaults cannot be honored.
+	if _, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable}); err == nil {
+		t.Error("expected error for isolation level")
+	}
+	if _, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true}); err == nil {
+		t.Error("expected error for read-only transaction")
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{"begin  -"}, d.log); diff != "" {
+		t.Errorf("unexpected driver log (-want +got):\n%s", diff)
+	}
+}
+
+func TestForwarded(t *testing.T) {
+	ctx := context.Background()
+	d := &stubDriver{convert: true}
+	db := sql.OpenDB(sqlwrap.WrapConnector(d, sqlwrap.Options{}))
+
+	if _, err := db.ExecContext(ctx, "UPDATE t SET a = $1", "x"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"prepare UPDATE t SET a = $1 f88d91914ea4c50b",
+		"arg X f88d91914ea4c50b",
+		"stmt exec UPDATE t SET a = $1 f88d91914ea4c50b",
+		"close  -",
+	}
+	if diff := cmp.Diff(want, d.log); diff != "" {
+		t.Errorf("unexpected driver log (-want +got):\n%s", diff)
+	}
+}
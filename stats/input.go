@@ -0,0 +1,242 @@
+package stats
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrUnknownFormat is returned when reading an unknown input format.
+	ErrUnknownFormat = errors.New("stats: unknown input format")
+	// ErrMissingColumn is returned when a pg_stat_statements export does not
+	// have a query column.
+	ErrMissingColumn = errors.New("stats: missing query column")
+)
+
+// Format is an input format.
+type Format string
+
+const (
+	// FormatAuto detects the format from the content of the input.
+	FormatAuto Format = ""
+	// FormatStatements is a CSV export of pg_stat_statements with a header
+	// row, such as written by COPY ... TO ... WITH (FORMAT csv, HEADER). The
+	// query column is required, and the queryid, calls, total_exec_time,
+	// total_plan_time and total_time columns are used if present.
+	FormatStatements Format = "statements"
+	// FormatCSVLog is a server log written with log_destination csvlog.
+	FormatCSVLog Format = "csvlog"
+	// FormatJSONLog is a server log written with log_destination jsonlog.
+	FormatJSONLog Format = "jsonlog"
+)
+
+// csvlog columns, which are not named in the file.
+const (
+	csvlogSession = 5
+	csvlogLine    = 6
+	csvlogMessage = 13
+	csvlogQueryID = 25
+)
+
+var (
+	durationMessage  = regexp.MustCompile(`(?s)^duration: ([0-9.]+) ms\s+(?:statement|execute [^:]*): (.*)$`)
+	statementMessage = regexp.MustCompile(`(?s)^(?:statement|execute [^:]*): (.*)$`)
+)
+
+// Read adds the records of an input in the format f.
+//
+// Log messages are read as written by log_min_duration_statement, with the
+// duration and statement in one message, or by log_statement, with only the
+// statement, which counts a call without time. As a log written with both
+// settings has two messages for a statement, log_statement messages are only
+// counted if the log has no messages with a duration, and then once per
+// session and line number. Other messages are ignored, including the parse
+// and bind steps of extended query protocol statements.
+func (a *Aggregator) Read(r io.Reader, f Format) error {
+	br := bufio.NewReader(r)
+	if f == FormatAuto {
+		f = detectFormat(br)
+	}
+	switch f {
+	case FormatStatements:
+		return a.readStatements(br)
+	case FormatCSVLog:
+		return a.readCSVLog(br)
+	case FormatJSONLog:
+		return a.readJSONLog(br)
+	}
+	return fmt.Errorf("%w %q", ErrUnknownFormat, f)
+}
+
+// detectFormat detects JSON logs by their first character, and
+// pg_stat_statements exports by a query column in their header row.
+func detectFormat(br *bufio.Reader) Format {
+	head, _ := br.Peek(4096)
+	head = bytes.TrimLeft(head, " \t\r\n")
+	if len(head) > 0 && head[0] == '{' {
+		return FormatJSONLog
+	}
+	line, _, _ := bytes.Cut(head, []byte("\n"))
+	for _, field := range strings.Split(strings.TrimSpace(string(line)), ",") {
+		if strings.Trim(field, `"`) == "query" {
+			return FormatStatements
+		}
+	}
+	return FormatCSVLog
+}
+
+func (a *Aggregator) readStatements(r io.Reader) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("stats: reading header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[name] = i
+	}
+	queryCol, ok := columns["query"]
+	if !ok {
+		return ErrMissingColumn
+	}
+
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("stats: reading row: %w", err)
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return row[i]
+			}
+			return ""
+		}
+		if queryCol >= len(row) {
+			continue
+		}
+		rec := Record{Query: row[queryCol]}
+		rec.QueryID, _ = strconv.ParseInt(field("queryid"), 10, 64)
+		rec.Calls, _ = strconv.ParseInt(field("calls"), 10, 64)
+		for _, col := range []string{"total_exec_time", "total_plan_time", "total_time"} {
+			t, _ := strconv.ParseFloat(field(col), 64)
+			rec.TotalTime += t
+		}
+		a.Add(rec)
+	}
+}
+
+func (a *Aggregator) readCSVLog(r io.Reader) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	lr := newLogReader(a)
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			lr.flush()
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("stats: reading log: %w", err)
+		}
+		if len(row) <= csvlogMessage {
+			continue
+		}
+		var queryID int64
+		if len(row) > csvlogQueryID {
+			queryID, _ = strconv.ParseInt(row[csvlogQueryID], 10, 64)
+		}
+		line, _ := strconv.ParseInt(row[csvlogLine], 10, 64)
+		lr.add(row[csvlogMessage], queryID, logLine{session: row[csvlogSession], line: line})
+	}
+}
+
+// jsonlogEntry is the subset of the fields of a jsonlog entry that is used.
+type jsonlogEntry struct {
+	Message   string `json:"message"`
+	QueryID   int64  `json:"query_id"`
+	SessionID string `json:"session_id"`
+	LineNum   int64  `json:"line_num"`
+}
+
+func (a *Aggregator) readJSONLog(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	lr := newLogReader(a)
+	for {
+		var entry jsonlogEntry
+		err := dec.Decode(&entry)
+		if errors.Is(err, io.EOF) {
+			lr.flush()
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("stats: reading log: %w", err)
+		}
+		lr.add(entry.Message, entry.QueryID, logLine{session: entry.SessionID, line: entry.LineNum})
+	}
+}
+
+// logLine identifies a log message by its session and line number in the
+// session.
+type logLine struct {
+	session string
+	line    int64
+}
+
+// logReader adds the statements of the messages of a log to an Aggregator.
+type logReader struct {
+	a *Aggregator
+	// durations is set once a message with a duration has been read.
+	durations bool
+	// statements aggregates the messages of log_statement until the log is
+	// known to have no messages with a duration.
+	statements *Aggregator
+	seen       map[logLine]bool
+}
+
+func newLogReader(a *Aggregator) *logReader {
+	return &logReader{a: a, statements: NewAggregator(), seen: map[logLine]bool{}}
+}
+
+func (l *logReader) add(msg string, queryID int64, line logLine) {
+	if m := durationMessage.FindStringSubmatch(msg); m != nil {
+		duration, _ := strconv.ParseFloat(m[1], 64)
+		l.a.Add(Record{Query: m[2], QueryID: queryID, Calls: 1, TotalTime: duration})
+		if !l.durations {
+			l.durations = true
+			l.statements, l.seen = nil, nil
+		}
+		return
+	}
+	if l.durations {
+		return
+	}
+	if m := statementMessage.FindStringSubmatch(msg); m != nil {
+		if line.session != "" {
+			if l.seen[line] {
+				return
+			}
+			l.seen[line] = true
+		}
+		l.statements.Add(Record{Query: m[1], QueryID: queryID, Calls: 1})
+	}
+}
+
+// flush adds the messages of log_statement if the log has no messages with a
+// duration.
+func (l *logReader) flush() {
+	if !l.durations {
+		l.a.merge(l.statements)
+	}
+}
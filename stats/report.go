@@ -0,0 +1,129 @@
+package stats
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+)
+
+// Report is the aggregated statistics of queries, ranked by total time.
+type Report struct {
+	Entries []*Entry
+	// Skipped is the number of records whose query could not be
+	// fingerprinted.
+	Skipped int
+}
+
+// Top returns the report with only the first n entries, or all if n is not
+// positive.
+func (r *Report) Top(n int) *Report {
+	if n <= 0 || n >= len(r.Entries) {
+		return r
+	}
+	return &Report{Entries: r.Entries[:n], Skipped: r.Skipped}
+}
+
+// maxQueryLen is the maximum length of queries in text reports.
+const maxQueryLen = 80
+
+// WriteText writes the report as an aligned table, with queries on a single
+// line and truncated.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RANK\tCALLS\tTOTAL (ms)\tMEAN (ms)\tQUERY IDS\tFINGERPRINT\tQUERY")
+	for i, e := range r.Entries {
+		fmt.Fprintf(tw, "%d\t%d\t%.3f\t%.3f\t%d\t%016x\t%s\n",
+			i+1, e.Calls, e.TotalTime, e.MeanTime(), len(e.QueryIDs), e.Fingerprint, shortQuery(e.Query))
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("stats: writing report: %w", err)
+	}
+	if r.Skipped > 0 {
+		if _, err := fmt.Fprintf(w, "\n%d queries could not be fingerprinted\n", r.Skipped); err != nil {
+			return fmt.Errorf("stats: writing report: %w", err)
+		}
+	}
+	return nil
+}
+
+func shortQuery(q string) string {
+	q = strings.Join(strings.Fields(q), " ")
+	if utf8.RuneCountInString(q) <= maxQueryLen {
+		return q
+	}
+	return string([]rune(q)[:maxQueryLen-1]) + "…"
+}
+
+// WriteCSV writes the report as CSV with a header row. Query IDs are
+// separated by spaces.
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"rank", "fingerprint", "calls", "total_time_ms", "mean_time_ms", "query_ids", "query"})
+	for i, e := range r.Entries {
+		ids := make([]string, len(e.QueryIDs))
+		for j, id := range e.QueryIDs {
+			ids[j] = strconv.FormatInt(id, 10)
+		}
+		_ = cw.Write([]string{
+			strconv.Itoa(i + 1),
+			fmt.Sprintf("%016x", e.Fingerprint),
+			strconv.FormatInt(e.Calls, 10),
+			strconv.FormatFloat(e.TotalTime, 'f', 3, 64),
+			strconv.FormatFloat(e.MeanTime(), 'f', 3, 64),
+			strings.Join(ids, " "),
+			e.Query,
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("stats: writing report: %w", err)
+	}
+	return nil
+}
+
+type jsonReport struct {
+	Entries []jsonEntry `json:"entries"`
+	Skipped int         `json:"skipped"`
+}
+
+type jsonEntry struct {
+	Rank        int     `json:"rank"`
+	Fingerprint string  `json:"fingerprint"`
+	Calls       int64   `json:"calls"`
+	TotalTime   float64 `json:"total_time_ms"`
+	MeanTime    float64 `json:"mean_time_ms"`
+	QueryIDs    []int64 `json:"query_ids"`
+	Query       string  `json:"query"`
+}
+
+// WriteJSON writes the report as a JSON object with the ranked entries and
+// the number of skipped records.
+func (r *Report) WriteJSON(w io.Writer) error {
+	out := jsonReport{Entries: []jsonEntry{}, Skipped: r.Skipped}
+	for i, e := range r.Entries {
+		ids := e.QueryIDs
+		if ids == nil {
+			ids = []int64{}
+		}
+		out.Entries = append(out.Entries, jsonEntry{
+			Rank:        i + 1,
+			Fingerprint: fmt.Sprintf("%016x", e.Fingerprint),
+			Calls:       e.Calls,
+			TotalTime:   e.TotalTime,
+			MeanTime:    e.MeanTime(),
+			QueryIDs:    ids,
+			Query:       e.Query,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return fmt.Errorf("stats: writing report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// Package stats aggregates query statistics from pg_stat_statements exports
+// and Postgres server logs to find the queries consuming the most time.
+//
+// Queries are grouped by their fingerprint rather than the queryid computed by
+// Postgres. Postgres assigns different queryids to queries differing only in
+// the number of values in an IN list, which the fingerprint treats as the same
+// query. Queries referring to tables of different schemas, such as a.t and b.t,
+// have different fingerprints.
+package stats
+
+import (
+	"sort"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+)
+
+// Record is the statistics of a query from an input.
+type Record struct {
+	// Query is the text of the query.
+	Query string
+	// QueryID is the queryid computed by Postgres, or zero if unknown.
+	QueryID int64
+	// Calls is the number of executions of the query.
+	Calls int64
+	// TotalTime is the total execution time of the query in milliseconds.
+	TotalTime float64
+}
+
+// Entry is the aggregated statistics of the queries with the same fingerprint.
+type Entry struct {
+	Fingerprint uint64
+	// Query is the text of the first query added with the fingerprint.
+	Query string
+	// QueryIDs are the distinct queryids of the queries, in ascending order.
+	// More than one indicates queries Postgres does not consider the same.
+	QueryIDs  []int64
+	Calls     int64
+	TotalTime float64
+}
+
+// MeanTime returns the mean execution time of the queries in milliseconds.
+func (e *Entry) MeanTime() float64 {
+	if e.Calls == 0 {
+		return 0
+	}
+	return e.TotalTime / float64(e.Calls)
+}
+
+// Aggregator aggregates records by fingerprint.
+type Aggregator struct {
+	entries map[uint64]*Entry
+	order   []*Entry
+	skipped int
+}
+
+// NewAggregator returns an empty Aggregator.
+func NewAggregator() *Aggregator {
+	return &Aggregator{entries: map[uint64]*Entry{}}
+}
+
+// Add adds a record. Records whose query cannot be fingerprinted, such as
+// queries truncated by track_activity_query_size, are counted as skipped.
+func (a *Aggregator) Add(rec Record) {
+	fp, err := pg_query.FingerprintToUInt64(rec.Query)
+	if err != nil {
+		a.skipped++
+		return
+	}
+	e := a.entry(fp, rec.Query)
+	e.Calls += rec.Calls
+	e.TotalTime += rec.TotalTime
+	e.addQueryID(rec.QueryID)
+}
+
+// merge adds the entries and skipped records of b.
+func (a *Aggregator) merge(b *Aggregator) {
+	for _, be := range b.order {
+		e := a.entry(be.Fingerprint, be.Query)
+		e.Calls += be.Calls
+		e.TotalTime += be.TotalTime
+		for _, id := range be.QueryIDs {
+			e.addQueryID(id)
+		}
+	}
+	a.skipped += b.skipped
+}
+
+// entry returns the entry of fp, adding it with query if there is none.
+func (a *Aggregator) entry(fp uint64, query string) *Entry {
+	e, ok := a.entries[fp]
+	if !ok {
+		e = &Entry{Fingerprint: fp, Query: query}
+		a.entries[fp] = e
+		a.order = append(a.order, e)
+	}
+	return e
+}
+
+func (e *Entry) addQueryID(id int64) {
+	if id == 0 {
+		return
+	}
+	i := sort.Search(len(e.QueryIDs), func(i int) bool { return e.QueryIDs[i] >= id })
+	if i == len(e.QueryIDs) || e.QueryIDs[i] != id {
+		e.QueryIDs = append(e.QueryIDs, 0)
+		copy(e.QueryIDs[i+1:], e.QueryIDs[i:])
+		e.QueryIDs[i] = id
+	}
+}
+
+// Report returns the entries ranked by total time, then by calls.
+func (a *Aggregator) Report() *Report {
+	entries := append([]*Entry(nil), a.order...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].TotalTime != entries[j].TotalTime {
+			return entries[i].TotalTime > entries[j].TotalTime
+		}
+		return entries[i].Calls > entries[j].Calls
+	})
+	return &Report{Entries: entries, Skipped: a.skipped}
+}
@@ -0,0 +1,199 @@
+package stats_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/wasilibs/go-pgquery/stats"
+)
+
+type entry struct {
+	query    string
+	queryIDs []int64
+	calls    int64
+	total    float64
+}
+
+func entries(r *stats.Report) []entry {
+	var res []entry
+	for _, e := range r.Entries {
+		res = append(res, entry{query: e.Query, queryIDs: e.QueryIDs, calls: e.Calls, total: e.TotalTime})
+	}
+	return res
+}
+
+const statementsCSV = `userid,dbid,queryid,query,calls,total_plan_time,total_exec_time
+10,5,101,"SELECT * FROM users WHERE id IN ($1, $2)",10,1.5,20
+10,5,102,"SELECT * FROM users WHERE id IN ($1, $2, $3)",5,0.5,10
+10,5,103,"UPDATE users SET name = $1 WHERE id = $2",100,0,50
+10,5,104,"SELECT * FROM users WHERE id = ",1,0,1000
+`
+
+const csvlog = `2024-01-01 00:00:00.000 UTC,"app","db",123,"[local]",abc.1,1,"SELECT",2024-01-01 00:00:00 UTC,3/1,0,LOG,00000,"duration: 12.500 ms  statement: SELECT 1",,,,,,,,,"psql","client backend",,7
+2024-01-01 00:00:01.000 UTC,"app","db",123,"[local]",abc.1,2,"SELECT",2024-01-01 00:00:00 UTC,3/2,0,LOG,00000,"duration: 7.500 ms  execute <unnamed>: SELECT 2",,,,,,,,,"psql","client backend",,7
+2024-01-01 00:00:02.000 UTC,"app","db",123,"[local]",abc.1,3,"SELECT",2024-01-01 00:00:00 UTC,3/3,0,LOG,00000,"duration: 1.000 ms  parse <unnamed>: SELECT 2",,,,,,,,,"psql","client backend",,7
+2024-01-01 00:00:03.000 UTC,"app","db",123,"[local]",abc.1,4,"idle",2024-01-01 00:00:00 UTC,,0,LOG,00000,"statement: SELECT 1",,,,,,,,,"psql","client backend",,8
+2024-01-01 00:00:04.000 UTC,"app","db",123,"[local]",abc.1,5,"idle",2024-01-01 00:00:00 UTC,,0,LOG,00000,"checkpoint starting: time",,,,,,,,,"psql","checkpointer",,0
+`
+
+const statementJSONLog = `{"timestamp":"2024-01-01 00:00:00.000 UTC","error_severity":"LOG","message":"statement: SELECT a FROM t WHERE b = 1","session_id":"abc.1","line_num":1}
+{"timestamp":"2024-01-01 00:00:00.000 UTC","error_severity":"LOG","message":"statement: SELECT a FROM t WHERE b = 1","session_id":"abc.1","line_num":1}
+{"timestamp":"2024-01-01 00:00:01.000 UTC","error_severity":"LOG","message":"statement: SELECT a FROM t WHERE b = 2","session_id":"abc.1","line_num":2}
+{"timestamp":"2024-01-01 00:00:01.000 UTC","error_severity":"LOG","message":"statement: SELECT a FROM t WHERE b = 3","session_id":"abc.2","line_num":1}
+`
+
+const jsonlog = `{"timestamp":"2024-01-01 00:00:00.000 UTC","error_severity":"LOG","message":"duration: 3.000 ms  statement: SELECT a FROM t WHERE b = 1","query_id":5}
+{"timestamp":"2024-01-01 00:00:01.000 UTC","error_severity":"LOG","message":"duration: 2.000 ms  statement: SELECT a FROM t WHERE b = 2","query_id":5}
+{"timestamp":"2024-01-01 00:00:02.000 UTC","error_severity":"LOG","message":"connection received: host=[local]"}
+`
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		format  stats.Format
+		want    []entry
+		skipped int
+	}{
+		{
+			name:   "statements",
+			input:  statementsCSV,
+			format: stats.FormatStatements,
+			want: []entry{
+				{query: "UPDATE users SET name = $1 WHERE id = $2", queryIDs: []int64{103}, calls: 100, total: 50},
+				{query: "SELECT * FROM users WHERE id IN ($1, $2)", queryIDs: []int64{101, 102}, calls: 15, total: 32},
+			},
+			skipped: 1,
+		},
+		{
+			name:  "statements detected",
+			input: statementsCSV,
+			want: []entry{
+				{query: "UPDATE users SET name = $1 WHERE id = $2", queryIDs: []int64{103}, calls: 100, total: 50},
+				{query: "SELECT * FROM users WHERE id IN ($1, $2)", queryIDs: []int64{101, 102}, calls: 15, total: 32},
+			},
+			skipped: 1,
+		},
+		{
+			name:  "csvlog",
+			input: csvlog,
+			want: []entry{
+				{query: "SELECT 1", queryIDs: []int64{7}, calls: 2, total: 20},
+			},
+		},
+		{
+			name:  "log_statement",
+			input: statementJSONLog,
+			want: []entry{
+				{query: "SELECT a FROM t WHERE b = 1", calls: 3},
+			},
+		},
+		{
+			name:  "jsonlog",
+			input: jsonlog,
+			want: []entry{
+				{query: "SELECT a FROM t WHERE b = 1", queryIDs: []int64{5}, calls: 2, total: 5},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			a := stats.NewAggregator()
+			if err := a.Read(strings.NewReader(tc.input), tc.format); err != nil {
+				t.Fatal(err)
+			}
+			r := a.Report()
+			if diff := cmp.Diff(tc.want, entries(r), cmp.AllowUnexported(entry{}), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("unexpected entries (-want +got):\n%s", diff)
+			}
+			if r.Skipped != tc.skipped {
+				t.Errorf("unexpected skipped count %d, want %d", r.Skipped, tc.skipped)
+			}
+		})
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	a := stats.NewAggregator()
+	if err := a.Read(strings.NewReader("a,b\n1,2\n"), stats.FormatStatements); !errors.Is(err, stats.ErrMissingColumn) {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := a.Read(strings.NewReader(""), stats.Format("xml")); !errors.Is(err, stats.ErrUnknownFormat) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestReport(t *testing.T) {
+	a := stats.NewAggregator()
+	a.Add(stats.Record{Query: "SELECT * FROM users WHERE id IN (1, 2)", QueryID: -3, Calls: 4, TotalTime: 10})
+	a.Add(stats.Record{Query: "SELECT * FROM users WHERE id IN (1, 2, 3)", QueryID: 2, Calls: 1, TotalTime: 2})
+	a.Add(stats.Record{Query: "SELECT\n  1", Calls: 1, TotalTime: 1})
+	a.Add(stats.Record{Query: "SELECT FROM WHERE", Calls: 1})
+	r := a.Report()
+
+	tests := []struct {
+		name  string
+		write func(*bytes.Buffer) error
+		want  string
+	}{
+		{
+			name:  "text",
+			write: func(b *bytes.Buffer) error { return r.WriteText(b) },
+			want: `RANK  CALLS  TOTAL (ms)  MEAN (ms)  QUERY IDS  FINGERPRINT       QUERY
+1     5      12.000      2.400      2          a0ead580058af585  SELECT * FROM users WHERE id IN (1, 2)
+2     1      1.000       1.000      0          50fde20626009aba  SELECT 1
+
+1 queries could not be fingerprinted
+`,
+		},
+		{
+			name:  "csv",
+			write: func(b *bytes.Buffer) error { return r.WriteCSV(b) },
+			want: `rank,fingerprint,calls,total_time_ms,mean_time_ms,query_ids,query
+1,a0ead580058af585,5,12.000,2.400,-3 2,"SELECT * FROM users WHERE id IN (1, 2)"
+2,50fde20626009aba,1,1.000,1.000,,"SELECT
+  1"
+`,
+		},
+		{
+			name:  "json top",
+			write: func(b *bytes.Buffer) error { return r.Top(1).WriteJSON(b) },
+			want: `{
+  "entries": [
+    {
+      "rank": 1,
+      "fingerprint": "a0ead580058af585",
+      "calls": 5,
+      "total_time_ms": 12,
+      "mean_time_ms": 2.4,
+      "query_ids": [
+        -3,
+        2
+      ],
+      "query": "SELECT * FROM users WHERE id IN (1, 2)"
+    }
+  ],
+  "skipped": 1
+}
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := tc.write(&b); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, b.String()); diff != "" {
+				t.Errorf("unexpected report (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,383 @@
+package pg_query //nolint:revive // Keep package name aligned with existing public API.
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/wasilibs/go-pgquery/internal/walk"
+)
+
+// Truncate returns the statements of input deparsed and shortened to at most
+// maxLen characters for display. Rather than cutting through identifiers, it
+// first collapses target lists, VALUES lists, WHERE clauses, INSERT column
+// lists and CTE queries to "...", starting with the most deeply nested and
+// longest ones, and only cuts the result and appends "..." if that is not
+// enough. Statements no longer than maxLen are returned deparsed but
+// otherwise unchanged, as are all statements if maxLen is negative.
+//
+// This is a port of the truncation of libpg_query's summary, returning the
+// same results except for identifiers and strings containing "…", which
+// libpg_query mistakes for truncated parts.
+func Truncate(input string, maxLen int) (string, error) {
+	tree, err := Parse(input)
+	if err != nil {
+		return "", err
+	}
+	output, err := Deparse(tree)
+	if err != nil {
+		return "", err
+	}
+	if maxLen < 0 || len(output) <= maxLen {
+		return output, nil
+	}
+
+	t := truncator{placeholder: placeholder(output)}
+	for _, raw := range tree.GetStmts() {
+		// The statement list is at depth 0 and its statements at depth 1.
+		t.generate(raw.ProtoReflect(), 1)
+	}
+	if t.err != nil {
+		return "", t.err
+	}
+	slices.SortStableFunc(t.candidates, func(a, b truncation) int {
+		if a.depth != b.depth {
+			return b.depth - a.depth
+		}
+		return b.length - a.length
+	})
+
+	for _, c := range t.candidates {
+		c.apply()
+		if output, err = Deparse(tree); err != nil {
+			return "", err
+		}
+		q := `"` + t.placeholder + `"`
+		output = strings.ReplaceAll(output, "SELECT "+q+" AS "+q, "SELECT "+q)
+		output = strings.ReplaceAll(output, "SELECT WHERE "+q, q)
+		output = strings.ReplaceAll(output, q, "...")
+		if len(output) <= maxLen {
+			return output, nil
+		}
+	}
+	return truncateChars(output, maxLen), nil
+}
+
+// truncation is a possible truncation of a part of a parse tree.
+type truncation struct {
+	depth  int
+	length int
+	apply  func()
+}
+
+type truncator struct {
+	candidates []truncation
+	err        error
+	// placeholder is the name of the column standing in for truncated parts.
+	placeholder string
+}
+
+func (t *truncator) add(depth int, length int, apply func()) {
+	// Truncating would not make the output shorter.
+	if length <= 3 {
+		return
+	}
+	t.candidates = append(t.candidates, truncation{depth: depth, length: length, apply: apply})
+}
+
+func (t *truncator) addWhereClause(depth int, where **pganalyze.Node) {
+	if *where == nil {
+		return
+	}
+	t.add(depth, t.len(&pganalyze.SelectStmt{WhereClause: *where}, len("SELECT WHERE ")), func() {
+		*where = t.column()
+	})
+}
+
+// len returns the length of stmt deparsed, less the length of the SQL the
+// deparser adds around the truncated part.
+func (t *truncator) len(stmt proto.Message, dummyLen int) int {
+	n := &pganalyze.Node{}
+	n.ProtoReflect().Set(nodeField(stmt.ProtoReflect().Descriptor()), protoreflect.ValueOfMessage(stmt.ProtoReflect()))
+	sql, err := Deparse(&pganalyze.ParseResult{Stmts: []*pganalyze.RawStmt{{Stmt: n}}})
+	if err != nil {
+		// Parts of a tree that deparses also deparse, so this is not expected.
+		t.err = err
+		return 0
+	}
+	return len(sql) - dummyLen
+}
+
+// generate adds the possible truncations of m and its children, tracking the
+// depth the same way as libpg_query, which walks the tree with
+// raw_expression_tree_walker.
+func (t *truncator) generate(m protoreflect.Message, depth int) {
+	if n, ok := m.Interface().(*pganalyze.Node); ok {
+		msg := walk.Unwrap(n)
+		if msg == nil {
+			return
+		}
+		m = msg.ProtoReflect()
+	}
+
+	switch msg := m.Interface().(type) {
+	case *pganalyze.RawStmt:
+		t.generate(msg.GetStmt().ProtoReflect(), depth)
+		return
+	case *pganalyze.SelectStmt:
+		if len(msg.GetTargetList()) > 0 {
+			t.add(depth, t.len(&pganalyze.SelectStmt{TargetList: msg.GetTargetList()}, len("SELECT ")), func() {
+				msg.TargetList = []*pganalyze.Node{t.target()}
+			})
+		}
+		t.addWhereClause(depth, &msg.WhereClause)
+		if len(msg.GetValuesLists()) > 0 {
+			t.add(depth, t.len(&pganalyze.SelectStmt{ValuesLists: msg.GetValuesLists()}, len("VALUES ()")), func() {
+				msg.ValuesLists = []*pganalyze.Node{{Node: &pganalyze.Node_List{List: &pganalyze.List{Items: []*pganalyze.Node{t.column()}}}}}
+			})
+		}
+	case *pganalyze.InsertStmt:
+		if len(msg.GetCols()) > 0 {
+			stmt := &pganalyze.InsertStmt{
+				Relation: &pganalyze.RangeVar{Relname: "x", Inh: true, Relpersistence: "p"},
+				Cols:     msg.GetCols(),
+				Override: pganalyze.OverridingKind_OVERRIDING_USER_VALUE,
+			}
+			t.add(depth, t.len(stmt, len("INSERT INTO x () DEFAULT VALUES")), func() {
+				msg.Cols = []*pganalyze.Node{t.target()}
+			})
+		}
+	case *pganalyze.UpdateStmt:
+		if len(msg.GetTargetList()) > 0 {
+			t.add(depth, t.updateTargetListLen(msg.GetTargetList()), func() {
+				msg.TargetList = []*pganalyze.Node{t.target()}
+			})
+		}
+		t.addWhereClause(depth, &msg.WhereClause)
+	case *pganalyze.DeleteStmt:
+		t.addWhereClause(depth, &msg.WhereClause)
+	case *pganalyze.CopyStmt:
+		t.addWhereClause(depth, &msg.WhereClause)
+	case *pganalyze.IndexStmt:
+		t.addWhereClause(depth, &msg.WhereClause)
+	case *pganalyze.RuleStmt:
+		t.addWhereClause(depth, &msg.WhereClause)
+	case *pganalyze.CommonTableExpr:
+		if q := walk.Unwrap(msg.GetCtequery()); q != nil {
+			t.add(depth, t.len(q, 0), func() {
+				msg.Ctequery = &pganalyze.Node{Node: &pganalyze.Node_SelectStmt{SelectStmt: &pganalyze.SelectStmt{WhereClause: t.column()}}}
+			})
+		}
+	case *pganalyze.InferClause:
+		t.addWhereClause(depth, &msg.WhereClause)
+	case *pganalyze.OnConflictClause:
+		if len(msg.GetTargetList()) > 0 {
+			t.add(depth, t.updateTargetListLen(msg.GetTargetList()), func() {
+				msg.TargetList = []*pganalyze.Node{t.target()}
+			})
+		}
+		t.addWhereClause(depth, &msg.WhereClause)
+	case *pganalyze.List:
+		for _, n := range msg.GetItems() {
+			t.generate(n.ProtoReflect(), depth+1)
+		}
+		return
+	case *pganalyze.CaseExpr:
+		// The walker does not visit CaseWhen nodes, only their children.
+		t.generateNode(msg.GetArg(), depth+1)
+		for _, n := range msg.GetArgs() {
+			w := n.GetCaseWhen()
+			t.generateNode(w.GetExpr(), depth+1)
+			t.generateNode(w.GetResult(), depth+1)
+		}
+		t.generateNode(msg.GetDefresult(), depth+1)
+		return
+	}
+
+	for _, fd := range truncateWalkFields[m.Descriptor().FullName()] {
+		if !m.Has(fd) {
+			continue
+		}
+		if fd.IsList() {
+			// Repeated fields are Lists in Postgres, adding a level.
+			l := m.Get(fd).List()
+			for i := range l.Len() {
+				t.generate(l.Get(i).Message(), depth+2)
+			}
+			continue
+		}
+		t.generate(m.Get(fd).Message(), depth+1)
+	}
+}
+
+func (t *truncator) generateNode(n *pganalyze.Node, depth int) {
+	if n != nil {
+		t.generate(n.ProtoReflect(), depth)
+	}
+}
+
+func (t *truncator) updateTargetListLen(targetList []*pganalyze.Node) int {
+	stmt := &pganalyze.UpdateStmt{
+		Relation:   &pganalyze.RangeVar{Relname: "x", Inh: true, Relpersistence: "p"},
+		TargetList: targetList,
+	}
+	return t.len(stmt, len("UPDATE x SET "))
+}
+
+// column returns the column reference standing in for truncated expressions,
+// deparsed as the quoted placeholder and then replaced with "...".
+func (t *truncator) column() *pganalyze.Node {
+	return &pganalyze.Node{Node: &pganalyze.Node_ColumnRef{ColumnRef: &pganalyze.ColumnRef{
+		Fields: []*pganalyze.Node{{Node: &pganalyze.Node_String_{String_: &pganalyze.String{Sval: t.placeholder}}}},
+	}}}
+}
+
+func (t *truncator) target() *pganalyze.Node {
+	return &pganalyze.Node{Node: &pganalyze.Node_ResTarget{ResTarget: &pganalyze.ResTarget{Name: t.placeholder, Val: t.column()}}}
+}
+
+// placeholder returns the name of the column standing in for truncated parts,
+// "…" as in libpg_query unless it occurs in sql, so that identifiers and
+// strings of sql are never mistaken for truncated parts.
+func placeholder(sql string) string {
+	p := "…"
+	for i := 0; strings.Contains(sql, p); i++ {
+		p = "…" + strconv.Itoa(i)
+	}
+	return p
+}
+
+// truncateChars cuts s to maxLen characters including a trailing "...",
+// reproducing libpg_query which limits the bytes kept to the number of
+// characters in s.
+func truncateChars(s string, maxLen int) string {
+	n := utf8.RuneCountInString(s)
+	if n <= maxLen {
+		return s
+	}
+	limit, budget, end := maxLen-3, n, 0
+	for chars := 1; budget > 0 && end < len(s) && chars <= limit; chars++ {
+		_, size := utf8.DecodeRuneInString(s[end:])
+		end += size
+		budget -= size
+	}
+	return s[:end] + "..."
+}
+
+// nodeField returns the field of Node holding messages of type md.
+func nodeField(md protoreflect.MessageDescriptor) protoreflect.FieldDescriptor {
+	fields := (&pganalyze.Node{}).ProtoReflect().Descriptor().Fields()
+	for i := range fields.Len() {
+		if fd := fields.Get(i); fd.Message() != nil && fd.Message().FullName() == md.FullName() {
+			return fd
+		}
+	}
+	panic(fmt.Sprintf("pg_query: %s is not a node type", md.FullName()))
+}
+
+// truncateWalkFields are the fields raw_expression_tree_walker visits by node
+// type, in the order it visits them. Lists and CaseExpr are handled
+// separately, and other node types are not descended into.
+var truncateWalkFields = resolveFields(map[string][]string{
+	"RangeVar":                  {"alias"},
+	"GroupingFunc":              {"args"},
+	"SubLink":                   {"testexpr", "subselect"},
+	"RowExpr":                   {"args"},
+	"CoalesceExpr":              {"args"},
+	"MinMaxExpr":                {"args"},
+	"XmlExpr":                   {"named_args", "args"},
+	"JsonReturning":             {"format"},
+	"JsonValueExpr":             {"raw_expr", "formatted_expr", "format"},
+	"JsonParseExpr":             {"expr", "output"},
+	"JsonScalarExpr":            {"expr", "output"},
+	"JsonSerializeExpr":         {"expr", "output"},
+	"JsonConstructorExpr":       {"args", "func", "coercion", "returning"},
+	"JsonIsPredicate":           {"expr"},
+	"JsonArgument":              {"val"},
+	"JsonFuncExpr":              {"context_item", "pathspec", "passing", "output", "on_empty", "on_error"},
+	"JsonBehavior":              {"expr"},
+	"JsonTable":                 {"context_item", "pathspec", "passing", "columns", "on_error"},
+	"JsonTableColumn":           {"type_name", "on_empty", "on_error", "columns"},
+	"JsonTablePathSpec":         {"string"},
+	"NullTest":                  {"arg"},
+	"BooleanTest":               {"arg"},
+	"JoinExpr":                  {"larg", "rarg", "quals", "alias"},
+	"IntoClause":                {"rel", "view_query"},
+	"InsertStmt":                {"relation", "cols", "select_stmt", "on_conflict_clause", "returning_list", "with_clause"},
+	"DeleteStmt":                {"relation", "using_clause", "where_clause", "returning_list", "with_clause"},
+	"UpdateStmt":                {"relation", "target_list", "where_clause", "from_clause", "returning_list", "with_clause"},
+	"MergeStmt":                 {"relation", "source_relation", "join_condition", "merge_when_clauses", "returning_list", "with_clause"},
+	"MergeWhenClause":           {"condition", "target_list", "values"},
+	"PLAssignStmt":              {"indirection", "val"},
+	"A_Expr":                    {"lexpr", "rexpr"},
+	"BoolExpr":                  {"args"},
+	"FuncCall":                  {"args", "agg_order", "agg_filter", "over"},
+	"NamedArgExpr":              {"arg"},
+	"A_Indices":                 {"lidx", "uidx"},
+	"A_Indirection":             {"arg", "indirection"},
+	"A_ArrayExpr":               {"elements"},
+	"ResTarget":                 {"indirection", "val"},
+	"MultiAssignRef":            {"source"},
+	"TypeCast":                  {"arg", "type_name"},
+	"CollateClause":             {"arg"},
+	"SortBy":                    {"node"},
+	"WindowDef":                 {"partition_clause", "order_clause", "start_offset", "end_offset"},
+	"RangeSubselect":            {"subquery", "alias"},
+	"RangeFunction":             {"functions", "alias", "coldeflist"},
+	"RangeTableSample":          {"relation", "args", "repeatable"},
+	"RangeTableFunc":            {"docexpr", "rowexpr", "namespaces", "columns", "alias"},
+	"RangeTableFuncCol":         {"colexpr", "coldefexpr"},
+	"TypeName":                  {"typmods", "array_bounds"},
+	"ColumnDef":                 {"type_name", "raw_default", "coll_clause"},
+	"IndexElem":                 {"expr"},
+	"GroupingSet":               {"content"},
+	"LockingClause":             {"locked_rels"},
+	"XmlSerialize":              {"expr", "type_name"},
+	"WithClause":                {"ctes"},
+	"InferClause":               {"index_elems", "where_clause"},
+	"OnConflictClause":          {"infer", "target_list", "where_clause"},
+	"CommonTableExpr":           {"ctequery"},
+	"JsonOutput":                {"type_name", "returning"},
+	"JsonKeyValue":              {"key", "value"},
+	"JsonObjectConstructor":     {"output", "exprs"},
+	"JsonArrayConstructor":      {"output", "exprs"},
+	"JsonAggConstructor":        {"output", "agg_order", "agg_filter", "over"},
+	"JsonObjectAgg":             {"constructor", "arg"},
+	"JsonArrayAgg":              {"constructor", "arg"},
+	"JsonArrayQueryConstructor": {"output", "query"},
+	"SelectStmt": {
+		"distinct_clause", "into_clause", "target_list", "from_clause", "where_clause", "group_clause",
+		"having_clause", "window_clause", "values_lists", "sort_clause", "limit_offset", "limit_count",
+		"locking_clause", "with_clause", "larg", "rarg",
+	},
+})
+
+func resolveFields(names map[string][]string) map[protoreflect.FullName][]protoreflect.FieldDescriptor {
+	res := make(map[protoreflect.FullName][]protoreflect.FieldDescriptor, len(names))
+	for msg, fields := range names {
+		name := protoreflect.FullName("pg_query." + msg)
+		d, err := protoregistry.GlobalFiles.FindDescriptorByName(name)
+		if err != nil {
+			panic(fmt.Sprintf("pg_query: resolving %s: %v", name, err))
+		}
+		md, ok := d.(protoreflect.MessageDescriptor)
+		if !ok {
+			panic(fmt.Sprintf("pg_query: %s is not a message", name))
+		}
+		for _, f := range fields {
+			fd := md.Fields().ByName(protoreflect.Name(f))
+			if fd == nil {
+				panic(fmt.Sprintf("pg_query: %s has no field %s", name, f))
+			}
+			res[name] = append(res[name], fd)
+		}
+	}
+	return res
+}
@@ -0,0 +1,48 @@
+//go:build cgo
+
+package pg_query_test
+
+import (
+	"strings"
+	"testing"
+
+	pganalyze "github.com/pganalyze/pg_query_go/v6"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+)
+
+// TestTruncateMatchesLibpgquery compares Truncate with the truncation of
+// libpg_query's summary, which is only reachable with cgo.
+func TestTruncateMatchesLibpgquery(t *testing.T) {
+	var inputs []string
+	for _, tc := range truncateTests {
+		inputs = append(inputs, tc.input)
+	}
+	inputs = append(inputs, libpgqueryDeparseTests...)
+	inputs = append(inputs, libpgqueryFingerprintTests...)
+
+	for _, input := range inputs {
+		// libpg_query mistakes identifiers and strings containing its "…"
+		// placeholder for truncated parts.
+		if strings.Contains(input, "…") {
+			continue
+		}
+		full, err := pg_query.Truncate(input, -1)
+		if err != nil {
+			continue
+		}
+		for limit := 0; limit <= len(full); limit += max(len(full)/8, 1) {
+			want, err := pganalyze.Summary(input, limit)
+			if err != nil {
+				t.Fatalf("Summary(%q, %d): %v", input, limit, err)
+			}
+			got, err := pg_query.Truncate(input, limit)
+			if err != nil {
+				t.Fatalf("Truncate(%q, %d): %v", input, limit, err)
+			}
+			if got != want.GetTruncatedQuery() {
+				t.Errorf("Truncate(%q, %d)\nexpected %s\nactual   %s", input, limit, want.GetTruncatedQuery(), got)
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package pg_query_test
+
+import (
+	"errors"
+	"testing"
+
+	pg_query "github.com/wasilibs/go-pgquery"
+	"github.com/wasilibs/go-pgquery/parser"
+)
+
+var truncateTests = []struct {
+	input  string
+	maxLen int
+	want   string
+}{
+	{
+		input:  "SELECT id, name, email FROM users WHERE id = 1",
+		maxLen: 30,
+		want:   "SELECT ... FROM users WHERE...",
+	},
+	{
+		input:  "select id from users where id = 1",
+		maxLen: 100,
+		want:   "SELECT id FROM users WHERE id = 1",
+	},
+	{
+		input:  "SELECT a, b FROM t",
+		maxLen: -1,
+		want:   "SELECT a, b FROM t",
+	},
+	{
+		input:  "SELECT a, b, c, d FROM t WHERE x = 1 AND y = 2",
+		maxLen: 40,
+		want:   "SELECT a, b, c, d FROM t WHERE ...",
+	},
+	{
+		input:  "SELECT * FROM t WHERE a IN (SELECT b FROM u WHERE c = 1 AND d = 2)",
+		maxLen: 50,
+		want:   "SELECT * FROM t WHERE ...",
+	},
+	{
+		input:  "INSERT INTO t (a, b, c) VALUES (1, 2, 3), (4, 5, 6)",
+		maxLen: 30,
+		want:   "INSERT INTO t (...) VALUES ...",
+	},
+	{
+		input:  "UPDATE t SET a = 1, b = 2, c = 3 WHERE id = 42",
+		maxLen: 30,
+		want:   "UPDATE t SET ... = ... WHER...",
+	},
+	{
+		input:  "DELETE FROM t WHERE a = 1 AND b = 2 AND c = 3",
+		maxLen: 30,
+		want:   "DELETE FROM t WHERE ...",
+	},
+	{
+		input:  "WITH x AS (SELECT a, b FROM t WHERE c = 1) SELECT * FROM x",
+		maxLen: 40,
+		want:   "WITH x AS (...) SELECT * FROM x",
+	},
+	{
+		input:  "INSERT INTO t (a, b) VALUES (1, 2) ON CONFLICT (a) WHERE b > 0 DO UPDATE SET b = excluded.b WHERE t.b < 10",
+		maxLen: 60,
+		want:   "INSERT INTO t (...) VALUES (...) ON CONFLICT (a) WHERE .....",
+	},
+	{
+		input:  "SELECT CASE WHEN a = 1 THEN (SELECT x FROM y WHERE z = 2) ELSE 0 END FROM t",
+		maxLen: 50,
+		want:   "SELECT ... FROM t",
+	},
+	{
+		input:  "SELECT 1; SELECT a, b, c FROM t WHERE d = 1",
+		maxLen: 30,
+		want:   "SELECT 1; SELECT ... FROM t...",
+	},
+	{
+		input:  "CREATE INDEX i ON t (a) WHERE b IS NOT NULL AND c > 100",
+		maxLen: 40,
+		want:   "CREATE INDEX i ON t USING btree (a) W...",
+	},
+	{
+		input:  "SELECT very_long_column_name_number_one FROM a_table_with_a_long_name",
+		maxLen: 20,
+		want:   "SELECT ... FROM a...",
+	},
+	{
+		input:  "SELECT 'ünïcödé ünïcödé ünïcödé' FROM t",
+		maxLen: 20,
+		want:   "SELECT ... FROM t",
+	},
+	{
+		input:  `CREATE TABLE "ünïcödé_täblé" (a int, b text)`,
+		maxLen: 24,
+		want:   `CREATE TABLE "ünïcödé...`,
+	},
+	{
+		input:  `SELECT "…", '"…"' FROM t WHERE x = 1 AND y = 2`,
+		maxLen: 40,
+		want:   `SELECT "…", '"…"' FROM t WHERE ...`,
+	},
+}
+
+func TestTruncate(t *testing.T) {
+	for _, tc := range truncateTests {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := pg_query.Truncate(tc.input, tc.maxLen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("Truncate(%s, %d)\nexpected %s\nactual   %s", tc.input, tc.maxLen, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestTruncateError(t *testing.T) {
+	_, err := pg_query.Truncate("SELECT * FROM", 10)
+	var perr *parser.Error
+	if !errors.As(err, &perr) {
+		t.Errorf("expected parse error, actual %v", err)
+	}
+}